@@ -0,0 +1,105 @@
+// Package antimeridian implements functions for geometries whose coordinates
+// are longitudes and latitudes, in degrees, that may cross the antimeridian,
+// i.e. 180 degrees longitude.
+//
+// Consecutive coordinates are connected along the shortest path in
+// longitude, so a line from 170 to -170 degrees longitude crosses the
+// antimeridian rather than spanning 340 degrees.
+package antimeridian
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// NormalizeLongitude returns lon normalized to the range [-180, 180].
+func NormalizeLongitude(lon float64) float64 {
+	if -180 <= lon && lon <= 180 {
+		return lon
+	}
+	return math.Remainder(lon, 360)
+}
+
+// Normalize returns a copy of g with all longitudes normalized to the range
+// [-180, 180].  Geometries that cross the antimeridian are not split, see
+// Split.
+func Normalize(g geom.T) (geom.T, error) {
+	flatCoords := normalizeFlatCoords(g.FlatCoords(), g.Stride())
+	switch g := g.(type) {
+	case *geom.Point:
+		return geom.NewPointFlat(g.Layout(), flatCoords).SetSRID(g.SRID()), nil
+	case *geom.LineString:
+		return geom.NewLineStringFlat(g.Layout(), flatCoords).SetSRID(g.SRID()), nil
+	case *geom.LinearRing:
+		return geom.NewLinearRingFlat(g.Layout(), flatCoords).SetSRID(g.SRID()), nil
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(g.Layout(), flatCoords, cloneEnds(g.Ends())).SetSRID(g.SRID()), nil
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(g.Layout(), flatCoords).SetSRID(g.SRID()), nil
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(g.Layout(), flatCoords, cloneEnds(g.Ends())).SetSRID(g.SRID()), nil
+	case *geom.MultiPolygon:
+		endss := make([][]int, len(g.Endss()))
+		for i, ends := range g.Endss() {
+			endss[i] = cloneEnds(ends)
+		}
+		return geom.NewMultiPolygonFlat(g.Layout(), flatCoords, endss).SetSRID(g.SRID()), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// Bounds returns the bounds of g, taking the antimeridian into account.  The
+// returned bounds are the smallest range of longitudes that contains the
+// longitudes of all of g's coordinates.  If this range crosses the
+// antimeridian then the maximum X is greater than 180, so the bounds remain
+// valid.  The east longitude of an RFC 7946 bounding box is the
+// NormalizeLongitude of the maximum X.
+func Bounds(g geom.T) *geom.Bounds {
+	b := g.Bounds()
+	if b.IsEmpty() {
+		return b
+	}
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	lons := make([]float64, 0, len(flatCoords)/stride)
+	for i := 0; i < len(flatCoords); i += stride {
+		lons = append(lons, NormalizeLongitude(flatCoords[i]))
+	}
+	sort.Float64s(lons)
+	// the bounds are the complement of the largest gap between consecutive
+	// longitudes, which is initially the gap across the antimeridian
+	minX, maxX := lons[0], lons[len(lons)-1]
+	gap := lons[0] + 360 - lons[len(lons)-1]
+	for i := 1; i < len(lons); i++ {
+		if lons[i]-lons[i-1] > gap {
+			gap = lons[i] - lons[i-1]
+			minX, maxX = lons[i], lons[i-1]+360
+		}
+	}
+	min := make(geom.Coord, stride)
+	max := make(geom.Coord, stride)
+	for i := 0; i < stride; i++ {
+		min[i], max[i] = b.Min(i), b.Max(i)
+	}
+	min[0], max[0] = minX, maxX
+	return geom.NewBounds(g.Layout()).SetCoords(min, max)
+}
+
+// normalizeFlatCoords returns a copy of flatCoords with all longitudes
+// normalized.
+func normalizeFlatCoords(flatCoords []float64, stride int) []float64 {
+	result := make([]float64, len(flatCoords))
+	copy(result, flatCoords)
+	for i := 0; i < len(result); i += stride {
+		result[i] = NormalizeLongitude(result[i])
+	}
+	return result
+}
+
+func cloneEnds(ends []int) []int {
+	result := make([]int, len(ends))
+	copy(result, ends)
+	return result
+}
@@ -0,0 +1,226 @@
+package antimeridian
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestNormalizeLongitude(t *testing.T) {
+	for _, tc := range []struct {
+		lon  float64
+		want float64
+	}{
+		{lon: 0, want: 0},
+		{lon: 180, want: 180},
+		{lon: -180, want: -180},
+		{lon: 190, want: -170},
+		{lon: -190, want: 170},
+		{lon: 360, want: 0},
+		{lon: 720.5, want: 0.5},
+		{lon: -540, want: 180},
+	} {
+		if got := NormalizeLongitude(tc.lon); got != tc.want {
+			t.Errorf("NormalizeLongitude(%v) == %v, want %v", tc.lon, got, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{190, 10}).SetSRID(4326),
+			want: geom.NewPointFlat(geom.XY, []float64{-170, 10}).SetSRID(4326),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{170, 0, 1, 190, 1, 2}),
+			want: geom.NewLineStringFlat(geom.XYZ, []float64{170, 0, 1, -170, 1, 2}),
+		},
+		{
+			g:    geom.NewMultiPolygonFlat(geom.XY, []float64{-190, 0, -185, 0, -185, 5, -190, 0}, [][]int{{8}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{170, 0, 175, 0, 175, 5, 170, 0}, [][]int{{8}}),
+		},
+	} {
+		if got, err := Normalize(tc.g); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Normalize(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want *geom.Bounds
+	}{
+		{
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{-10, 0, 10, 5}),
+			want: geom.NewBounds(geom.XY).Set(-10, 0, 10, 5),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{170, 0, -170, 5}),
+			want: geom.NewBounds(geom.XY).Set(170, 0, 190, 5),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{175, 0, 1, -170, 5, 2, 179, -5, 3, 540, 0, 0}),
+			want: geom.NewBounds(geom.XYZ).Set(175, -5, 0, 190, 5, 3),
+		},
+		{
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{-120, 0, 0, 0, 120, 0}),
+			want: geom.NewBounds(geom.XY).Set(-120, 0, 120, 0),
+		},
+		{
+			g:    geom.NewLineString(geom.XY),
+			want: geom.NewBounds(geom.XY),
+		},
+	} {
+		if got := Bounds(tc.g); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Bounds(%v) == %v, want %v", i, tc.g, got, tc.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{-181, 0}),
+			want: geom.NewPointFlat(geom.XY, []float64{179, 0}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 10}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 10}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{170, 0, -170, 10}).SetSRID(4326),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{170, 0, 180, 5, -180, 5, -170, 10}, []int{4, 8}).SetSRID(4326),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{-170, 0, 170, 10}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{-170, 0, -180, 5, 180, 5, 170, 10}, []int{4, 8}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XYZM, []float64{170, 0, 0, 10, -170, 10, 100, 20}),
+			want: geom.NewMultiLineStringFlat(geom.XYZM, []float64{170, 0, 0, 10, 180, 5, 50, 15, -180, 5, 50, 15, -170, 10, 100, 20}, []int{8, 16}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{170, 0, 180, 5, -170, 10}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{170, 0, 180, 5, -180, 5, -170, 10}, []int{4, 8}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{180, 0, -170, 0}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{-180, 0, -170, 0}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{170, 0, 190, 0, 170, 10}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{170, 0, 180, 0, -180, 0, -170, 0, -180, 5, 180, 5, 170, 10}, []int{4, 10, 14}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 179, 0, 181, 0}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 179, 0, 180, 0, -180, 0, -179, 0}, []int{4, 8, 12}),
+		},
+		{
+			g: geom.NewPolygonFlat(geom.XY, []float64{170, -10, -170, -10, -170, 10, 170, 10, 170, -10}, []int{10}).SetSRID(4326),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				180, 10, 170, 10, 170, -10, 180, -10, 180, 10,
+				-180, -10, -170, -10, -170, 10, -180, 10, -180, -10,
+			}, [][]int{{10}, {20}}).SetSRID(4326),
+		},
+		{
+			g: geom.NewPolygonFlat(geom.XY, []float64{170, -10, 170, 10, -170, 10, -170, -10, 170, -10}, []int{10}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				180, 10, 180, -10, 170, -10, 170, 10, 180, 10,
+				-180, -10, -180, 10, -170, 10, -170, -10, -180, -10,
+			}, [][]int{{10}, {20}}),
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{185, 0, 190, 0, 190, 5, 185, 0}, []int{8}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{-175, 0, -170, 0, -170, 5, -175, 0}, []int{8}),
+		},
+		{
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				170, -10, -170, -10, -170, 10, 170, 10, 170, -10,
+				-178, -2, -178, 2, -172, 2, -172, -2, -178, -2,
+			}, []int{10, 20}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				180, 10, 170, 10, 170, -10, 180, -10, 180, 10,
+				-180, -10, -170, -10, -170, 10, -180, 10, -180, -10,
+				-178, -2, -178, 2, -172, 2, -172, -2, -178, -2,
+			}, [][]int{{10}, {20, 30}}),
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				179, 0, 181, 0, 181, 1, 179, 0,
+			}, [][]int{{8}, {16}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				180, 0.5, 179, 0, 180, 0, 180, 0.5,
+				-180, 0, -179, 0, -179, 1, -180, 0.5, -180, 0,
+			}, [][]int{{8}, {16}, {26}}),
+		},
+	} {
+		if got, err := Split(tc.g); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Split(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+}
+
+func TestSplitConcavePolygon(t *testing.T) {
+	// a C-shaped polygon that is open to the west and crosses the
+	// antimeridian four times
+	g := geom.NewPolygonFlat(geom.XY, []float64{
+		170, 0, -170, 0, -170, 10, 170, 10, 170, 7, -175, 7, -175, 3, 170, 3, 170, 0,
+	}, []int{18})
+	got, err := Split(g)
+	if err != nil {
+		t.Fatalf("Split(%v) == ..., %v, want ..., <nil>", g, err)
+	}
+	mp, ok := got.(*geom.MultiPolygon)
+	if !ok || mp.NumPolygons() != 3 {
+		t.Fatalf("Split(%v) == %v, want a MultiPolygon with 3 polygons", g, got)
+	}
+	for i, want := range []float64{30, 30, 80} {
+		p := mp.Polygon(i)
+		if p.NumLinearRings() != 1 || p.Area() != want {
+			t.Errorf("Split(%v).Polygon(%d) == %v, want a polygon with area %v", g, i, p.FlatCoords(), want)
+		}
+		if b := p.Bounds(); b.Min(0) < -180 || b.Max(0) > 180 {
+			t.Errorf("Split(%v).Polygon(%d) == %v, want longitudes in range", g, i, p.FlatCoords())
+		}
+	}
+}
+
+func TestSplitPolygonWithCrossingHole(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XY, []float64{
+		160, -20, -160, -20, -160, 20, 160, 20, 160, -20,
+		175, -5, 175, 5, -175, 5, -175, -5, 175, -5,
+	}, []int{10, 20})
+	got, err := Split(g)
+	if err != nil {
+		t.Fatalf("Split(%v) == ..., %v, want ..., <nil>", g, err)
+	}
+	mp, ok := got.(*geom.MultiPolygon)
+	if !ok || mp.NumPolygons() != 2 {
+		t.Fatalf("Split(%v) == %v, want a MultiPolygon with 2 polygons", g, got)
+	}
+	for i := 0; i < mp.NumPolygons(); i++ {
+		p := mp.Polygon(i)
+		if p.NumLinearRings() != 1 || math.Abs(p.Area()-750) > 1e-9 {
+			t.Errorf("Split(%v).Polygon(%d) == %v, want a polygon with area 750", g, i, p.FlatCoords())
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	g := geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0})
+	if _, err := Split(g); !reflect.DeepEqual(err, geom.ErrUnsupportedType{Value: g}) {
+		t.Errorf("Split(%v) == ..., %v, want ..., %v", g, err, geom.ErrUnsupportedType{Value: g})
+	}
+}
@@ -0,0 +1,427 @@
+package antimeridian
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+	"github.com/twpayne/go-geom/internal/mathutil"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/orientation"
+)
+
+// A chain is a part of a ring on one side of a cut line, starting and ending
+// on the cut line.
+type chain struct {
+	right      bool
+	flatCoords []float64
+}
+
+// A sideRing is a ring that lies entirely on one side of a cut line.
+type sideRing struct {
+	right      bool
+	exterior   bool
+	flatCoords []float64
+}
+
+// Split splits g at the antimeridian, as recommended by RFC 7946 section
+// 3.1.9, and normalizes its longitudes.  Parts of g west of the antimeridian
+// end at 180 degrees longitude and parts east of it start at -180 degrees
+// longitude, with the other ordinates interpolated linearly.
+//
+// LineStrings and Polygons that cross the antimeridian are returned as
+// MultiLineStrings and MultiPolygons respectively.  Other geometries are
+// returned with the same type.  Polygon rings keep the orientation of their
+// exterior ring.  LinearRings are not supported.
+func Split(g geom.T) (geom.T, error) {
+	layout, stride := g.Layout(), g.Stride()
+	switch g := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return Normalize(g)
+	case *geom.LineString:
+		lines := splitLine(g.FlatCoords(), stride)
+		if len(lines) == 1 {
+			return geom.NewLineStringFlat(layout, lines[0]).SetSRID(g.SRID()), nil
+		}
+		return newMultiLineString(layout, lines).SetSRID(g.SRID()), nil
+	case *geom.MultiLineString:
+		var lines [][]float64
+		offset := 0
+		for _, end := range g.Ends() {
+			lines = append(lines, splitLine(g.FlatCoords()[offset:end], stride)...)
+			offset = end
+		}
+		return newMultiLineString(layout, lines).SetSRID(g.SRID()), nil
+	case *geom.Polygon:
+		polygons := splitPolygon(layout, flat.Split(g.FlatCoords(), 0, g.Ends()))
+		if len(polygons) == 1 {
+			return newPolygon(layout, polygons[0]).SetSRID(g.SRID()), nil
+		}
+		return newMultiPolygon(layout, polygons).SetSRID(g.SRID()), nil
+	case *geom.MultiPolygon:
+		var polygons [][][]float64
+		offset := 0
+		for _, ends := range g.Endss() {
+			polygons = append(polygons, splitPolygon(layout, flat.Split(g.FlatCoords(), offset, ends))...)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return newMultiPolygon(layout, polygons).SetSRID(g.SRID()), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// splitLine splits the line string in flatCoords at the antimeridian.
+func splitLine(flatCoords []float64, stride int) [][]float64 {
+	if len(flatCoords) == 0 {
+		return [][]float64{{}}
+	}
+	var lines [][]float64
+	var line []float64
+	prev := make([]float64, stride)
+	copy(prev, flatCoords[:stride])
+	prev[0] = NormalizeLongitude(prev[0])
+	line = append(line, prev...)
+	for i := stride; i < len(flatCoords); i += stride {
+		next := make([]float64, stride)
+		copy(next, flatCoords[i:i+stride])
+		next[0] = NormalizeLongitude(next[0])
+		if dx := next[0] - prev[0]; dx < -180 || dx > 180 {
+			// the line crosses the antimeridian at +/-180, where it ends and
+			// the next line starts at -/+180
+			unwrapped := make([]float64, stride)
+			copy(unwrapped, next)
+			x := 180.0
+			if dx < -180 {
+				unwrapped[0] += 360
+			} else {
+				unwrapped[0] -= 360
+				x = -180
+			}
+			p := crossing(prev, unwrapped, x)
+			line = flat.AppendUnrepeated(line, p, stride)
+			if len(line) >= 2*stride {
+				lines = append(lines, line)
+			}
+			p[0] = -x
+			line = append([]float64(nil), p...)
+		}
+		line = flat.AppendUnrepeated(line, next, stride)
+		prev = next
+	}
+	if len(line) >= 2*stride || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitPolygon splits the polygon with the given rings at the antimeridian,
+// and returns the rings of each resulting polygon.
+func splitPolygon(layout geom.Layout, rings [][]float64) [][][]float64 {
+	if len(rings) == 0 {
+		return nil
+	}
+	stride := layout.Stride()
+	if len(rings[0]) < 4*stride {
+		for i, ring := range rings {
+			rings[i] = normalizeFlatCoords(ring, stride)
+		}
+		return [][][]float64{rings}
+	}
+
+	// unwrap each ring so that its longitudes are continuous, and shift the
+	// holes to be near the exterior ring
+	unwrapped := make([]float64, 0, len(rings)*len(rings[0]))
+	ends := make([]int, len(rings))
+	for i, ring := range rings {
+		r := unwrap(ring, stride)
+		if i > 0 && len(r) > 0 {
+			shift := 360 * mathutil.Round((unwrapped[0]-r[0])/360)
+			for j := 0; j < len(r); j += stride {
+				r[j] += shift
+			}
+		}
+		unwrapped = append(unwrapped, r...)
+		ends[i] = len(unwrapped)
+	}
+	// orient the rings so that the interior of the polygon is always to the
+	// left of its rings
+	exteriorCCW := xy.IsRingCounterClockwise(layout, unwrapped[:ends[0]])
+	oriented, _ := xy.Orient(geom.NewPolygonFlat(layout, unwrapped, ends), orientation.CounterClockwise)
+	pieces := [][][]float64{flat.Split(oriented.FlatCoords(), 0, oriented.Ends())}
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for i := 0; i < ends[0]; i += stride {
+		minX, maxX = math.Min(minX, unwrapped[i]), math.Max(maxX, unwrapped[i])
+	}
+	var polygons [][][]float64
+	for c := 180 + 360*(math.Floor((minX-180)/360)+1); c < maxX; c += 360 {
+		var next [][][]float64
+		for _, piece := range pieces {
+			left, right := splitPolygonAt(layout, piece, c)
+			polygons = append(polygons, left...)
+			next = append(next, right...)
+		}
+		pieces = next
+	}
+	polygons = append(polygons, pieces...)
+
+	for _, polygon := range polygons {
+		minX, maxX := math.Inf(1), math.Inf(-1)
+		for i := 0; i < len(polygon[0]); i += stride {
+			minX, maxX = math.Min(minX, polygon[0][i]), math.Max(maxX, polygon[0][i])
+		}
+		shift := -360 * mathutil.Round((minX+maxX)/2/360)
+		for _, ring := range polygon {
+			for i := 0; i < len(ring); i += stride {
+				ring[i] += shift
+			}
+			if !exteriorCCW {
+				flat.Reverse(ring, stride)
+			}
+		}
+	}
+	return polygons
+}
+
+// splitPolygonAt splits the polygon with the given rings, which must have
+// counter-clockwise exterior rings and clockwise interior rings, at x = c,
+// and returns the rings of the polygons to the left and right of c.
+func splitPolygonAt(layout geom.Layout, rings [][]float64, c float64) (left, right [][][]float64) {
+	stride := layout.Stride()
+	var chains []chain
+	var sideRings []sideRing
+	for i, ring := range rings {
+		ringChains, right, ok := splitRing(ring, stride, c)
+		switch {
+		case ok:
+			chains = append(chains, ringChains...)
+		case len(ring) >= 4*stride:
+			sideRings = append(sideRings, sideRing{right: right, exterior: i == 0, flatCoords: ring})
+		}
+	}
+
+	for _, right := range []bool{false, true} {
+		var sideChains []chain
+		for _, ch := range chains {
+			if ch.right == right {
+				sideChains = append(sideChains, ch)
+			}
+		}
+		var polygons [][][]float64
+		for _, ring := range joinChains(sideChains, stride) {
+			polygons = append(polygons, [][]float64{ring})
+		}
+		for _, sr := range sideRings {
+			if sr.right == right && sr.exterior {
+				polygons = append(polygons, [][]float64{sr.flatCoords})
+			}
+		}
+		for _, sr := range sideRings {
+			if sr.right != right || sr.exterior {
+				continue
+			}
+			p := geom.Coord(sr.flatCoords[:stride])
+			for i, polygon := range polygons {
+				if xy.IsPointInRing(layout, p, polygon[0]) {
+					polygons[i] = append(polygon, sr.flatCoords)
+					break
+				}
+			}
+		}
+		if right {
+			return left, polygons
+		}
+		left = polygons
+	}
+	return
+}
+
+// splitRing splits ring at x = c into chains.  If ring does not cross c then
+// it returns false and the side of c that ring is on.  Points on c are
+// considered to be on the same side as the preceding point, so rings that
+// touch c are not split.
+func splitRing(ring []float64, stride int, c float64) ([]chain, bool, bool) {
+	pts := ring[:len(ring)-stride]
+	n := len(pts) / stride
+	sides := make([]bool, n)
+	last := -1
+	for i := n - 1; i >= 0; i-- {
+		if pts[i*stride] != c {
+			last = i
+			break
+		}
+	}
+	if last == -1 {
+		return nil, false, false
+	}
+	side := pts[last*stride] > c
+	for i := 0; i < n; i++ {
+		if x := pts[i*stride]; x != c {
+			side = x > c
+		}
+		sides[i] = side
+	}
+
+	start := -1
+	for i := 0; i < n; i++ {
+		if sides[i] != sides[(i+n-1)%n] {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return nil, sides[0], false
+	}
+
+	point := func(i int) []float64 {
+		i %= n
+		return pts[i*stride : (i+1)*stride]
+	}
+	var chains []chain
+	current := chain{
+		right:      sides[start],
+		flatCoords: crossing(point(start+n-1), point(start), c),
+	}
+	for j := 0; j < n; j++ {
+		i, next := (start+j)%n, (start+j+1)%n
+		current.flatCoords = flat.AppendUnrepeated(current.flatCoords, point(i), stride)
+		if sides[next] != current.right {
+			p := crossing(point(i), point(next), c)
+			current.flatCoords = flat.AppendUnrepeated(current.flatCoords, p, stride)
+			chains = append(chains, current)
+			current = chain{
+				right:      sides[next],
+				flatCoords: p,
+			}
+		}
+	}
+	return chains, false, true
+}
+
+// An end is the start or end of a chain on a cut line.
+type end struct {
+	y     float64
+	chain int
+	start bool
+}
+
+// endsByY sorts ends by their Y ordinates.
+type endsByY []end
+
+func (s endsByY) Len() int           { return len(s) }
+func (s endsByY) Less(i, j int) bool { return s[i].y < s[j].y }
+func (s endsByY) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// joinChains joins chains on the same side of a cut line into rings.  The end
+// of each chain is connected along the cut line to the start of the chain
+// that bounds the same interval of the polygon's interior on the cut line.
+func joinChains(chains []chain, stride int) [][]float64 {
+	ends := make([]end, 0, 2*len(chains))
+	for i, ch := range chains {
+		ends = append(ends,
+			end{y: ch.flatCoords[1], chain: i, start: true},
+			end{y: ch.flatCoords[len(ch.flatCoords)-stride+1], chain: i, start: false},
+		)
+	}
+	sort.Stable(endsByY(ends))
+	endIndex := make([]int, len(chains))
+	for i, e := range ends {
+		if !e.start {
+			endIndex[e.chain] = i
+		}
+	}
+
+	var rings [][]float64
+	used := make([]bool, len(chains))
+	for i := range chains {
+		if used[i] {
+			continue
+		}
+		var ring []float64
+		for j := i; !used[j]; {
+			used[j] = true
+			for k := 0; k < len(chains[j].flatCoords); k += stride {
+				ring = flat.AppendUnrepeated(ring, chains[j].flatCoords[k:k+stride], stride)
+			}
+			partner := ends[endIndex[j]^1]
+			if !partner.start {
+				break
+			}
+			j = partner.chain
+		}
+		ring = flat.AppendUnrepeated(ring, ring[:stride], stride)
+		if len(ring) >= 4*stride {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// crossing returns the point on the line from a to b with x = c, with the
+// other ordinates interpolated linearly.
+func crossing(a, b []float64, c float64) []float64 {
+	p := make([]float64, len(a))
+	t := 0.0
+	if b[0] != a[0] {
+		t = (c - a[0]) / (b[0] - a[0])
+	}
+	for i := range p {
+		p[i] = a[i] + t*(b[i]-a[i])
+	}
+	p[0] = c
+	return p
+}
+
+// unwrap returns a copy of ring with its first longitude normalized and its
+// other longitudes adjusted so that consecutive longitudes differ by no more
+// than 180 degrees.
+func unwrap(ring []float64, stride int) []float64 {
+	result := make([]float64, len(ring))
+	copy(result, ring)
+	if len(result) == 0 {
+		return result
+	}
+	result[0] = NormalizeLongitude(result[0])
+	for i := stride; i < len(result); i += stride {
+		result[i] = result[i-stride] + math.Remainder(result[i]-result[i-stride], 360)
+	}
+	return result
+}
+
+func newMultiLineString(layout geom.Layout, lines [][]float64) *geom.MultiLineString {
+	var flatCoords []float64
+	ends := make([]int, len(lines))
+	for i, line := range lines {
+		flatCoords = append(flatCoords, line...)
+		ends[i] = len(flatCoords)
+	}
+	return geom.NewMultiLineStringFlat(layout, flatCoords, ends)
+}
+
+func newPolygon(layout geom.Layout, rings [][]float64) *geom.Polygon {
+	var flatCoords []float64
+	ends := make([]int, len(rings))
+	for i, ring := range rings {
+		flatCoords = append(flatCoords, ring...)
+		ends[i] = len(flatCoords)
+	}
+	return geom.NewPolygonFlat(layout, flatCoords, ends)
+}
+
+func newMultiPolygon(layout geom.Layout, polygons [][][]float64) *geom.MultiPolygon {
+	var flatCoords []float64
+	endss := make([][]int, len(polygons))
+	for i, rings := range polygons {
+		ends := make([]int, len(rings))
+		for j, ring := range rings {
+			flatCoords = append(flatCoords, ring...)
+			ends[j] = len(flatCoords)
+		}
+		endss[i] = ends
+	}
+	return geom.NewMultiPolygonFlat(layout, flatCoords, endss)
+}
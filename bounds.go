@@ -1,8 +1,5 @@
 package geom
 
-// FIXME(twpayne) creating a Bounds with layout XYM and then extending it with
-// a XYZ geometry will not work.
-
 import (
 	"math"
 )
@@ -28,13 +25,126 @@ func NewBounds(layout Layout) *Bounds {
 	}
 }
 
-// Extend extends b to include geometry g.
+// Area returns the area of b in the X and Y dimensions, or zero if b is
+// empty.
+func (b *Bounds) Area() float64 {
+	if b.layout.Stride() < 2 || b.IsEmpty() {
+		return 0
+	}
+	return (b.max[0] - b.min[0]) * (b.max[1] - b.min[1])
+}
+
+// Buffer expands b by d in the X and Y dimensions and returns b.  A negative
+// d shrinks b, and b becomes empty if it is shrunk by more than half of its
+// width or height.
+func (b *Bounds) Buffer(d float64) *Bounds {
+	for i := 0; i < 2 && i < b.layout.Stride(); i++ {
+		b.min[i] -= d
+		b.max[i] += d
+	}
+	return b
+}
+
+// Center returns the center of b.  If b is empty then the ordinates of the
+// center are NaN.
+func (b *Bounds) Center() Coord {
+	center := make(Coord, b.layout.Stride())
+	for i := range center {
+		center[i] = (b.min[i] + b.max[i]) / 2
+	}
+	return center
+}
+
+// Clone returns a deep copy of b.
+func (b *Bounds) Clone() *Bounds {
+	return &Bounds{
+		layout: b.layout,
+		min:    append(Coord(nil), b.min...),
+		max:    append(Coord(nil), b.max...),
+	}
+}
+
+// Contains returns true if b contains b2 in the dimensions that they have in
+// common.  An empty Bounds neither contains nor is contained by any Bounds.
+func (b *Bounds) Contains(b2 *Bounds) bool {
+	if b.IsEmpty() || b2.IsEmpty() {
+		return false
+	}
+	for i, stride := 0, b.layout.Stride(); i < stride; i++ {
+		j := ordinateIndex(b.layout, b2.layout, i)
+		if j == -1 {
+			continue
+		}
+		if b2.min[j] < b.min[i] || b.max[i] < b2.max[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// Extend extends b to include geometry g.  If g has dimensions that b does not
+// then b's layout is extended to include them, e.g. extending an XYM Bounds
+// with an XYZ geometry results in an XYZM Bounds.
 func (b *Bounds) Extend(g T) *Bounds {
-	b.extendStride(g.Layout().Stride())
-	b.extendFlatCoords(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride())
+	layout := g.Layout()
+	b.extendLayout(layout)
+	if layout == b.layout {
+		return b.extendFlatCoords(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride())
+	}
+	stride := g.Stride()
+	indexes := make([]int, stride)
+	for i := range indexes {
+		indexes[i] = ordinateIndex(layout, b.layout, i)
+	}
+	flatCoords := g.FlatCoords()
+	for i := 0; i < len(flatCoords); i += stride {
+		for j, k := range indexes {
+			if k == -1 {
+				continue
+			}
+			b.min[k] = math.Min(b.min[k], flatCoords[i+j])
+			b.max[k] = math.Max(b.max[k], flatCoords[i+j])
+		}
+	}
 	return b
 }
 
+// Intersection returns a new Bounds that is the intersection of b and b2.  The
+// layout of the result contains only the dimensions that b and b2 have in
+// common.  If b and b2 do not intersect then the result is empty.
+func (b *Bounds) Intersection(b2 *Bounds) *Bounds {
+	result := NewBounds(intersectLayouts(b.layout, b2.layout))
+	if !b.Intersects(b2) {
+		return result
+	}
+	for i, stride := 0, result.layout.Stride(); i < stride; i++ {
+		j1 := ordinateIndex(result.layout, b.layout, i)
+		j2 := ordinateIndex(result.layout, b2.layout, i)
+		result.min[i] = math.Max(b.min[j1], b2.min[j2])
+		result.max[i] = math.Min(b.max[j1], b2.max[j2])
+	}
+	return result
+}
+
+// Intersects returns true if b and b2 intersect, including touching at their
+// boundaries, in the dimensions that they have in common.  An empty Bounds
+// does not intersect any Bounds.
+func (b *Bounds) Intersects(b2 *Bounds) bool {
+	if b.IsEmpty() || b2.IsEmpty() {
+		return false
+	}
+	for i, stride := 0, b.layout.Stride(); i < stride; i++ {
+		j := ordinateIndex(b.layout, b2.layout, i)
+		if j == -1 {
+			continue
+		}
+		if b.min[i] > b2.max[j] || b.max[i] < b2.min[j] {
+			return false
+		}
+	}
+	return true
+}
+
 // IsEmpty returns true if b is empty.
 func (b *Bounds) IsEmpty() bool {
 	for i, stride := 0, b.layout.Stride(); i < stride; i++ {
@@ -70,6 +180,22 @@ func (b *Bounds) Overlaps(layout Layout, b2 *Bounds) bool {
 	return true
 }
 
+// Polygon returns b as a Polygon in the X and Y dimensions with a
+// counter-clockwise exterior ring.  If b is empty then the Polygon is empty.
+func (b *Bounds) Polygon() *Polygon {
+	if b.layout.Stride() < 2 || b.IsEmpty() {
+		return NewPolygon(XY)
+	}
+	flatCoords := []float64{
+		b.min[0], b.min[1],
+		b.max[0], b.min[1],
+		b.max[0], b.max[1],
+		b.min[0], b.max[1],
+		b.min[0], b.min[1],
+	}
+	return NewPolygonFlat(XY, flatCoords, []int{len(flatCoords)})
+}
+
 // Set sets the minimum and maximum values. args must be an even number of
 // values: the first half are the minimum values for each dimension and the
 // second half are the maximum values for each dimension.
@@ -108,6 +234,26 @@ func (b *Bounds) OverlapsPoint(layout Layout, point Coord) bool {
 	return true
 }
 
+// Union returns a new Bounds that is the union of b and b2.  The layout of the
+// result contains all the dimensions of b and b2.
+func (b *Bounds) Union(b2 *Bounds) *Bounds {
+	result := NewBounds(unionLayouts(b.layout, b2.layout))
+	result.extendBounds(b)
+	result.extendBounds(b2)
+	return result
+}
+
+func (b *Bounds) extendBounds(b2 *Bounds) {
+	for i, stride := 0, b2.layout.Stride(); i < stride; i++ {
+		j := ordinateIndex(b2.layout, b.layout, i)
+		if j == -1 {
+			continue
+		}
+		b.min[j] = math.Min(b.min[j], b2.min[i])
+		b.max[j] = math.Max(b.max[j], b2.max[i])
+	}
+}
+
 func (b *Bounds) extendFlatCoords(flatCoords []float64, offset, end, stride int) *Bounds {
 	b.extendStride(stride)
 	for i := offset; i < end; i += stride {
@@ -126,3 +272,82 @@ func (b *Bounds) extendStride(stride int) {
 		b.layout++
 	}
 }
+
+// extendLayout extends b's layout to include the dimensions of layout,
+// preserving the values of b's existing dimensions.
+func (b *Bounds) extendLayout(layout Layout) {
+	newLayout := unionLayouts(b.layout, layout)
+	if newLayout == b.layout {
+		return
+	}
+	nb := NewBounds(newLayout)
+	nb.extendBounds(b)
+	*b = *nb
+}
+
+// ordinateIndex returns the index in layout to of the dimension at index i in
+// layout from, or -1 if to does not have that dimension.
+func ordinateIndex(from, to Layout, i int) int {
+	switch {
+	case i < 2:
+		return i
+	case i == from.ZIndex():
+		return to.ZIndex()
+	case i == from.MIndex():
+		return to.MIndex()
+	case i < to.Stride():
+		return i
+	default:
+		return -1
+	}
+}
+
+// unionLayouts returns the layout that has all the dimensions of l1 and l2.
+func unionLayouts(l1, l2 Layout) Layout {
+	switch {
+	case l1 == l2:
+		return l1
+	case l1 > XYZM || l2 > XYZM:
+		if l1.Stride() > l2.Stride() {
+			return l1
+		}
+		return l2
+	case l1 == NoLayout:
+		return l2
+	case l2 == NoLayout:
+		return l1
+	}
+	return layoutWith(l1.ZIndex() != -1 || l2.ZIndex() != -1, l1.MIndex() != -1 || l2.MIndex() != -1)
+}
+
+// intersectLayouts returns the layout that has the dimensions that l1 and l2
+// have in common.
+func intersectLayouts(l1, l2 Layout) Layout {
+	switch {
+	case l1 == l2:
+		return l1
+	case l1 > XYZM && l2 > XYZM:
+		if l1.Stride() < l2.Stride() {
+			return l1
+		}
+		return l2
+	case l1 == NoLayout || l2 == NoLayout:
+		return NoLayout
+	}
+	return layoutWith(l1.ZIndex() != -1 && l2.ZIndex() != -1, l1.MIndex() != -1 && l2.MIndex() != -1)
+}
+
+// layoutWith returns the layout with X and Y dimensions and optional Z and M
+// dimensions.
+func layoutWith(z, m bool) Layout {
+	switch {
+	case z && m:
+		return XYZM
+	case z:
+		return XYZ
+	case m:
+		return XYM
+	default:
+		return XY
+	}
+}
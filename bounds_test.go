@@ -219,3 +219,161 @@ func TestBoundsSetCoords(t *testing.T) {
 		t.Errorf("Expected %v but got %v", expected, *bounds)
 	}
 }
+
+func TestBoundsExtendLayout(t *testing.T) {
+	for i, tc := range []struct {
+		b    *Bounds
+		g    T
+		want *Bounds
+	}{
+		{
+			b:    NewBounds(XYM).Set(0, 0, 5, 1, 1, 6),
+			g:    NewPointFlat(XYZ, []float64{2, 2, 10}),
+			want: NewBounds(XYZM).Set(0, 0, 10, 5, 2, 2, 10, 6),
+		},
+		{
+			b:    NewBounds(XYZ).Set(0, 0, 5, 1, 1, 6),
+			g:    NewPointFlat(XYM, []float64{2, 2, 10}),
+			want: NewBounds(XYZM).Set(0, 0, 5, 10, 2, 2, 6, 10),
+		},
+		{
+			b:    NewBounds(XY).Set(0, 0, 1, 1),
+			g:    NewLineStringFlat(XYM, []float64{-1, 0, 3, 2, 2, 4}),
+			want: NewBounds(XYM).Set(-1, 0, 3, 2, 2, 4),
+		},
+		{
+			b:    NewBounds(XYZM).Set(0, 0, 0, 0, 1, 1, 1, 1),
+			g:    NewPointFlat(XYM, []float64{2, 2, 2}),
+			want: NewBounds(XYZM).Set(0, 0, 0, 0, 2, 2, 1, 2),
+		},
+		{
+			b:    NewBounds(NoLayout),
+			g:    NewPointFlat(XYM, []float64{1, 2, 3}),
+			want: NewBounds(XYM).Set(1, 2, 3, 1, 2, 3),
+		},
+	} {
+		if got := tc.b.Extend(tc.g); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Extend(%v) == %v, want %v", i, tc.g, got, tc.want)
+		}
+	}
+}
+
+func TestBoundsIntersectsContains(t *testing.T) {
+	for i, tc := range []struct {
+		b1, b2         *Bounds
+		wantIntersects bool
+		wantContains   bool
+	}{
+		{
+			b1:             NewBounds(XY).Set(0, 0, 10, 10),
+			b2:             NewBounds(XY).Set(2, 2, 8, 8),
+			wantIntersects: true,
+			wantContains:   true,
+		},
+		{
+			b1:             NewBounds(XY).Set(0, 0, 10, 10),
+			b2:             NewBounds(XY).Set(5, 5, 15, 15),
+			wantIntersects: true,
+			wantContains:   false,
+		},
+		{
+			b1:             NewBounds(XY).Set(0, 0, 10, 10),
+			b2:             NewBounds(XY).Set(10, 10, 15, 15),
+			wantIntersects: true,
+			wantContains:   false,
+		},
+		{
+			b1:             NewBounds(XY).Set(0, 0, 10, 10),
+			b2:             NewBounds(XY).Set(11, 0, 15, 10),
+			wantIntersects: false,
+			wantContains:   false,
+		},
+		{
+			b1:             NewBounds(XYZ).Set(0, 0, 0, 10, 10, 10),
+			b2:             NewBounds(XYM).Set(2, 2, 100, 8, 8, 200),
+			wantIntersects: true,
+			wantContains:   true,
+		},
+		{
+			b1:             NewBounds(XYZ).Set(0, 0, 0, 10, 10, 10),
+			b2:             NewBounds(XYZM).Set(2, 2, 20, 0, 8, 8, 30, 0),
+			wantIntersects: false,
+			wantContains:   false,
+		},
+		{
+			b1:             NewBounds(XY).Set(0, 0, 10, 10),
+			b2:             NewBounds(XY),
+			wantIntersects: false,
+			wantContains:   false,
+		},
+	} {
+		if got := tc.b1.Intersects(tc.b2); got != tc.wantIntersects {
+			t.Errorf("%d: %v.Intersects(%v) == %v, want %v", i, tc.b1, tc.b2, got, tc.wantIntersects)
+		}
+		if got := tc.b1.Contains(tc.b2); got != tc.wantContains {
+			t.Errorf("%d: %v.Contains(%v) == %v, want %v", i, tc.b1, tc.b2, got, tc.wantContains)
+		}
+	}
+}
+
+func TestBoundsIntersectionUnion(t *testing.T) {
+	for i, tc := range []struct {
+		b1, b2           *Bounds
+		wantIntersection *Bounds
+		wantUnion        *Bounds
+	}{
+		{
+			b1:               NewBounds(XY).Set(0, 0, 10, 10),
+			b2:               NewBounds(XY).Set(5, -5, 15, 5),
+			wantIntersection: NewBounds(XY).Set(5, 0, 10, 5),
+			wantUnion:        NewBounds(XY).Set(0, -5, 15, 10),
+		},
+		{
+			b1:               NewBounds(XY).Set(0, 0, 1, 1),
+			b2:               NewBounds(XY).Set(2, 2, 3, 3),
+			wantIntersection: NewBounds(XY),
+			wantUnion:        NewBounds(XY).Set(0, 0, 3, 3),
+		},
+		{
+			b1:               NewBounds(XYZ).Set(0, 0, 0, 10, 10, 10),
+			b2:               NewBounds(XYM).Set(5, 5, 1, 15, 15, 2),
+			wantIntersection: NewBounds(XY).Set(5, 5, 10, 10),
+			wantUnion:        NewBounds(XYZM).Set(0, 0, 0, 1, 15, 15, 10, 2),
+		},
+		{
+			b1:               NewBounds(XY).Set(0, 0, 1, 1),
+			b2:               NewBounds(XY),
+			wantIntersection: NewBounds(XY),
+			wantUnion:        NewBounds(XY).Set(0, 0, 1, 1),
+		},
+	} {
+		if got := tc.b1.Intersection(tc.b2); !reflect.DeepEqual(got, tc.wantIntersection) {
+			t.Errorf("%d: %v.Intersection(%v) == %v, want %v", i, tc.b1, tc.b2, got, tc.wantIntersection)
+		}
+		if got := tc.b1.Union(tc.b2); !reflect.DeepEqual(got, tc.wantUnion) {
+			t.Errorf("%d: %v.Union(%v) == %v, want %v", i, tc.b1, tc.b2, got, tc.wantUnion)
+		}
+	}
+}
+
+func TestBoundsBufferAreaCenterPolygon(t *testing.T) {
+	b := NewBounds(XYZ).Set(0, 0, 5, 2, 4, 7)
+	if got, want := b.Area(), 8.0; got != want {
+		t.Errorf("%v.Area() == %v, want %v", b, got, want)
+	}
+	if got, want := b.Center(), (Coord{1, 2, 6}); !reflect.DeepEqual(got, want) {
+		t.Errorf("%v.Center() == %v, want %v", b, got, want)
+	}
+	if got, want := b.Polygon(), NewPolygonFlat(XY, []float64{0, 0, 2, 0, 2, 4, 0, 4, 0, 0}, []int{10}); !reflect.DeepEqual(got, want) {
+		t.Errorf("%v.Polygon() == %v, want %v", b, got, want)
+	}
+	if got, want := b.Clone().Buffer(1), NewBounds(XYZ).Set(-1, -1, 5, 3, 5, 7); !reflect.DeepEqual(got, want) {
+		t.Errorf("%v.Buffer(1) == %v, want %v", b, got, want)
+	}
+	if got := b.Clone().Buffer(-1.5); !got.IsEmpty() || got.Area() != 0 {
+		t.Errorf("%v.Buffer(-1.5) == %v, want an empty bounds", b, got)
+	}
+	if got, want := NewBounds(XY).Polygon(), NewPolygon(XY); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewBounds(XY).Polygon() == %v, want %v", got, want)
+	}
+}
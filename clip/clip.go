@@ -0,0 +1,484 @@
+// Package clip implements fast clipping of geometries to rectangles, for
+// example when cutting geometries into tiles.
+//
+// Lines are clipped with the Liang-Barsky algorithm.  Polygons are clipped by
+// clipping each of their rings as lines and then joining the pieces that
+// remain along the boundary of the rectangle, in the style of the
+// Weiler-Atherton algorithm, so that, unlike with the Sutherland-Hodgman
+// algorithm, concave polygons that are cut into several pieces produce
+// several valid polygons rather than a single polygon joined by zero-width
+// bridges along the boundary.
+//
+// Coordinates inserted where geometries cross the boundary of the rectangle
+// have their Z and M values, if any, interpolated.
+package clip
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/location"
+)
+
+// ToBounds returns the part of g that lies within the X and Y ranges of b.
+// Points and MultiPoints are returned as a MultiPoint, LineStrings,
+// LinearRings, and MultiLineStrings as a MultiLineString, and Polygons and
+// MultiPolygons as a MultiPolygon, any of which may be empty.  Line strings
+// and rings that collapse to a single point are removed.  Exterior rings of
+// clipped polygons are oriented counter-clockwise and holes clockwise.
+// Vertices added at the corners of b take their Z and M values, if any, from
+// the preceding vertex, or zero if the whole of b lies inside a polygon.  The
+// result has the same layout and SRID as g.
+func ToBounds(g geom.T, b *geom.Bounds) (geom.T, error) {
+	layout, stride := g.Layout(), g.Stride()
+	r := rect{
+		minX: b.Min(0),
+		minY: b.Min(1),
+		maxX: b.Max(0),
+		maxY: b.Max(1),
+	}
+	switch g := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		var flatCoords []float64
+		for i := 0; i < len(g.FlatCoords()); i += stride {
+			if coord := g.FlatCoords()[i : i+stride]; r.contains(coord) {
+				flatCoords = append(flatCoords, coord...)
+			}
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords).SetSRID(g.SRID()), nil
+	case *geom.LineString, *geom.LinearRing, *geom.MultiLineString:
+		var ends []int
+		switch g := g.(type) {
+		case *geom.MultiLineString:
+			ends = g.Ends()
+		default:
+			ends = []int{len(g.FlatCoords())}
+		}
+		var flatCoords []float64
+		var clippedEnds []int
+		offset := 0
+		for _, end := range ends {
+			for _, line := range r.clipLine(g.FlatCoords()[offset:end], stride) {
+				flatCoords = append(flatCoords, line...)
+				clippedEnds = append(clippedEnds, len(flatCoords))
+			}
+			offset = end
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, clippedEnds).SetSRID(g.SRID()), nil
+	case *geom.Polygon, *geom.MultiPolygon:
+		var endss [][]int
+		switch g := g.(type) {
+		case *geom.Polygon:
+			endss = [][]int{g.Ends()}
+		case *geom.MultiPolygon:
+			endss = g.Endss()
+		}
+		var flatCoords []float64
+		var clippedEndss [][]int
+		offset := 0
+		for _, ends := range endss {
+			if len(ends) == 0 {
+				continue
+			}
+			var rings [][]float64
+			for _, end := range ends {
+				rings = append(rings, g.FlatCoords()[offset:end])
+				offset = end
+			}
+			for _, polygon := range r.clipPolygon(rings, layout) {
+				var clippedEnds []int
+				for _, ring := range polygon {
+					flatCoords = append(flatCoords, ring...)
+					clippedEnds = append(clippedEnds, len(flatCoords))
+				}
+				clippedEndss = append(clippedEndss, clippedEnds)
+			}
+		}
+		return geom.NewMultiPolygonFlat(layout, flatCoords, clippedEndss).SetSRID(g.SRID()), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// A rect is an axis-aligned rectangle.
+type rect struct {
+	minX, minY, maxX, maxY float64
+}
+
+// Edges of a rect.
+const (
+	noEdge = iota
+	left
+	right
+	bottom
+	top
+)
+
+// contains returns true if coord lies within r or on its boundary.
+func (r rect) contains(coord []float64) bool {
+	return r.minX <= coord[0] && coord[0] <= r.maxX && r.minY <= coord[1] && coord[1] <= r.maxY
+}
+
+// clipSegment clips the segment from start to end to r using the Liang-Barsky
+// algorithm.  It returns the parameters of the clipped segment along the
+// segment and the edges of r at which they lie, or noEdge.  ok is false if the
+// segment lies entirely outside r.
+func (r rect) clipSegment(start, end []float64) (t0, t1 float64, edge0, edge1 int, ok bool) {
+	t0, t1 = 0, 1
+	dx, dy := end[0]-start[0], end[1]-start[1]
+	for _, c := range [4]struct {
+		p, q float64
+		edge int
+	}{
+		{-dx, start[0] - r.minX, left},
+		{dx, r.maxX - start[0], right},
+		{-dy, start[1] - r.minY, bottom},
+		{dy, r.maxY - start[1], top},
+	} {
+		switch {
+		case c.p == 0:
+			if c.q < 0 {
+				return 0, 0, noEdge, noEdge, false
+			}
+		case c.p < 0:
+			if t := c.q / c.p; t > t1 {
+				return 0, 0, noEdge, noEdge, false
+			} else if t > t0 {
+				t0, edge0 = t, c.edge
+			}
+		default:
+			if t := c.q / c.p; t < t0 {
+				return 0, 0, noEdge, noEdge, false
+			} else if t < t1 {
+				t1, edge1 = t, c.edge
+			}
+		}
+	}
+	return t0, t1, edge0, edge1, true
+}
+
+// interpolate returns the coordinate at parameter t along the segment from
+// start to end, snapped exactly onto edge.
+func (r rect) interpolate(start, end []float64, t float64, edge int) []float64 {
+	coord := make([]float64, len(start))
+	for i := range coord {
+		coord[i] = start[i] + t*(end[i]-start[i])
+	}
+	switch edge {
+	case left:
+		coord[0] = r.minX
+	case right:
+		coord[0] = r.maxX
+	case bottom:
+		coord[1] = r.minY
+	case top:
+		coord[1] = r.maxY
+	}
+	return coord
+}
+
+// clipLine returns the pieces of the line string in flatCoords that lie
+// within r.  Repeated consecutive coordinates are removed and pieces that
+// collapse to a single point are omitted.
+func (r rect) clipLine(flatCoords []float64, stride int) [][]float64 {
+	var lines [][]float64
+	var line []float64
+	flush := func() {
+		if len(line) >= 2*stride {
+			lines = append(lines, line)
+		}
+		line = nil
+	}
+	for i := stride; i < len(flatCoords); i += stride {
+		start, end := flatCoords[i-stride:i], flatCoords[i:i+stride]
+		t0, t1, edge0, edge1, ok := r.clipSegment(start, end)
+		if !ok {
+			continue
+		}
+		clippedStart := start
+		if t0 > 0 {
+			clippedStart = r.interpolate(start, end, t0, edge0)
+		}
+		if n := len(line); n != 0 && !equalXY(line[n-stride:], clippedStart) {
+			flush()
+		}
+		line = flat.AppendUnrepeated(line, clippedStart, stride)
+		if t1 < 1 {
+			line = flat.AppendUnrepeated(line, r.interpolate(start, end, t1, edge1), stride)
+			flush()
+		} else {
+			line = flat.AppendUnrepeated(line, end, stride)
+		}
+	}
+	flush()
+	return lines
+}
+
+// A piece is a part of a ring that lies within a rect, starting and ending
+// on its boundary.
+type piece struct {
+	flatCoords []float64
+	// start and end are the positions of the start and end of the piece
+	// along the boundary of the rect.
+	start, end float64
+}
+
+// piecesByStart sorts the indexes of pieces by the starts of the pieces.
+type piecesByStart struct {
+	indexes []int
+	pieces  []piece
+}
+
+func (s piecesByStart) Len() int { return len(s.indexes) }
+func (s piecesByStart) Less(i, j int) bool {
+	return s.pieces[s.indexes[i]].start < s.pieces[s.indexes[j]].start
+}
+func (s piecesByStart) Swap(i, j int) { s.indexes[i], s.indexes[j] = s.indexes[j], s.indexes[i] }
+
+// clipPolygon returns the rings of the polygons that are the parts of the
+// polygon with the given rings that lie within r.
+func (r rect) clipPolygon(rings [][]float64, layout geom.Layout) [][][]float64 {
+	stride := layout.Stride()
+	if len(rings[0]) < 4*stride {
+		return nil
+	}
+	exteriorBounds := geom.NewLinearRingFlat(layout, rings[0]).Bounds()
+	if exteriorBounds.IsEmpty() || exteriorBounds.Max(0) < r.minX || r.maxX < exteriorBounds.Min(0) || exteriorBounds.Max(1) < r.minY || r.maxY < exteriorBounds.Min(1) {
+		return nil
+	}
+	if r.minX <= exteriorBounds.Min(0) && exteriorBounds.Max(0) <= r.maxX && r.minY <= exteriorBounds.Min(1) && exteriorBounds.Max(1) <= r.maxY {
+		polygon := make([][]float64, len(rings))
+		for i, ring := range rings {
+			polygon[i] = append([]float64(nil), ring...)
+		}
+		return [][][]float64{polygon}
+	}
+
+	// Orient the exterior ring counter-clockwise and holes clockwise, so that
+	// the interior is always on the left, and clip each ring.
+	var pieces []piece
+	var insideHoles, outsideHoles [][]float64
+	for i, ring := range rings {
+		if len(ring) < 4*stride {
+			continue
+		}
+		ring = append([]float64(nil), ring...)
+		if xy.IsRingCounterClockwise(layout, ring) != (i == 0) {
+			flat.Reverse(ring, stride)
+		}
+		outside := -1
+		for j := 0; j < len(ring); j += stride {
+			if !r.contains(ring[j : j+stride]) {
+				outside = j
+				break
+			}
+		}
+		if outside == -1 {
+			if i != 0 {
+				insideHoles = append(insideHoles, ring)
+			}
+			continue
+		}
+		lines := r.clipLine(rotate(ring, outside, stride), stride)
+		if len(lines) == 0 && i != 0 {
+			outsideHoles = append(outsideHoles, ring)
+		}
+		for _, line := range lines {
+			pieces = append(pieces, piece{
+				flatCoords: line,
+				start:      r.position(line[:stride]),
+				end:        r.position(line[len(line)-stride:]),
+			})
+		}
+	}
+
+	var shells [][]float64
+	if len(pieces) == 0 {
+		// The boundary of the polygon does not cross r, so r lies either
+		// entirely inside or entirely outside the polygon.
+		center := geom.Coord{(r.minX + r.maxX) / 2, (r.minY + r.maxY) / 2}
+		if xy.LocatePointInRing(layout, center, rings[0]) != location.Interior {
+			return nil
+		}
+		for _, hole := range outsideHoles {
+			if xy.LocatePointInRing(layout, center, hole) == location.Interior {
+				return nil
+			}
+		}
+		shell := make([]float64, 0, 5*stride)
+		for _, corner := range [][2]float64{
+			{r.minX, r.minY}, {r.maxX, r.minY}, {r.maxX, r.maxY}, {r.minX, r.maxY}, {r.minX, r.minY},
+		} {
+			coord := make([]float64, stride)
+			coord[0], coord[1] = corner[0], corner[1]
+			shell = append(shell, coord...)
+		}
+		shells = append(shells, shell)
+	} else {
+		shells = r.joinPieces(pieces, layout)
+	}
+
+	polygons := make([][][]float64, len(shells))
+	for i, shell := range shells {
+		polygons[i] = [][]float64{shell}
+	}
+	for _, hole := range insideHoles {
+		for i, shell := range shells {
+			if holeInShell(layout, hole, shell) {
+				polygons[i] = append(polygons[i], hole)
+				break
+			}
+		}
+	}
+	return polygons
+}
+
+// joinPieces joins pieces into rings by following the boundary of r
+// counter-clockwise from the end of each piece to the start of the next, and
+// returns the rings with a positive area.
+func (r rect) joinPieces(pieces []piece, layout geom.Layout) [][]float64 {
+	stride := layout.Stride()
+	perimeter := 2 * ((r.maxX - r.minX) + (r.maxY - r.minY))
+	corners := [4][2]float64{
+		{r.maxX, r.minY},
+		{r.maxX, r.maxY},
+		{r.minX, r.maxY},
+		{r.minX, r.minY},
+	}
+	cornerPositions := [4]float64{
+		r.maxX - r.minX,
+		(r.maxX - r.minX) + (r.maxY - r.minY),
+		2*(r.maxX-r.minX) + (r.maxY - r.minY),
+		perimeter,
+	}
+	// distance returns the distance along the boundary counter-clockwise
+	// from position from to position to.
+	distance := func(from, to float64) float64 {
+		d := math.Mod(to-from, perimeter)
+		if d < 0 {
+			d += perimeter
+		}
+		return d
+	}
+
+	byStart := make([]int, len(pieces))
+	for i := range byStart {
+		byStart[i] = i
+	}
+	sort.Stable(piecesByStart{indexes: byStart, pieces: pieces})
+	visited := make([]bool, len(pieces))
+
+	var rings [][]float64
+	for first := range pieces {
+		if visited[first] {
+			continue
+		}
+		var ring []float64
+		for current := first; ; {
+			visited[current] = true
+			for i := 0; i < len(pieces[current].flatCoords); i += stride {
+				ring = flat.AppendUnrepeated(ring, pieces[current].flatCoords[i:i+stride], stride)
+			}
+			// find the next piece counter-clockwise along the boundary
+			end := pieces[current].end
+			k := sort.Search(len(byStart), func(i int) bool {
+				return pieces[byStart[i]].start >= end
+			})
+			next := -1
+			for i := 0; i < len(byStart); i++ {
+				if candidate := byStart[(k+i)%len(byStart)]; !visited[candidate] || candidate == first {
+					next = candidate
+					break
+				}
+			}
+			if next == -1 {
+				break
+			}
+			// add the corners between the end of this piece and the start of
+			// the next
+			d := distance(end, pieces[next].start)
+			last := ring[len(ring)-stride:]
+			var cornerCoords []float64
+			for i := range corners {
+				j := (sort.SearchFloat64s(cornerPositions[:], end) + i) % len(corners)
+				if cd := distance(end, cornerPositions[j]); 0 < cd && cd < d {
+					coord := append([]float64(nil), last...)
+					coord[0], coord[1] = corners[j][0], corners[j][1]
+					cornerCoords = append(cornerCoords, coord...)
+				}
+			}
+			for i := 0; i < len(cornerCoords); i += stride {
+				ring = flat.AppendUnrepeated(ring, cornerCoords[i:i+stride], stride)
+			}
+			if next == first {
+				break
+			}
+			current = next
+		}
+		ring = flat.AppendUnrepeated(ring, ring[:stride], stride)
+		if len(ring) >= 4*stride && xy.SignedArea(layout, ring) < 0 {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// position returns the position of coord, which must lie on the boundary of
+// r, measured counter-clockwise along the boundary from r's bottom left
+// corner.
+func (r rect) position(coord []float64) float64 {
+	x, y := coord[0], coord[1]
+	width, height := r.maxX-r.minX, r.maxY-r.minY
+	distances := [4]float64{
+		math.Abs(y - r.minY),
+		math.Abs(x - r.maxX),
+		math.Abs(y - r.maxY),
+		math.Abs(x - r.minX),
+	}
+	edge := 0
+	for i, d := range distances {
+		if d < distances[edge] {
+			edge = i
+		}
+	}
+	switch edge {
+	case 0:
+		return x - r.minX
+	case 1:
+		return width + (y - r.minY)
+	case 2:
+		return width + height + (r.maxX - x)
+	default:
+		return 2*width + height + (r.maxY - y)
+	}
+}
+
+// holeInShell returns true if hole lies inside shell.
+func holeInShell(layout geom.Layout, hole, shell []float64) bool {
+	stride := layout.Stride()
+	for i := 0; i < len(hole); i += stride {
+		switch xy.LocatePointInRing(layout, hole[i:i+stride], shell) {
+		case location.Interior:
+			return true
+		case location.Exterior:
+			return false
+		}
+	}
+	return false
+}
+
+func equalXY(c1, c2 []float64) bool {
+	return c1[0] == c2[0] && c1[1] == c2[1]
+}
+
+// rotate returns a copy of the closed ring in flatCoords starting at offset.
+func rotate(flatCoords []float64, offset, stride int) []float64 {
+	open := flatCoords[:len(flatCoords)-stride]
+	rotated := make([]float64, 0, len(flatCoords))
+	rotated = append(rotated, open[offset:]...)
+	rotated = append(rotated, open[:offset]...)
+	return append(rotated, open[offset:offset+stride]...)
+}
@@ -0,0 +1,108 @@
+package clip
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestToBounds(t *testing.T) {
+	b := geom.NewBounds(geom.XY).Set(0, 0, 10, 10)
+	for i, tc := range []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{5, 5}),
+			want: geom.NewMultiPointFlat(geom.XY, []float64{5, 5}),
+		},
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{15, 5}),
+			want: geom.NewMultiPoint(geom.XY),
+		},
+		{
+			g:    geom.NewMultiPointFlat(geom.XYZ, []float64{5, 5, 1, 11, 5, 2, 10, 10, 3}),
+			want: geom.NewMultiPointFlat(geom.XYZ, []float64{5, 5, 1, 10, 10, 3}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{-5, 5, 0, 15, 5, 20}),
+			want: geom.NewMultiLineStringFlat(geom.XYZ, []float64{0, 5, 5, 10, 5, 15}, []int{6}),
+		},
+		{
+			// a line that leaves and re-enters the rectangle is split
+			g:    geom.NewLineStringFlat(geom.XY, []float64{5, 5, 5, 15, 7, 15, 7, 5}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{5, 5, 5, 10, 7, 10, 7, 5}, []int{4, 8}),
+		},
+		{
+			// a line that only touches a corner is removed
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{-5, 5, 5, -5, 1, 1, 2, 2}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{1, 1, 2, 2}, []int{4}),
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{-5, 0, 5, 0, 5, 10, -5, 10, -5, 0}, []int{10}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 5, 0, 5, 10, 0, 10, 0, 0}, [][]int{{10}}),
+		},
+		{
+			// a concave polygon is cut into separate pieces
+			g: geom.NewPolygonFlat(geom.XY, []float64{2, 5, 4, 5, 4, 12, 6, 12, 6, 5, 8, 5, 8, 15, 2, 15, 2, 5}, []int{18}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				6, 10, 6, 5, 8, 5, 8, 10, 6, 10,
+				2, 10, 2, 5, 4, 5, 4, 10, 2, 10,
+			}, [][]int{{10}, {20}}),
+		},
+		{
+			// a clockwise polygon crossing every edge, with M interpolated
+			g: geom.NewPolygonFlat(geom.XYM, []float64{5, -2, 1, -2, 5, 2, 5, 12, 3, 12, 5, 4, 5, -2, 1}, []int{15}),
+			want: geom.NewMultiPolygonFlat(geom.XYM, []float64{
+				7, 0, 13.0 / 7, 10, 3, 22.0 / 7, 10, 7, 26.0 / 7, 7, 10, 23.0 / 7,
+				3, 10, 19.0 / 7, 0, 7, 16.0 / 7, 0, 3, 12.0 / 7, 3, 0, 9.0 / 7,
+				7, 0, 13.0 / 7,
+			}, [][]int{{27}}),
+		},
+		{
+			// a hole that crosses the rectangle becomes part of the exterior
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				-5, -5, 15, -5, 15, 15, -5, 15, -5, -5,
+				3, 3, 3, 12, 5, 12, 5, 3, 3, 3,
+			}, []int{10, 20}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{5, 10, 5, 3, 3, 3, 3, 10, 0, 10, 0, 0, 10, 0, 10, 10, 5, 10}, [][]int{{18}}),
+		},
+		{
+			// the rectangle lies inside the polygon, and a hole inside the
+			// rectangle is kept
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				-10, -10, 20, -10, 20, 20, -10, 20, -10, -10,
+				4, 4, 4, 6, 6, 6, 6, 4, 4, 4,
+			}, []int{10, 20}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 0, 10, 0, 0, 4, 4, 4, 6, 6, 6, 6, 4, 4, 4}, [][]int{{10, 20}}),
+		},
+		{
+			// the rectangle lies inside a hole
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				-10, -10, 20, -10, 20, 20, -10, 20, -10, -10,
+				-5, -5, -5, 15, 15, 15, 15, -5, -5, -5,
+			}, []int{10, 20}),
+			want: geom.NewMultiPolygon(geom.XY),
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				5, 5, 15, 5, 15, 15, 5, 15, 5, 5, 6, 6, 6, 7, 7, 7, 7, 6, 6, 6,
+				20, 20, 30, 20, 30, 30, 20, 20,
+			}, [][]int{{10, 20}, {28}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{5, 10, 5, 5, 10, 5, 10, 10, 5, 10, 6, 6, 6, 7, 7, 7, 7, 6, 6, 6}, [][]int{{10, 20}}),
+		},
+	} {
+		got, err := ToBounds(tc.g, b)
+		if err != nil || !geom.EqualTolerance(got, tc.want, 1e-9) {
+			t.Errorf("%d: ToBounds(%v, %v) == %v, %v, want %v, <nil>", i, tc.g, b, got, err, tc.want)
+		}
+	}
+}
+
+func TestToBoundsSRID(t *testing.T) {
+	g := geom.NewLineStringFlat(geom.XY, []float64{-5, 5, 15, 5}).SetSRID(4326)
+	got, err := ToBounds(g, geom.NewBounds(geom.XY).Set(0, 0, 10, 10))
+	if err != nil || got.SRID() != 4326 {
+		t.Errorf("ToBounds(%v, ...) == %v, %v, want SRID 4326, <nil>", g, got, err)
+	}
+}
@@ -1,8 +1,14 @@
 package ewkb
 
 import (
+	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
 
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
@@ -42,3 +48,56 @@ func Example_scan() {
 	// SRID: 4326
 
 }
+
+func TestScanValue(t *testing.T) {
+	pt := geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326)
+	data, err := hex.DecodeString("0101000020e6100000000000000000f03f0000000000000040")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, src := range []interface{}{data, []byte("0101000020e6100000000000000000f03f0000000000000040"), "0101000020e6100000000000000000f03f0000000000000040"} {
+		var p Point
+		if err := p.Scan(src); err != nil || !reflect.DeepEqual(&p.Point, pt) {
+			t.Errorf("%d: Scan(%v) == %v, got %v, want <nil>, %v", i, src, err, &p.Point, pt)
+		}
+		var g Geom
+		if err := g.Scan(src); err != nil || !reflect.DeepEqual(g.T, pt) {
+			t.Errorf("%d: Scan(%v) == %v, got %v, want <nil>, %v", i, src, err, g.T, pt)
+		}
+	}
+
+	for i, tc := range []struct {
+		v    driver.Valuer
+		want driver.Value
+	}{
+		{v: Point{Point: *pt}, want: data},
+		{v: NullPoint{Point: Point{Point: *pt}, Valid: true}, want: data},
+		{v: NullPoint{}, want: nil},
+		{v: Geom{T: pt}, want: data},
+		{v: Geom{}, want: nil},
+	} {
+		if got, err := tc.v.Value(); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: %v.Value() == %v, %v, want %v, <nil>", i, tc.v, got, err, tc.want)
+		}
+	}
+
+	var p Point
+	if err := p.Scan(nil); err == nil {
+		t.Errorf("Scan(nil) == <nil>, want !<nil>")
+	}
+	np := NullPoint{Valid: true}
+	if err := np.Scan(nil); err != nil || np.Valid {
+		t.Errorf("Scan(nil) == %v, Valid %v, want <nil>, false", err, np.Valid)
+	}
+	if err := np.Scan(data); err != nil || !np.Valid || !reflect.DeepEqual(&np.Point.Point, pt) {
+		t.Errorf("Scan(%v) == %v, got %v, want <nil>, %v", data, err, &np.Point.Point, pt)
+	}
+	g := Geom{T: pt}
+	if err := g.Scan(nil); err != nil || g.T != nil {
+		t.Errorf("Scan(nil) == %v, got %v, want <nil>, nil", err, g.T)
+	}
+	var ls LineString
+	if err := ls.Scan(data); err == nil {
+		t.Errorf("Scan(%v) into LineString == <nil>, want !<nil>", data)
+	}
+}
@@ -0,0 +1,405 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// This file contains a minimal FlatBuffers implementation, sufficient for the
+// FlatGeobuf schema.
+
+var errInvalidFlatBuffer = errors.New("flatgeobuf: invalid flatbuffer")
+
+// An fbTable is a table in a FlatBuffer being read.
+type fbTable struct {
+	buf    []byte
+	pos    int
+	vtable int
+	vsize  int
+}
+
+// fbRoot returns the root table of buf.
+func fbRoot(buf []byte) (fbTable, error) {
+	if len(buf) < 4 {
+		return fbTable{}, errInvalidFlatBuffer
+	}
+	return fbTableAt(buf, int(binary.LittleEndian.Uint32(buf)))
+}
+
+func fbTableAt(buf []byte, pos int) (fbTable, error) {
+	if pos < 0 || pos+4 > len(buf) {
+		return fbTable{}, errInvalidFlatBuffer
+	}
+	vtable := pos - int(int32(binary.LittleEndian.Uint32(buf[pos:])))
+	if vtable < 0 || vtable+4 > len(buf) {
+		return fbTable{}, errInvalidFlatBuffer
+	}
+	vsize := int(binary.LittleEndian.Uint16(buf[vtable:]))
+	if vsize < 4 || vtable+vsize > len(buf) {
+		return fbTable{}, errInvalidFlatBuffer
+	}
+	return fbTable{buf: buf, pos: pos, vtable: vtable, vsize: vsize}, nil
+}
+
+// offset returns the position of field in the buffer, or 0 if the field is
+// not present.
+func (t fbTable) offset(field int) int {
+	o := 4 + 2*field
+	if o+2 > t.vsize {
+		return 0
+	}
+	if off := int(binary.LittleEndian.Uint16(t.buf[t.vtable+o:])); off != 0 {
+		return t.pos + off
+	}
+	return 0
+}
+
+func (t fbTable) scalar(field, size int) ([]byte, bool) {
+	p := t.offset(field)
+	if p == 0 || p+size > len(t.buf) {
+		return nil, false
+	}
+	return t.buf[p : p+size], true
+}
+
+func (t fbTable) uint8(field int, def uint8) uint8 {
+	if b, ok := t.scalar(field, 1); ok {
+		return b[0]
+	}
+	return def
+}
+
+func (t fbTable) bool(field int, def bool) bool {
+	if b, ok := t.scalar(field, 1); ok {
+		return b[0] != 0
+	}
+	return def
+}
+
+func (t fbTable) uint16(field int, def uint16) uint16 {
+	if b, ok := t.scalar(field, 2); ok {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return def
+}
+
+func (t fbTable) int32(field int, def int32) int32 {
+	if b, ok := t.scalar(field, 4); ok {
+		return int32(binary.LittleEndian.Uint32(b))
+	}
+	return def
+}
+
+func (t fbTable) uint64(field int, def uint64) uint64 {
+	if b, ok := t.scalar(field, 8); ok {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return def
+}
+
+// indirect returns the position referenced by the offset field, or 0 if the
+// field is not present.
+func (t fbTable) indirect(field int) (int, error) {
+	p := t.offset(field)
+	if p == 0 {
+		return 0, nil
+	}
+	if p+4 > len(t.buf) {
+		return 0, errInvalidFlatBuffer
+	}
+	target := p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+	if target+4 > len(t.buf) {
+		return 0, errInvalidFlatBuffer
+	}
+	return target, nil
+}
+
+// vector returns the position of the first element and the length of the
+// vector field with elements of size elemSize.
+func (t fbTable) vector(field, elemSize int) (int, int, error) {
+	p, err := t.indirect(field)
+	if err != nil || p == 0 {
+		return 0, 0, err
+	}
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	if n < 0 || n > (len(t.buf)-p-4)/elemSize {
+		return 0, 0, errInvalidFlatBuffer
+	}
+	return p + 4, n, nil
+}
+
+func (t fbTable) bytes(field int) ([]byte, error) {
+	p, n, err := t.vector(field, 1)
+	if err != nil || p == 0 {
+		return nil, err
+	}
+	return t.buf[p : p+n], nil
+}
+
+func (t fbTable) string(field int) (string, error) {
+	b, err := t.bytes(field)
+	return string(b), err
+}
+
+func (t fbTable) float64s(field int) ([]float64, error) {
+	p, n, err := t.vector(field, 8)
+	if err != nil || p == 0 {
+		return nil, err
+	}
+	vs := make([]float64, n)
+	for i := range vs {
+		vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(t.buf[p+8*i:]))
+	}
+	return vs, nil
+}
+
+func (t fbTable) uint32s(field int) ([]uint32, error) {
+	p, n, err := t.vector(field, 4)
+	if err != nil || p == 0 {
+		return nil, err
+	}
+	vs := make([]uint32, n)
+	for i := range vs {
+		vs[i] = binary.LittleEndian.Uint32(t.buf[p+4*i:])
+	}
+	return vs, nil
+}
+
+func (t fbTable) table(field int) (fbTable, bool, error) {
+	p, err := t.indirect(field)
+	if err != nil || p == 0 {
+		return fbTable{}, false, err
+	}
+	st, err := fbTableAt(t.buf, p)
+	return st, err == nil, err
+}
+
+func (t fbTable) tables(field int) ([]fbTable, error) {
+	p, n, err := t.vector(field, 4)
+	if err != nil || p == 0 {
+		return nil, err
+	}
+	ts := make([]fbTable, n)
+	for i := range ts {
+		ep := p + 4*i
+		if ts[i], err = fbTableAt(t.buf, ep+int(binary.LittleEndian.Uint32(t.buf[ep:]))); err != nil {
+			return nil, err
+		}
+	}
+	return ts, nil
+}
+
+// An fbObject is an object to be written to a FlatBuffer.
+type fbObject interface {
+	write(b *fbBuilder) int
+}
+
+// An fbValue is a field of a table being built.  Exactly one of scalar and
+// ref is set for present fields.
+type fbValue struct {
+	scalar []byte
+	ref    fbObject
+}
+
+// An fbTableBuilder describes a table to be written.  The index of each value
+// is its field id.
+type fbTableBuilder []fbValue
+
+func (t *fbTableBuilder) set(field int, v fbValue) {
+	for len(*t) <= field {
+		*t = append(*t, fbValue{})
+	}
+	(*t)[field] = v
+}
+
+func (t *fbTableBuilder) setUint8(field int, v uint8) {
+	t.set(field, fbValue{scalar: []byte{v}})
+}
+
+func (t *fbTableBuilder) setBool(field int, v bool) {
+	if v {
+		t.setUint8(field, 1)
+	} else {
+		t.setUint8(field, 0)
+	}
+}
+
+func (t *fbTableBuilder) setUint16(field int, v uint16) {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	t.set(field, fbValue{scalar: b})
+}
+
+func (t *fbTableBuilder) setInt32(field int, v int32) {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	t.set(field, fbValue{scalar: b})
+}
+
+func (t *fbTableBuilder) setUint64(field int, v uint64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	t.set(field, fbValue{scalar: b})
+}
+
+func (t *fbTableBuilder) setRef(field int, ref fbObject) {
+	t.set(field, fbValue{ref: ref})
+}
+
+// An fbString is a string to be written.
+type fbString string
+
+// An fbVector is a vector of scalars to be written, already encoded in little
+// endian byte order.
+type fbVector struct {
+	elemSize int
+	data     []byte
+}
+
+func fbFloat64s(vs []float64) fbVector {
+	data := make([]byte, 8*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return fbVector{elemSize: 8, data: data}
+}
+
+func fbUint32s(vs []uint32) fbVector {
+	data := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return fbVector{elemSize: 4, data: data}
+}
+
+// An fbTableVector is a vector of tables to be written.
+type fbTableVector []fbTableBuilder
+
+// An fbBuilder writes FlatBuffers front to back.  Children are always written
+// after their parents so that all unsigned offsets point forwards, and all
+// values are aligned relative to the start of the buffer.
+type fbBuilder struct {
+	buf []byte
+}
+
+// finishSizePrefixed returns a size-prefixed FlatBuffer with root table t.
+func finishSizePrefixed(t fbTableBuilder) []byte {
+	b := &fbBuilder{buf: make([]byte, 8)}
+	root := t.write(b)
+	binary.LittleEndian.PutUint32(b.buf[0:], uint32(len(b.buf)-4))
+	binary.LittleEndian.PutUint32(b.buf[4:], uint32(root-4))
+	return b.buf
+}
+
+// pad pads the buffer so that len(b.buf)+offset is a multiple of alignment.
+func (b *fbBuilder) pad(alignment, offset int) {
+	for (len(b.buf)+offset)%alignment != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) patchOffset(at, target int) {
+	binary.LittleEndian.PutUint32(b.buf[at:], uint32(target-at))
+}
+
+func (s fbString) write(b *fbBuilder) int {
+	b.pad(4, 0)
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+func (v fbVector) write(b *fbBuilder) int {
+	alignment := v.elemSize
+	if alignment < 4 {
+		alignment = 4
+	}
+	b.pad(alignment, 4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(len(v.data)/v.elemSize))
+	b.buf = append(b.buf, v.data...)
+	return pos
+}
+
+func (v fbTableVector) write(b *fbBuilder) int {
+	b.pad(4, 0)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4+4*len(v))...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(len(v)))
+	for i, t := range v {
+		b.patchOffset(pos+4+4*i, t.write(b))
+	}
+	return pos
+}
+
+// size returns the size of field i in the table.
+func (t fbTableBuilder) size(i int) int {
+	if t[i].ref != nil {
+		return 4
+	}
+	return len(t[i].scalar)
+}
+
+// fieldsBySize sorts the fields of a table, largest first.
+type fieldsBySize struct {
+	fields []int
+	t      fbTableBuilder
+}
+
+func (s fieldsBySize) Len() int           { return len(s.fields) }
+func (s fieldsBySize) Less(i, j int) bool { return s.t.size(s.fields[i]) > s.t.size(s.fields[j]) }
+func (s fieldsBySize) Swap(i, j int)      { s.fields[i], s.fields[j] = s.fields[j], s.fields[i] }
+
+func (t fbTableBuilder) write(b *fbBuilder) int {
+	// lay out the fields after the vtable offset, largest first
+	fields := make([]int, 0, len(t))
+	for i, v := range t {
+		if v.scalar != nil || v.ref != nil {
+			fields = append(fields, i)
+		}
+	}
+	sort.Stable(fieldsBySize{fields: fields, t: t})
+	offsets := make([]int, len(t))
+	tableSize := 4
+	for _, i := range fields {
+		for tableSize%t.size(i) != 0 {
+			tableSize++
+		}
+		offsets[i] = tableSize
+		tableSize += t.size(i)
+	}
+
+	// write the vtable
+	b.pad(2, 0)
+	vtable := len(b.buf)
+	vt := make([]byte, 4+2*len(t))
+	binary.LittleEndian.PutUint16(vt[0:], uint16(len(vt)))
+	binary.LittleEndian.PutUint16(vt[2:], uint16(tableSize))
+	for i, offset := range offsets {
+		binary.LittleEndian.PutUint16(vt[4+2*i:], uint16(offset))
+	}
+	b.buf = append(b.buf, vt...)
+
+	// write the table
+	b.pad(8, 0)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, tableSize)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(int32(pos-vtable)))
+	for _, i := range fields {
+		copy(b.buf[pos+offsets[i]:], t[i].scalar)
+	}
+
+	// write the children
+	for _, i := range fields {
+		if t[i].ref != nil {
+			b.patchOffset(pos+offsets[i], t[i].ref.write(b))
+		}
+	}
+	return pos
+}
@@ -0,0 +1,536 @@
+// Package flatgeobuf implements FlatGeobuf encoding and decoding.
+//
+// FlatGeobuf files contain a header, an optional packed Hilbert R-tree spatial
+// index, and a sequence of features.  The spatial index allows a Reader to
+// read only the features whose bounding boxes intersect a query bounds,
+// skipping all others, without reading the whole file.
+//
+// See https://flatgeobuf.org/.
+package flatgeobuf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/geomutil"
+)
+
+// magic is the magic number at the start of every file.  The fourth byte is
+// the major version and the last byte the patch version.
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+// A GeometryType is a FlatGeobuf geometry type.
+type GeometryType uint8
+
+// Geometry types.
+const (
+	GeometryTypeUnknown GeometryType = iota
+	GeometryTypePoint
+	GeometryTypeLineString
+	GeometryTypePolygon
+	GeometryTypeMultiPoint
+	GeometryTypeMultiLineString
+	GeometryTypeMultiPolygon
+)
+
+// A ColumnType is the type of a column.
+type ColumnType uint8
+
+// Column types.
+const (
+	ColumnTypeByte ColumnType = iota
+	ColumnTypeUByte
+	ColumnTypeBool
+	ColumnTypeShort
+	ColumnTypeUShort
+	ColumnTypeInt
+	ColumnTypeUInt
+	ColumnTypeLong
+	ColumnTypeULong
+	ColumnTypeFloat
+	ColumnTypeDouble
+	ColumnTypeString
+	ColumnTypeJSON
+	ColumnTypeDateTime
+	ColumnTypeBinary
+)
+
+// Header table field ids.
+const (
+	headerName          = 0
+	headerEnvelope      = 1
+	headerGeometryType  = 2
+	headerHasZ          = 3
+	headerHasM          = 4
+	headerColumns       = 7
+	headerFeaturesCount = 8
+	headerIndexNodeSize = 9
+	headerCRS           = 10
+
+	columnName = 0
+	columnType = 1
+
+	crsOrg  = 0
+	crsCode = 1
+
+	featureGeometry   = 0
+	featureProperties = 1
+	featureColumns    = 2
+)
+
+var (
+	errInvalidProperties = errors.New("flatgeobuf: invalid properties")
+	errSearchAfterNext   = errors.New("flatgeobuf: Search called after Next")
+)
+
+// An ErrBadMagic is returned when a file does not start with the FlatGeobuf
+// magic number.
+type ErrBadMagic [8]byte
+
+func (e ErrBadMagic) Error() string {
+	return fmt.Sprintf("flatgeobuf: bad magic %q", e[:])
+}
+
+// An ErrUnsupportedGeometryType is returned when a geometry type cannot be
+// decoded.
+type ErrUnsupportedGeometryType GeometryType
+
+func (e ErrUnsupportedGeometryType) Error() string {
+	return fmt.Sprintf("flatgeobuf: unsupported geometry type %d", int(e))
+}
+
+// An ErrUnsupportedValue is returned when a property value cannot be encoded.
+type ErrUnsupportedValue struct {
+	Name  string
+	Value interface{}
+}
+
+func (e ErrUnsupportedValue) Error() string {
+	return fmt.Sprintf("flatgeobuf: %s: unsupported value type %T", e.Name, e.Value)
+}
+
+// A Column describes a property.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// A Header describes the features in a file.
+type Header struct {
+	Name          string
+	GeometryType  GeometryType
+	Layout        geom.Layout
+	Columns       []Column
+	FeaturesCount uint64
+	IndexNodeSize uint16
+	Envelope      *geom.Bounds
+	SRID          int
+}
+
+// A Feature is a geometry with properties.
+type Feature struct {
+	Geometry   geom.T
+	Properties map[string]interface{}
+}
+
+// Write writes features to w.  The Name, Columns, IndexNodeSize, and SRID of
+// h are used; the other fields are computed from features.  If h.Columns is
+// nil then a column is created for each property, sorted by name, with a type
+// inferred from the property's values.  If h.IndexNodeSize is zero then
+// DefaultIndexNodeSize is used, and if it is NoIndex then no spatial index is
+// written.  All geometries must have the same layout.
+// Features are written in the order of the spatial index, which is not
+// necessarily the order of features.
+func Write(w io.Writer, h *Header, features []*Feature) error {
+	columns := h.Columns
+	if columns == nil {
+		var err error
+		if columns, err = inferColumns(features); err != nil {
+			return err
+		}
+	}
+	indexNodeSize := h.IndexNodeSize
+	switch indexNodeSize {
+	case 0:
+		indexNodeSize = DefaultIndexNodeSize
+	case NoIndex:
+		indexNodeSize = 0
+	}
+
+	layout := geom.NoLayout
+	t := GeometryTypeUnknown
+	envelope := emptyNode()
+	leaves := make([]node, len(features))
+	buffers := make([][]byte, len(features))
+	for i, f := range features {
+		leaves[i] = emptyNode()
+		var ft fbTableBuilder
+		if f.Geometry != nil {
+			gt, err := geometryTypeOf(f.Geometry)
+			if err != nil {
+				return err
+			}
+			switch {
+			case layout == geom.NoLayout:
+				layout = f.Geometry.Layout()
+				t = gt
+			case f.Geometry.Layout() != layout:
+				return geom.ErrLayoutMismatch{Got: f.Geometry.Layout(), Want: layout}
+			case gt != t:
+				t = GeometryTypeUnknown
+			}
+			g, err := encodeGeometry(f.Geometry)
+			if err != nil {
+				return err
+			}
+			ft.setRef(featureGeometry, g)
+			if b := f.Geometry.Bounds(); !b.IsEmpty() {
+				leaves[i] = node{minX: b.Min(0), minY: b.Min(1), maxX: b.Max(0), maxY: b.Max(1)}
+				envelope.extend(leaves[i])
+			}
+		}
+		properties, err := encodeProperties(f.Properties, columns)
+		if err != nil {
+			return err
+		}
+		if len(properties) != 0 {
+			ft.setRef(featureProperties, fbVector{elemSize: 1, data: properties})
+		}
+		buffers[i] = finishSizePrefixed(ft)
+	}
+	if layout == geom.NoLayout {
+		layout = geom.XY
+	}
+
+	// sort the features and compute their offsets
+	indexes := hilbertSort(leaves, envelope)
+	sortedLeaves := make([]node, len(leaves))
+	var offset uint64
+	for i, j := range indexes {
+		sortedLeaves[i] = leaves[j]
+		sortedLeaves[i].offset = offset
+		offset += uint64(len(buffers[j]))
+	}
+
+	var ht fbTableBuilder
+	if h.Name != "" {
+		ht.setRef(headerName, fbString(h.Name))
+	}
+	if envelope.minX <= envelope.maxX {
+		ht.setRef(headerEnvelope, fbFloat64s([]float64{envelope.minX, envelope.minY, envelope.maxX, envelope.maxY}))
+	}
+	ht.setUint8(headerGeometryType, uint8(t))
+	ht.setBool(headerHasZ, layout.ZIndex() != -1)
+	ht.setBool(headerHasM, layout.MIndex() != -1)
+	if len(columns) != 0 {
+		cts := make(fbTableVector, len(columns))
+		for i, c := range columns {
+			cts[i].setRef(columnName, fbString(c.Name))
+			cts[i].setUint8(columnType, uint8(c.Type))
+		}
+		ht.setRef(headerColumns, cts)
+	}
+	ht.setUint64(headerFeaturesCount, uint64(len(features)))
+	ht.setUint16(headerIndexNodeSize, indexNodeSize)
+	if h.SRID != 0 {
+		var crs fbTableBuilder
+		crs.setRef(crsOrg, fbString("EPSG"))
+		crs.setInt32(crsCode, int32(h.SRID))
+		ht.setRef(headerCRS, crs)
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(magic[:]); err != nil {
+		return err
+	}
+	if _, err := bw.Write(finishSizePrefixed(ht)); err != nil {
+		return err
+	}
+	if len(features) != 0 && indexNodeSize != 0 {
+		if _, err := bw.Write(buildIndex(sortedLeaves, indexNodeSize)); err != nil {
+			return err
+		}
+	}
+	for _, j := range indexes {
+		if _, err := bw.Write(buffers[j]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// A Reader reads features from a FlatGeobuf file.
+type Reader struct {
+	r       io.Reader
+	header  *Header
+	started bool
+	// pos is the offset of r relative to the start of the features.
+	pos   uint64
+	count uint64
+	// offsets, if not nil, are the offsets of the features still to be read.
+	offsets []uint64
+	// query, if not nil, is used to filter features when there is no index.
+	query *node
+}
+
+// NewReader reads the header from r and returns a new Reader.  If r is also
+// an io.Seeker then skipped data is seeked over rather than read.
+func NewReader(r io.Reader) (*Reader, error) {
+	var m [8]byte
+	if _, err := io.ReadFull(r, m[:]); err != nil {
+		return nil, err
+	}
+	// any patch version can be read
+	if !bytes.Equal(m[:7], magic[:7]) {
+		return nil, ErrBadMagic(m)
+	}
+	data, err := readSizePrefixed(r)
+	if err != nil {
+		return nil, err
+	}
+	header, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		r:      r,
+		header: header,
+	}, nil
+}
+
+// Header returns the header.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Search restricts the features returned by Next to those whose bounding
+// boxes intersect the XY bounds of b.  If the file has a spatial index then
+// only the matching features are read, otherwise all features are read and
+// filtered.  Search must be called before the first call to Next.
+func (r *Reader) Search(b *geom.Bounds) error {
+	if r.started {
+		return errSearchAfterNext
+	}
+	r.started = true
+	query := node{minX: b.Min(0), minY: b.Min(1), maxX: b.Max(0), maxY: b.Max(1)}
+	size := r.indexSize()
+	if size == 0 {
+		r.query = &query
+		return nil
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.r, int64(size)))
+	if err != nil {
+		return err
+	}
+	if uint64(len(data)) != size {
+		return io.ErrUnexpectedEOF
+	}
+	r.offsets = searchIndex(data, r.header.FeaturesCount, r.header.IndexNodeSize, query)
+	if r.offsets == nil {
+		r.offsets = []uint64{}
+	}
+	return nil
+}
+
+// Next returns the next feature, or io.EOF if there are no more features.
+func (r *Reader) Next() (*Feature, error) {
+	if !r.started {
+		r.started = true
+		if err := r.skip(r.indexSize()); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if r.offsets != nil {
+			if len(r.offsets) == 0 {
+				return nil, io.EOF
+			}
+			if err := r.skip(r.offsets[0] - r.pos); err != nil {
+				return nil, err
+			}
+			r.pos = r.offsets[0]
+			r.offsets = r.offsets[1:]
+		} else if r.header.FeaturesCount != 0 && r.count == r.header.FeaturesCount {
+			return nil, io.EOF
+		}
+		data, err := readSizePrefixed(r.r)
+		if err != nil {
+			return nil, err
+		}
+		r.pos += 4 + uint64(len(data))
+		r.count++
+		f, err := r.decodeFeature(data)
+		if err != nil {
+			return nil, err
+		}
+		if r.query != nil && !featureIntersects(f, *r.query) {
+			continue
+		}
+		return f, nil
+	}
+}
+
+// indexSize returns the size of the spatial index.
+func (r *Reader) indexSize() uint64 {
+	return indexSize(r.header.FeaturesCount, r.header.IndexNodeSize)
+}
+
+// skip skips n bytes.
+func (r *Reader) skip(n uint64) error {
+	if n == 0 {
+		return nil
+	}
+	if s, ok := r.r.(io.Seeker); ok && n <= math.MaxInt64 {
+		_, err := s.Seek(int64(n), io.SeekCurrent)
+		return err
+	}
+	switch m, err := io.CopyN(ioutil.Discard, r.r, int64(n)); {
+	case err == io.EOF:
+		return io.ErrUnexpectedEOF
+	case err != nil:
+		return err
+	case uint64(m) != n:
+		return io.ErrUnexpectedEOF
+	default:
+		return nil
+	}
+}
+
+func (r *Reader) decodeFeature(data []byte) (*Feature, error) {
+	ft, err := fbRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	f := &Feature{}
+	gt, ok, err := ft.table(featureGeometry)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if f.Geometry, err = decodeGeometry(gt, r.header.GeometryType, r.header.Layout); err != nil {
+			return nil, err
+		}
+		if r.header.SRID != 0 {
+			if f.Geometry, err = geomutil.SetSRID(f.Geometry, r.header.SRID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	columns := r.header.Columns
+	cts, err := ft.tables(featureColumns)
+	if err != nil {
+		return nil, err
+	}
+	if cts != nil {
+		if columns, err = decodeColumns(cts); err != nil {
+			return nil, err
+		}
+	}
+	properties, err := ft.bytes(featureProperties)
+	if err != nil {
+		return nil, err
+	}
+	if f.Properties, err = decodeProperties(properties, columns); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func featureIntersects(f *Feature, query node) bool {
+	if f.Geometry == nil {
+		return false
+	}
+	b := f.Geometry.Bounds()
+	if b.IsEmpty() {
+		return false
+	}
+	return query.intersects(node{minX: b.Min(0), minY: b.Min(1), maxX: b.Max(0), maxY: b.Max(1)})
+}
+
+// readSizePrefixed reads a size-prefixed FlatBuffer from r, excluding the
+// size.
+func readSizePrefixed(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := int64(binary.LittleEndian.Uint32(size[:]))
+	// read incrementally so a corrupt size cannot cause a huge allocation
+	data, err := ioutil.ReadAll(io.LimitReader(r, n))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+func decodeHeader(data []byte) (*Header, error) {
+	ht, err := fbRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	h := &Header{
+		GeometryType:  GeometryType(ht.uint8(headerGeometryType, 0)),
+		FeaturesCount: ht.uint64(headerFeaturesCount, 0),
+		IndexNodeSize: ht.uint16(headerIndexNodeSize, DefaultIndexNodeSize),
+	}
+	if h.Name, err = ht.string(headerName); err != nil {
+		return nil, err
+	}
+	switch hasZ, hasM := ht.bool(headerHasZ, false), ht.bool(headerHasM, false); {
+	case hasZ && hasM:
+		h.Layout = geom.XYZM
+	case hasZ:
+		h.Layout = geom.XYZ
+	case hasM:
+		h.Layout = geom.XYM
+	default:
+		h.Layout = geom.XY
+	}
+	envelope, err := ht.float64s(headerEnvelope)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope) >= 4 {
+		h.Envelope = geom.NewBounds(geom.XY).Set(envelope[0], envelope[1], envelope[2], envelope[3])
+	}
+	cts, err := ht.tables(headerColumns)
+	if err != nil {
+		return nil, err
+	}
+	if h.Columns, err = decodeColumns(cts); err != nil {
+		return nil, err
+	}
+	crs, ok, err := ht.table(headerCRS)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		h.SRID = int(crs.int32(crsCode, 0))
+	}
+	return h, nil
+}
+
+func decodeColumns(cts []fbTable) ([]Column, error) {
+	if len(cts) == 0 {
+		return nil, nil
+	}
+	columns := make([]Column, len(cts))
+	for i, ct := range cts {
+		name, err := ct.string(columnName)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = Column{Name: name, Type: ColumnType(ct.uint8(columnType, 0))}
+	}
+	return columns, nil
+}
@@ -0,0 +1,306 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+// reader hides any io.Seeker implementation of the underlying io.Reader.
+type reader struct {
+	io.Reader
+}
+
+func readAll(t *testing.T, r io.Reader, b *geom.Bounds) (*Header, []*Feature) {
+	fr, err := NewReader(r)
+	if err != nil {
+		t.Fatalf("NewReader(...) == _, %v, want _, <nil>", err)
+	}
+	if b != nil {
+		if err := fr.Search(b); err != nil {
+			t.Fatalf("Search(%v) == %v, want <nil>", b, err)
+		}
+	}
+	var features []*Feature
+	for {
+		f, err := fr.Next()
+		if err == io.EOF {
+			return fr.Header(), features
+		}
+		if err != nil {
+			t.Fatalf("Next() == _, %v, want _, <nil>", err)
+		}
+		features = append(features, f)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for i, tc := range []struct {
+		h        *Header
+		features []*Feature
+		want     *Header
+	}{
+		{
+			h: &Header{Name: "points", SRID: 4326},
+			features: []*Feature{
+				{
+					Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+					Properties: map[string]interface{}{
+						"name":   "a",
+						"count":  int64(-3),
+						"ratio":  0.5,
+						"small":  float32(0.25),
+						"flag":   true,
+						"byte":   uint8(7),
+						"short":  int16(-300),
+						"int":    int32(1 << 20),
+						"ulong":  uint64(1 << 40),
+						"binary": []byte{1, 2, 3},
+					},
+				},
+				{
+					Geometry:   geom.NewPointFlat(geom.XY, []float64{3, 4}).SetSRID(4326),
+					Properties: map[string]interface{}{"name": "b"},
+				},
+			},
+			want: &Header{
+				Name:         "points",
+				GeometryType: GeometryTypePoint,
+				Layout:       geom.XY,
+				Columns: []Column{
+					{Name: "binary", Type: ColumnTypeBinary},
+					{Name: "byte", Type: ColumnTypeUByte},
+					{Name: "count", Type: ColumnTypeLong},
+					{Name: "flag", Type: ColumnTypeBool},
+					{Name: "int", Type: ColumnTypeInt},
+					{Name: "name", Type: ColumnTypeString},
+					{Name: "ratio", Type: ColumnTypeDouble},
+					{Name: "short", Type: ColumnTypeShort},
+					{Name: "small", Type: ColumnTypeFloat},
+					{Name: "ulong", Type: ColumnTypeULong},
+				},
+				FeaturesCount: 2,
+				IndexNodeSize: DefaultIndexNodeSize,
+				Envelope:      geom.NewBounds(geom.XY).Set(1, 2, 3, 4),
+				SRID:          4326,
+			},
+		},
+		{
+			h: &Header{IndexNodeSize: NoIndex},
+			features: []*Feature{
+				{Geometry: geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 4, 0, 2, 0, 4, 3, 0, 0, 1}, []int{12})},
+				{Geometry: geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 1, 1, 1, 2})},
+				{Geometry: geom.NewMultiPointFlat(geom.XYZ, []float64{5, 5, 5})},
+				{
+					Geometry: geom.NewMultiLineStringFlat(geom.XYZ, []float64{
+						0, 0, 0, 1, 1, 1,
+						2, 2, 2, 3, 3, 3, 4, 4, 4,
+					}, []int{6, 15}),
+				},
+				{
+					Geometry: geom.NewMultiPolygonFlat(geom.XYZ, []float64{
+						0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 0, 0,
+						10, 10, 0, 20, 10, 0, 20, 20, 0, 10, 20, 0, 10, 10, 0,
+						12, 12, 0, 12, 18, 0, 18, 18, 0, 12, 12, 0,
+					}, [][]int{{12}, {27, 39}}),
+				},
+				{},
+			},
+			want: &Header{
+				GeometryType:  GeometryTypeUnknown,
+				Layout:        geom.XYZ,
+				FeaturesCount: 6,
+				Envelope:      geom.NewBounds(geom.XY).Set(0, 0, 20, 20),
+			},
+		},
+		{
+			h: &Header{},
+			features: []*Feature{
+				{Geometry: geom.NewLineStringFlat(geom.XYZM, []float64{0, 0, 1, 2, 1, 1, 3, 4})},
+				{Geometry: geom.NewLineStringFlat(geom.XYZM, []float64{5, 5, 6, 7, 8, 9, 10, 11})},
+			},
+			want: &Header{
+				GeometryType:  GeometryTypeLineString,
+				Layout:        geom.XYZM,
+				FeaturesCount: 2,
+				IndexNodeSize: DefaultIndexNodeSize,
+				Envelope:      geom.NewBounds(geom.XY).Set(0, 0, 8, 9),
+			},
+		},
+		{
+			h: &Header{},
+			want: &Header{
+				Layout:        geom.XY,
+				IndexNodeSize: DefaultIndexNodeSize,
+			},
+		},
+	} {
+		var buf bytes.Buffer
+		if err := Write(&buf, tc.h, tc.features); err != nil {
+			t.Errorf("%d: Write(...) == %v, want <nil>", i, err)
+			continue
+		}
+		header, features := readAll(t, bytes.NewReader(buf.Bytes()), nil)
+		if !reflect.DeepEqual(header, tc.want) {
+			t.Errorf("%d: got header %+v, want %+v", i, header, tc.want)
+		}
+		// features are written in index order, so match them up
+		if len(features) != len(tc.features) {
+			t.Errorf("%d: got %d features, want %d", i, len(features), len(tc.features))
+			continue
+		}
+		for _, want := range tc.features {
+			found := false
+			for _, got := range features {
+				if reflect.DeepEqual(got, want) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("%d: feature %+v not found", i, want)
+			}
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var features []*Feature
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 30; y++ {
+			features = append(features, &Feature{
+				Geometry:   geom.NewPointFlat(geom.XY, []float64{float64(x), float64(y)}),
+				Properties: map[string]interface{}{"id": int64(100*x + y)},
+			})
+		}
+	}
+	for _, indexNodeSize := range []uint16{0, 2, 7, NoIndex} {
+		var buf bytes.Buffer
+		if err := Write(&buf, &Header{IndexNodeSize: indexNodeSize}, features); err != nil {
+			t.Fatalf("Write(...) == %v, want <nil>", err)
+		}
+		for _, b := range []*geom.Bounds{
+			geom.NewBounds(geom.XY).Set(-10, -10, -1, -1),
+			geom.NewBounds(geom.XY).Set(0, 0, 0, 0),
+			geom.NewBounds(geom.XY).Set(2.5, 3.5, 7, 9),
+			geom.NewBounds(geom.XY).Set(10, -5, 12, 100),
+			geom.NewBounds(geom.XY).Set(-100, -100, 100, 100),
+		} {
+			var want []int64
+			for _, f := range features {
+				if b.OverlapsPoint(geom.XY, f.Geometry.(*geom.Point).Coords()) {
+					want = append(want, f.Properties["id"].(int64))
+				}
+			}
+			for _, r := range []io.Reader{bytes.NewReader(buf.Bytes()), reader{bytes.NewReader(buf.Bytes())}} {
+				_, got := readAll(t, r, b)
+				ids := make(map[int64]bool)
+				for _, f := range got {
+					ids[f.Properties["id"].(int64)] = true
+				}
+				if len(got) != len(want) || len(ids) != len(want) {
+					t.Errorf("indexNodeSize %d: Search(%v) returned %d features, want %d", indexNodeSize, b, len(got), len(want))
+					continue
+				}
+				for _, id := range want {
+					if !ids[id] {
+						t.Errorf("indexNodeSize %d: Search(%v) did not return %d", indexNodeSize, b, id)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLevelBounds(t *testing.T) {
+	for i, tc := range []struct {
+		numItems      uint64
+		indexNodeSize uint16
+		want          [][2]uint64
+	}{
+		{numItems: 1, indexNodeSize: 16, want: [][2]uint64{{1, 2}, {0, 1}}},
+		{numItems: 16, indexNodeSize: 16, want: [][2]uint64{{1, 17}, {0, 1}}},
+		{numItems: 17, indexNodeSize: 16, want: [][2]uint64{{3, 20}, {1, 3}, {0, 1}}},
+	} {
+		if got := levelBounds(tc.numItems, tc.indexNodeSize); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: levelBounds(%d, %d) == %v, want %v", i, tc.numItems, tc.indexNodeSize, got, tc.want)
+		}
+	}
+}
+
+func TestHilbert(t *testing.T) {
+	// the first n*n values of the curve visit each point in the n by n square
+	// at the origin exactly once, moving one unit at each step
+	const n = 16
+	var xs, ys [n * n]int
+	seen := make(map[uint32]bool)
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			h := hilbert(uint32(x), uint32(y))
+			if h >= n*n || seen[h] {
+				t.Fatalf("hilbert(%d, %d) == %d, want a unique value less than %d", x, y, h, n*n)
+			}
+			seen[h] = true
+			xs[h], ys[h] = x, y
+		}
+	}
+	for i := 1; i < n*n; i++ {
+		if dx, dy := xs[i]-xs[i-1], ys[i]-ys[i-1]; dx*dx+dy*dy != 1 {
+			t.Errorf("step from %d to %d is (%d, %d), want a unit step", i-1, i, dx, dy)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for i, tc := range []struct {
+		h        *Header
+		features []*Feature
+	}{
+		{
+			h:        &Header{},
+			features: []*Feature{{Geometry: geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 1, 1, 0, 0})}},
+		},
+		{
+			h: &Header{},
+			features: []*Feature{
+				{Geometry: geom.NewPointFlat(geom.XY, []float64{0, 0})},
+				{Geometry: geom.NewPointFlat(geom.XYZ, []float64{0, 0, 0})},
+			},
+		},
+		{
+			h:        &Header{},
+			features: []*Feature{{Properties: map[string]interface{}{"a": []int{1}}}},
+		},
+		{
+			h:        &Header{Columns: []Column{{Name: "a", Type: ColumnTypeString}}},
+			features: []*Feature{{Properties: map[string]interface{}{"a": 1}}},
+		},
+	} {
+		if err := Write(&bytes.Buffer{}, tc.h, tc.features); err == nil {
+			t.Errorf("%d: Write(...) == <nil>, want !<nil>", i)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, &Header{}, []*Feature{{Geometry: geom.NewPointFlat(geom.XY, []float64{0, 0})}}); err != nil {
+		t.Fatalf("Write(...) == %v, want <nil>", err)
+	}
+	data := buf.Bytes()
+	for i, data := range [][]byte{
+		nil,
+		[]byte("fgb\x02fgb\x00\x00\x00\x00\x00"),
+		data[:12],
+		data[:len(data)-1],
+	} {
+		r, err := NewReader(bytes.NewReader(data))
+		if err == nil {
+			_, err = r.Next()
+		}
+		if err == nil {
+			t.Errorf("%d: reading %v succeeded, want error", i, data)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package flatgeobuf
+
+import (
+	"github.com/twpayne/go-geom"
+)
+
+// Geometry table field ids.
+const (
+	geometryEnds  = 0
+	geometryXY    = 1
+	geometryZ     = 2
+	geometryM     = 3
+	geometryType  = 6
+	geometryParts = 7
+)
+
+// geometryTypeOf returns the GeometryType of g.
+func geometryTypeOf(g geom.T) (GeometryType, error) {
+	switch g.(type) {
+	case *geom.Point:
+		return GeometryTypePoint, nil
+	case *geom.LineString:
+		return GeometryTypeLineString, nil
+	case *geom.Polygon:
+		return GeometryTypePolygon, nil
+	case *geom.MultiPoint:
+		return GeometryTypeMultiPoint, nil
+	case *geom.MultiLineString:
+		return GeometryTypeMultiLineString, nil
+	case *geom.MultiPolygon:
+		return GeometryTypeMultiPolygon, nil
+	default:
+		return GeometryTypeUnknown, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// encodeGeometry returns the Geometry table for g.
+func encodeGeometry(g geom.T) (fbTableBuilder, error) {
+	t, err := geometryTypeOf(g)
+	if err != nil {
+		return nil, err
+	}
+	if mp, ok := g.(*geom.MultiPolygon); ok {
+		parts := make(fbTableVector, mp.NumPolygons())
+		for i := range parts {
+			if parts[i], err = encodeGeometry(mp.Polygon(i)); err != nil {
+				return nil, err
+			}
+		}
+		var tb fbTableBuilder
+		tb.setUint8(geometryType, uint8(t))
+		tb.setRef(geometryParts, parts)
+		return tb, nil
+	}
+	return encodeFlat(t, g.Layout(), g.FlatCoords(), g.Ends()), nil
+}
+
+// encodeFlat returns the Geometry table for a geometry of type t with the
+// given flat coordinates and ends.
+func encodeFlat(t GeometryType, layout geom.Layout, flatCoords []float64, ends []int) fbTableBuilder {
+	stride := layout.Stride()
+	n := len(flatCoords) / stride
+	xy := make([]float64, 0, 2*n)
+	var z, m []float64
+	zIndex, mIndex := layout.ZIndex(), layout.MIndex()
+	for i := 0; i < len(flatCoords); i += stride {
+		xy = append(xy, flatCoords[i], flatCoords[i+1])
+		if zIndex != -1 {
+			z = append(z, flatCoords[i+zIndex])
+		}
+		if mIndex != -1 {
+			m = append(m, flatCoords[i+mIndex])
+		}
+	}
+
+	var tb fbTableBuilder
+	// ends are only needed when there is more than one part
+	if len(ends) > 1 {
+		fbEnds := make([]uint32, len(ends))
+		for i, end := range ends {
+			fbEnds[i] = uint32(end / stride)
+		}
+		tb.setRef(geometryEnds, fbUint32s(fbEnds))
+	}
+	tb.setRef(geometryXY, fbFloat64s(xy))
+	if zIndex != -1 {
+		tb.setRef(geometryZ, fbFloat64s(z))
+	}
+	if mIndex != -1 {
+		tb.setRef(geometryM, fbFloat64s(m))
+	}
+	tb.setUint8(geometryType, uint8(t))
+	return tb
+}
+
+// decodeGeometry decodes the Geometry table gt.  The geometry type stored in
+// gt takes precedence over t.
+func decodeGeometry(gt fbTable, t GeometryType, layout geom.Layout) (geom.T, error) {
+	if gtt := GeometryType(gt.uint8(geometryType, 0)); gtt != GeometryTypeUnknown {
+		t = gtt
+	}
+
+	if t == GeometryTypeMultiPolygon {
+		parts, err := gt.tables(geometryParts)
+		if err != nil {
+			return nil, err
+		}
+		mp := geom.NewMultiPolygon(layout)
+		for _, part := range parts {
+			g, err := decodeGeometry(part, GeometryTypePolygon, layout)
+			if err != nil {
+				return nil, err
+			}
+			p, ok := g.(*geom.Polygon)
+			if !ok {
+				return nil, ErrUnsupportedGeometryType(t)
+			}
+			if err := mp.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	}
+
+	flatCoords, err := decodeFlatCoords(gt, layout)
+	if err != nil {
+		return nil, err
+	}
+	stride := layout.Stride()
+	fbEnds, err := gt.uint32s(geometryEnds)
+	if err != nil {
+		return nil, err
+	}
+	var ends []int
+	for _, end := range fbEnds {
+		if e := int(end) * stride; e <= len(flatCoords) {
+			ends = append(ends, e)
+		} else {
+			return nil, errInvalidFlatBuffer
+		}
+	}
+	if len(ends) == 0 && len(flatCoords) != 0 {
+		ends = []int{len(flatCoords)}
+	}
+
+	switch t {
+	case GeometryTypePoint:
+		if len(flatCoords) != 0 && len(flatCoords) != stride {
+			return nil, errInvalidFlatBuffer
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case GeometryTypeLineString:
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case GeometryTypePolygon:
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+	case GeometryTypeMultiPoint:
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	case GeometryTypeMultiLineString:
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+	default:
+		return nil, ErrUnsupportedGeometryType(t)
+	}
+}
+
+// decodeFlatCoords returns the flat coordinates stored in gt.
+func decodeFlatCoords(gt fbTable, layout geom.Layout) ([]float64, error) {
+	xy, err := gt.float64s(geometryXY)
+	if err != nil {
+		return nil, err
+	}
+	if len(xy)%2 != 0 {
+		return nil, errInvalidFlatBuffer
+	}
+	n := len(xy) / 2
+	var z, m []float64
+	zIndex, mIndex := layout.ZIndex(), layout.MIndex()
+	if zIndex != -1 {
+		if z, err = gt.float64s(geometryZ); err != nil {
+			return nil, err
+		}
+		if len(z) != n {
+			return nil, errInvalidFlatBuffer
+		}
+	}
+	if mIndex != -1 {
+		if m, err = gt.float64s(geometryM); err != nil {
+			return nil, err
+		}
+		if len(m) != n {
+			return nil, errInvalidFlatBuffer
+		}
+	}
+	stride := layout.Stride()
+	flatCoords := make([]float64, stride*n)
+	for i := 0; i < n; i++ {
+		flatCoords[stride*i] = xy[2*i]
+		flatCoords[stride*i+1] = xy[2*i+1]
+		if zIndex != -1 {
+			flatCoords[stride*i+zIndex] = z[i]
+		}
+		if mIndex != -1 {
+			flatCoords[stride*i+mIndex] = m[i]
+		}
+	}
+	return flatCoords, nil
+}
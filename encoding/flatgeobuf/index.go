@@ -0,0 +1,247 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// DefaultIndexNodeSize is the default number of children of each node in the
+// spatial index.
+const DefaultIndexNodeSize = 16
+
+// NoIndex is the IndexNodeSize that requests that no spatial index is written.
+// It is never returned by a Reader, which reports files without an index as
+// having an IndexNodeSize of zero.
+const NoIndex = 1
+
+// nodeSize is the size in bytes of a node in the spatial index.
+const nodeSize = 40
+
+// hilbertMax is the maximum ordinate value used when computing Hilbert
+// values.
+const hilbertMax = 1<<16 - 1
+
+// A node is a node in the packed Hilbert R-tree.  For leaf nodes, offset is
+// the byte offset of the feature relative to the start of the features.  For
+// other nodes, offset is the index of the node's first child.
+type node struct {
+	minX, minY, maxX, maxY float64
+	offset                 uint64
+}
+
+func emptyNode() node {
+	return node{
+		minX: math.Inf(1),
+		minY: math.Inf(1),
+		maxX: math.Inf(-1),
+		maxY: math.Inf(-1),
+	}
+}
+
+func (n *node) extend(o node) {
+	n.minX = math.Min(n.minX, o.minX)
+	n.minY = math.Min(n.minY, o.minY)
+	n.maxX = math.Max(n.maxX, o.maxX)
+	n.maxY = math.Max(n.maxY, o.maxY)
+}
+
+func (n node) intersects(o node) bool {
+	return n.maxX >= o.minX && n.maxY >= o.minY && n.minX <= o.maxX && n.minY <= o.maxY
+}
+
+// levelBounds returns the start and end node indexes of each level of a
+// packed R-tree with numItems leaves, starting with the leaves.  The root is
+// stored first.
+func levelBounds(numItems uint64, indexNodeSize uint16) [][2]uint64 {
+	n := numItems
+	numNodes := n
+	levelNumNodes := []uint64{n}
+	for {
+		n = (n + uint64(indexNodeSize) - 1) / uint64(indexNodeSize)
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+		if n == 1 {
+			break
+		}
+	}
+	bounds := make([][2]uint64, len(levelNumNodes))
+	n = numNodes
+	for i, size := range levelNumNodes {
+		bounds[i] = [2]uint64{n - size, n}
+		n -= size
+	}
+	return bounds
+}
+
+// indexSize returns the size in bytes of the index for numItems features.
+func indexSize(numItems uint64, indexNodeSize uint16) uint64 {
+	if numItems == 0 || indexNodeSize < 2 {
+		return 0
+	}
+	lb := levelBounds(numItems, indexNodeSize)
+	return lb[0][1] * nodeSize
+}
+
+// buildIndex returns the encoded packed R-tree with the given leaves, which
+// must already be sorted.
+func buildIndex(leaves []node, indexNodeSize uint16) []byte {
+	lb := levelBounds(uint64(len(leaves)), indexNodeSize)
+	nodes := make([]node, lb[0][1])
+	copy(nodes[lb[0][0]:], leaves)
+	for level := 0; level < len(lb)-1; level++ {
+		start, end := lb[level][0], lb[level][1]
+		parent := lb[level+1][0]
+		for i := start; i < end; i += uint64(indexNodeSize) {
+			n := emptyNode()
+			n.offset = i
+			for j := i; j < i+uint64(indexNodeSize) && j < end; j++ {
+				n.extend(nodes[j])
+			}
+			nodes[parent] = n
+			parent++
+		}
+	}
+	data := make([]byte, nodeSize*len(nodes))
+	for i, n := range nodes {
+		b := data[nodeSize*i:]
+		binary.LittleEndian.PutUint64(b[0:], math.Float64bits(n.minX))
+		binary.LittleEndian.PutUint64(b[8:], math.Float64bits(n.minY))
+		binary.LittleEndian.PutUint64(b[16:], math.Float64bits(n.maxX))
+		binary.LittleEndian.PutUint64(b[24:], math.Float64bits(n.maxY))
+		binary.LittleEndian.PutUint64(b[32:], n.offset)
+	}
+	return data
+}
+
+func decodeNode(data []byte, i uint64) node {
+	b := data[nodeSize*i:]
+	return node{
+		minX:   math.Float64frombits(binary.LittleEndian.Uint64(b[0:])),
+		minY:   math.Float64frombits(binary.LittleEndian.Uint64(b[8:])),
+		maxX:   math.Float64frombits(binary.LittleEndian.Uint64(b[16:])),
+		maxY:   math.Float64frombits(binary.LittleEndian.Uint64(b[24:])),
+		offset: binary.LittleEndian.Uint64(b[32:]),
+	}
+}
+
+// searchIndex returns the sorted offsets of the features whose bounding boxes
+// intersect query.
+func searchIndex(data []byte, numItems uint64, indexNodeSize uint16, query node) []uint64 {
+	lb := levelBounds(numItems, indexNodeSize)
+	type entry struct {
+		index uint64
+		level int
+	}
+	var offsets []uint64
+	queue := []entry{{index: 0, level: len(lb) - 1}}
+	for len(queue) != 0 {
+		e := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		end := e.index + uint64(indexNodeSize)
+		if levelEnd := lb[e.level][1]; end > levelEnd {
+			end = levelEnd
+		}
+		for i := e.index; i < end; i++ {
+			n := decodeNode(data, i)
+			if !n.intersects(query) {
+				continue
+			}
+			if e.level == 0 {
+				offsets = append(offsets, n.offset)
+			} else {
+				queue = append(queue, entry{index: n.offset, level: e.level - 1})
+			}
+		}
+	}
+	sort.Sort(uint64s(offsets))
+	return offsets
+}
+
+// uint64s sorts a slice of uint64s in increasing order.
+type uint64s []uint64
+
+func (s uint64s) Len() int           { return len(s) }
+func (s uint64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// hilbert returns the Hilbert value of the point (x, y), where x and y are
+// between 0 and hilbertMax.
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xffff ^ a
+	c := 0xffff ^ (x | y)
+	d := x & (y ^ 0xffff)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xffff ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00ff00ff
+	i0 = (i0 | (i0 << 4)) & 0x0f0f0f0f
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00ff00ff
+	i1 = (i1 | (i1 << 4)) & 0x0f0f0f0f
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
+
+// hilbertSort sorts the indexes of nodes by the Hilbert value of the centers
+// of their bounding boxes within extent.
+func hilbertSort(nodes []node, extent node) []int {
+	width, height := extent.maxX-extent.minX, extent.maxY-extent.minY
+	values := make([]uint32, len(nodes))
+	for i, n := range nodes {
+		var x, y uint32
+		if width > 0 && !math.IsInf(n.minX, 0) {
+			x = uint32(math.Floor(hilbertMax * ((n.minX+n.maxX)/2 - extent.minX) / width))
+		}
+		if height > 0 && !math.IsInf(n.minY, 0) {
+			y = uint32(math.Floor(hilbertMax * ((n.minY+n.maxY)/2 - extent.minY) / height))
+		}
+		values[i] = hilbert(x, y)
+	}
+	indexes := make([]int, len(nodes))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Stable(indexesByValue{indexes: indexes, values: values})
+	return indexes
+}
+
+// indexesByValue sorts indexes by their values.
+type indexesByValue struct {
+	indexes []int
+	values  []uint32
+}
+
+func (s indexesByValue) Len() int           { return len(s.indexes) }
+func (s indexesByValue) Less(i, j int) bool { return s.values[s.indexes[i]] < s.values[s.indexes[j]] }
+func (s indexesByValue) Swap(i, j int)      { s.indexes[i], s.indexes[j] = s.indexes[j], s.indexes[i] }
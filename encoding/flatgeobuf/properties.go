@@ -0,0 +1,251 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom/internal/property"
+)
+
+// columnTypeOf returns the ColumnType used to encode v.
+func columnTypeOf(v interface{}) (ColumnType, bool) {
+	switch v.(type) {
+	case int8:
+		return ColumnTypeByte, true
+	case uint8:
+		return ColumnTypeUByte, true
+	case bool:
+		return ColumnTypeBool, true
+	case int16:
+		return ColumnTypeShort, true
+	case uint16:
+		return ColumnTypeUShort, true
+	case int32:
+		return ColumnTypeInt, true
+	case uint32:
+		return ColumnTypeUInt, true
+	case int, int64:
+		return ColumnTypeLong, true
+	case uint, uint64:
+		return ColumnTypeULong, true
+	case float32:
+		return ColumnTypeFloat, true
+	case float64:
+		return ColumnTypeDouble, true
+	case string:
+		return ColumnTypeString, true
+	case []byte:
+		return ColumnTypeBinary, true
+	default:
+		return 0, false
+	}
+}
+
+// inferColumns returns columns for all the properties of features, sorted by
+// name.
+func inferColumns(features []*Feature) ([]Column, error) {
+	types := make(map[string]ColumnType)
+	var names []string
+	for _, f := range features {
+		for name, value := range f.Properties {
+			if value == nil {
+				continue
+			}
+			if _, ok := types[name]; ok {
+				continue
+			}
+			t, ok := columnTypeOf(value)
+			if !ok {
+				return nil, ErrUnsupportedValue{Name: name, Value: value}
+			}
+			types[name] = t
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	columns := make([]Column, len(names))
+	for i, name := range names {
+		columns[i] = Column{Name: name, Type: types[name]}
+	}
+	return columns, nil
+}
+
+// encodeProperties returns the encoded properties.  Properties that are nil
+// are omitted.
+func encodeProperties(properties map[string]interface{}, columns []Column) ([]byte, error) {
+	var b []byte
+	for i, c := range columns {
+		v := properties[c.Name]
+		if v == nil {
+			continue
+		}
+		b = append(b, byte(i), byte(i>>8))
+		var ok bool
+		if b, ok = appendValue(b, c.Type, v); !ok {
+			return nil, ErrUnsupportedValue{Name: c.Name, Value: v}
+		}
+	}
+	return b, nil
+}
+
+// appendValue appends the encoding of v as type t to b.
+func appendValue(b []byte, t ColumnType, v interface{}) ([]byte, bool) {
+	switch t {
+	case ColumnTypeBool:
+		v, ok := v.(bool)
+		if !ok {
+			return b, false
+		}
+		if v {
+			return append(b, 1), true
+		}
+		return append(b, 0), true
+	case ColumnTypeByte, ColumnTypeUByte, ColumnTypeShort, ColumnTypeUShort, ColumnTypeInt, ColumnTypeUInt, ColumnTypeLong, ColumnTypeULong:
+		i, ok := property.ToInt64(v)
+		if !ok {
+			return b, false
+		}
+		switch t {
+		case ColumnTypeByte, ColumnTypeUByte:
+			return append(b, byte(i)), true
+		case ColumnTypeShort, ColumnTypeUShort:
+			return append(b, byte(i), byte(i>>8)), true
+		case ColumnTypeInt, ColumnTypeUInt:
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], uint32(i))
+			return append(b, buf[:]...), true
+		default:
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], uint64(i))
+			return append(b, buf[:]...), true
+		}
+	case ColumnTypeFloat, ColumnTypeDouble:
+		var f float64
+		switch v := v.(type) {
+		case float32:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			i, ok := property.ToInt64(v)
+			if !ok {
+				return b, false
+			}
+			f = float64(i)
+		}
+		if t == ColumnTypeFloat {
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(f)))
+			return append(b, buf[:]...), true
+		}
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		return append(b, buf[:]...), true
+	case ColumnTypeString, ColumnTypeJSON, ColumnTypeDateTime, ColumnTypeBinary:
+		var data []byte
+		switch v := v.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			return b, false
+		}
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(len(data)))
+		return append(append(b, buf[:]...), data...), true
+	default:
+		return b, false
+	}
+}
+
+// decodeProperties decodes the properties in data.
+func decodeProperties(data []byte, columns []Column) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	properties := make(map[string]interface{})
+	for len(data) != 0 {
+		if len(data) < 2 {
+			return nil, errInvalidProperties
+		}
+		i := int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+		if i >= len(columns) {
+			return nil, errInvalidProperties
+		}
+		var value interface{}
+		var n int
+		switch t := columns[i].Type; t {
+		case ColumnTypeByte, ColumnTypeUByte, ColumnTypeBool:
+			if n = 1; len(data) < n {
+				return nil, errInvalidProperties
+			}
+			switch t {
+			case ColumnTypeByte:
+				value = int8(data[0])
+			case ColumnTypeUByte:
+				value = data[0]
+			default:
+				value = data[0] != 0
+			}
+		case ColumnTypeShort, ColumnTypeUShort:
+			if n = 2; len(data) < n {
+				return nil, errInvalidProperties
+			}
+			u := binary.LittleEndian.Uint16(data)
+			if t == ColumnTypeShort {
+				value = int16(u)
+			} else {
+				value = u
+			}
+		case ColumnTypeInt, ColumnTypeUInt, ColumnTypeFloat:
+			if n = 4; len(data) < n {
+				return nil, errInvalidProperties
+			}
+			u := binary.LittleEndian.Uint32(data)
+			switch t {
+			case ColumnTypeInt:
+				value = int32(u)
+			case ColumnTypeUInt:
+				value = u
+			default:
+				value = math.Float32frombits(u)
+			}
+		case ColumnTypeLong, ColumnTypeULong, ColumnTypeDouble:
+			if n = 8; len(data) < n {
+				return nil, errInvalidProperties
+			}
+			u := binary.LittleEndian.Uint64(data)
+			switch t {
+			case ColumnTypeLong:
+				value = int64(u)
+			case ColumnTypeULong:
+				value = u
+			default:
+				value = math.Float64frombits(u)
+			}
+		case ColumnTypeString, ColumnTypeJSON, ColumnTypeDateTime, ColumnTypeBinary:
+			if len(data) < 4 {
+				return nil, errInvalidProperties
+			}
+			size := binary.LittleEndian.Uint32(data)
+			data = data[4:]
+			if uint64(len(data)) < uint64(size) {
+				return nil, errInvalidProperties
+			}
+			n = int(size)
+			if t == ColumnTypeBinary {
+				value = append([]byte(nil), data[:n]...)
+			} else {
+				value = string(data[:n])
+			}
+		default:
+			return nil, errInvalidProperties
+		}
+		properties[columns[i].Name] = value
+		data = data[n:]
+	}
+	return properties, nil
+}
@@ -0,0 +1,191 @@
+package geohash
+
+import (
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/cover"
+)
+
+// Relationships between a cell and a geometry.
+const (
+	disjoint = iota
+	intersects
+	within
+)
+
+// A cell is the bounds of a geohash.
+type cell struct {
+	minX, minY, maxX, maxY float64
+}
+
+// A coverer tests cells against the lines and polygons of a geometry.
+type coverer struct {
+	*cover.Shape
+}
+
+// Cover returns the sorted geohashes with the given precision whose cells
+// intersect g.  Points and multipoints are covered by the geohashes of their
+// coordinates, as returned by Encode.  Other geometries are covered by every
+// cell that they intersect, including cells that they only touch.  The number
+// of geohashes grows exponentially with precision, so large geometries should
+// be covered at low precisions.
+func Cover(g geom.T, precision int) ([]string, error) {
+	if precision < 1 || precision > MaxPrecision {
+		return nil, ErrInvalidPrecision(precision)
+	}
+	switch g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return coverPoints(g.FlatCoords(), g.Stride(), precision)
+	}
+	shape, err := cover.NewShape(g)
+	if err != nil {
+		return nil, err
+	}
+	c := &coverer{Shape: shape}
+
+	var hashes []string
+	var visit func(hash string, cl cell)
+	visit = func(hash string, cl cell) {
+		switch c.relate(cl) {
+		case disjoint:
+			return
+		case within:
+			hashes = appendDescendants(hashes, hash, precision)
+			return
+		}
+		if len(hash) == precision {
+			hashes = append(hashes, hash)
+			return
+		}
+		for i, child := range cl.children(len(hash)%2 == 0) {
+			visit(hash+base32[i:i+1], child)
+		}
+	}
+	// visiting children in base32 order yields sorted geohashes
+	world := cell{minX: -180, minY: -90, maxX: 180, maxY: 90}
+	for i, child := range world.children(true) {
+		visit(base32[i:i+1], child)
+	}
+	return hashes, nil
+}
+
+// coverPoints returns the sorted, distinct geohashes of the points in
+// flatCoords.
+func coverPoints(flatCoords []float64, stride, precision int) ([]string, error) {
+	set := make(map[string]struct{})
+	for i := 0; i < len(flatCoords); i += stride {
+		hash, err := Encode(geom.Coord(flatCoords[i:i+stride]), precision)
+		if err != nil {
+			return nil, err
+		}
+		set[hash] = struct{}{}
+	}
+	var hashes []string
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+// appendDescendants appends hash, if it has the given precision, or all its
+// descendants with the given precision to hashes.
+func appendDescendants(hashes []string, hash string, precision int) []string {
+	if len(hash) == precision {
+		return append(hashes, hash)
+	}
+	for i := 0; i < len(base32); i++ {
+		hashes = appendDescendants(hashes, hash+base32[i:i+1], precision)
+	}
+	return hashes
+}
+
+// relate returns the relationship between cl and c's geometry.
+func (c *coverer) relate(cl cell) int {
+	for _, line := range c.Lines {
+		if len(line) == c.Stride && cl.intersectsSegment(line[0], line[1], line[0], line[1]) {
+			return intersects
+		}
+		for i := c.Stride; i < len(line); i += c.Stride {
+			if cl.intersectsSegment(line[i-c.Stride], line[i-c.Stride+1], line[i], line[i+1]) {
+				return intersects
+			}
+		}
+	}
+	// cl does not intersect any boundary, so it is either entirely inside or
+	// entirely outside each polygon
+	center := make(geom.Coord, c.Stride)
+	center[0], center[1] = (cl.minX+cl.maxX)/2, (cl.minY+cl.maxY)/2
+	if c.InPolygon(center) {
+		return within
+	}
+	return disjoint
+}
+
+// children returns the 32 children of cl, in base32 order.  Bits alternate
+// between longitude and latitude, so the first bit of each child bisects
+// longitude if lonFirst is true and latitude otherwise.
+func (cl cell) children(lonFirst bool) [32]cell {
+	var children [32]cell
+	for i := range children {
+		child := cl
+		lon := lonFirst
+		for mask := 16; mask != 0; mask >>= 1 {
+			if lon {
+				if mid := (child.minX + child.maxX) / 2; i&mask != 0 {
+					child.minX = mid
+				} else {
+					child.maxX = mid
+				}
+			} else {
+				if mid := (child.minY + child.maxY) / 2; i&mask != 0 {
+					child.minY = mid
+				} else {
+					child.maxY = mid
+				}
+			}
+			lon = !lon
+		}
+		children[i] = child
+	}
+	return children
+}
+
+// intersectsSegment returns true if the segment from (x0, y0) to (x1, y1)
+// intersects the closed cell cl, using the Liang-Barsky algorithm.
+func (cl cell) intersectsSegment(x0, y0, x1, y1 float64) bool {
+	t0, t1 := 0.0, 1.0
+	dx, dy := x1-x0, y1-y0
+	for _, pq := range [4][2]float64{
+		{-dx, x0 - cl.minX},
+		{dx, cl.maxX - x0},
+		{-dy, y0 - cl.minY},
+		{dy, cl.maxY - y0},
+	} {
+		p, q := pq[0], pq[1]
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			if r > t1 {
+				return false
+			}
+			if r > t0 {
+				t0 = r
+			}
+		} else {
+			if r < t0 {
+				return false
+			}
+			if r < t1 {
+				t1 = r
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+// Package geohash implements geohash encoding and decoding.
+//
+// Geohashes encode longitude and latitude, in degrees, as the X and Y
+// ordinates of a geom.Coord.
+//
+// See https://en.wikipedia.org/wiki/Geohash.
+package geohash
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// MaxPrecision is the maximum precision, i.e. the maximum number of
+// characters in a geohash.  A geohash of this precision encodes 60 bits.
+const MaxPrecision = 12
+
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// decodeMap maps characters to their values, or -1 if they are invalid.
+var decodeMap [256]int8
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = -1
+	}
+	for i := 0; i < len(base32); i++ {
+		decodeMap[base32[i]] = int8(i)
+	}
+}
+
+// An ErrInvalidPrecision is returned when a precision is out of range.
+type ErrInvalidPrecision int
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("geohash: invalid precision %d", int(e))
+}
+
+// An ErrInvalidGeohash is returned when a geohash is empty, too long, or
+// contains invalid characters.
+type ErrInvalidGeohash string
+
+func (e ErrInvalidGeohash) Error() string {
+	return fmt.Sprintf("geohash: invalid geohash %q", string(e))
+}
+
+// An ErrInvalidCoord is returned when a coordinate is not a valid longitude
+// and latitude.
+type ErrInvalidCoord struct {
+	Coord geom.Coord
+}
+
+func (e ErrInvalidCoord) Error() string {
+	return fmt.Sprintf("geohash: invalid coordinate %v", e.Coord)
+}
+
+// An ErrInvalidDirection is returned when a Direction is not valid.
+type ErrInvalidDirection Direction
+
+func (e ErrInvalidDirection) Error() string {
+	return fmt.Sprintf("geohash: invalid direction %d", int(e))
+}
+
+// A Direction is a direction to a neighbouring geohash.
+type Direction int
+
+// Directions.
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// offsets are the offsets of each Direction, in cells.
+var offsets = [...]struct{ dx, dy float64 }{
+	North:     {0, 1},
+	NorthEast: {1, 1},
+	East:      {1, 0},
+	SouthEast: {1, -1},
+	South:     {0, -1},
+	SouthWest: {-1, -1},
+	West:      {-1, 0},
+	NorthWest: {-1, 1},
+}
+
+// Encode returns the geohash of coord with the given precision.
+func Encode(coord geom.Coord, precision int) (string, error) {
+	if precision < 1 || precision > MaxPrecision {
+		return "", ErrInvalidPrecision(precision)
+	}
+	if len(coord) < 2 || !(-180 <= coord.X() && coord.X() <= 180) || !(-90 <= coord.Y() && coord.Y() <= 90) {
+		return "", ErrInvalidCoord{Coord: coord}
+	}
+	return encode(coord.X(), coord.Y(), precision), nil
+}
+
+// EncodePoint returns the geohash of p with the given precision.
+func EncodePoint(p *geom.Point, precision int) (string, error) {
+	return Encode(p.Coords(), precision)
+}
+
+// encode returns the geohash of (x, y), which must be in range, with the
+// given precision.
+func encode(x, y float64, precision int) string {
+	minX, maxX := -180.0, 180.0
+	minY, maxY := -90.0, 90.0
+	hash := make([]byte, 0, precision)
+	bits, value := 0, 0
+	for len(hash) < precision {
+		// even bits bisect longitude, odd bits bisect latitude
+		value <<= 1
+		if bits&1 == 0 {
+			if mid := (minX + maxX) / 2; x >= mid {
+				value |= 1
+				minX = mid
+			} else {
+				maxX = mid
+			}
+		} else {
+			if mid := (minY + maxY) / 2; y >= mid {
+				value |= 1
+				minY = mid
+			} else {
+				maxY = mid
+			}
+		}
+		if bits++; bits%5 == 0 {
+			hash = append(hash, base32[value])
+			value = 0
+		}
+	}
+	return string(hash)
+}
+
+// Decode returns the bounds of the cell identified by hash.
+func Decode(hash string) (*geom.Bounds, error) {
+	minX, minY, maxX, maxY, err := decode(hash)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewBounds(geom.XY).Set(minX, minY, maxX, maxY), nil
+}
+
+// DecodeCenter returns the center of the cell identified by hash.
+func DecodeCenter(hash string) (geom.Coord, error) {
+	minX, minY, maxX, maxY, err := decode(hash)
+	if err != nil {
+		return nil, err
+	}
+	return geom.Coord{(minX + maxX) / 2, (minY + maxY) / 2}, nil
+}
+
+// decode returns the bounds of the cell identified by hash.
+func decode(hash string) (minX, minY, maxX, maxY float64, err error) {
+	if len(hash) < 1 || len(hash) > MaxPrecision {
+		err = ErrInvalidGeohash(hash)
+		return
+	}
+	minX, maxX = -180, 180
+	minY, maxY = -90, 90
+	bits := 0
+	for i := 0; i < len(hash); i++ {
+		value := decodeMap[hash[i]]
+		if value < 0 {
+			err = ErrInvalidGeohash(hash)
+			return
+		}
+		for mask := int8(16); mask != 0; mask >>= 1 {
+			if bits&1 == 0 {
+				if mid := (minX + maxX) / 2; value&mask != 0 {
+					minX = mid
+				} else {
+					maxX = mid
+				}
+			} else {
+				if mid := (minY + maxY) / 2; value&mask != 0 {
+					minY = mid
+				} else {
+					maxY = mid
+				}
+			}
+			bits++
+		}
+	}
+	return
+}
+
+// Neighbour returns the geohash of the same precision adjacent to hash in
+// direction d.  Longitude wraps around the antimeridian.  It returns the empty
+// string if there is no neighbour because hash is adjacent to a pole.
+func Neighbour(hash string, d Direction) (string, error) {
+	minX, minY, maxX, maxY, err := decode(hash)
+	if err != nil {
+		return "", err
+	}
+	if d < North || d > NorthWest {
+		return "", ErrInvalidDirection(d)
+	}
+	return neighbour(minX, minY, maxX, maxY, d, len(hash)), nil
+}
+
+// Neighbours returns the eight geohashes adjacent to hash, indexed by
+// Direction.  Neighbours beyond the poles are the empty string.
+func Neighbours(hash string) ([]string, error) {
+	minX, minY, maxX, maxY, err := decode(hash)
+	if err != nil {
+		return nil, err
+	}
+	neighbours := make([]string, len(offsets))
+	for d := range offsets {
+		neighbours[d] = neighbour(minX, minY, maxX, maxY, Direction(d), len(hash))
+	}
+	return neighbours, nil
+}
+
+// neighbour returns the geohash of the cell adjacent to the cell with the
+// given bounds in direction d.
+func neighbour(minX, minY, maxX, maxY float64, d Direction, precision int) string {
+	offset := offsets[d]
+	y := (minY+maxY)/2 + offset.dy*(maxY-minY)
+	if y < -90 || y > 90 {
+		return ""
+	}
+	x := (minX+maxX)/2 + offset.dx*(maxX-minX)
+	x = math.Mod(x+540, 360) - 180
+	return encode(x, y, precision)
+}
@@ -0,0 +1,221 @@
+package geohash
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestEncodeAndDecode(t *testing.T) {
+	for i, tc := range []struct {
+		coord     geom.Coord
+		precision int
+		want      string
+	}{
+		{coord: geom.Coord{-5.6, 42.6}, precision: 5, want: "ezs42"},
+		{coord: geom.Coord{10.40744, 57.64911}, precision: 11, want: "u4pruydqqvj"},
+		{coord: geom.Coord{-0.1275, 51.507222, 11}, precision: 6, want: "gcpvj0"},
+		{coord: geom.Coord{0, 0}, precision: 1, want: "s"},
+		{coord: geom.Coord{-180, -90}, precision: 12, want: "000000000000"},
+		{coord: geom.Coord{180, 90}, precision: 12, want: "zzzzzzzzzzzz"},
+	} {
+		got, err := Encode(tc.coord, tc.precision)
+		if err != nil || got != tc.want {
+			t.Errorf("%d: Encode(%v, %d) == %q, %v, want %q, <nil>", i, tc.coord, tc.precision, got, err, tc.want)
+		}
+		b, err := Decode(tc.want)
+		if err != nil {
+			t.Errorf("%d: Decode(%q) == ..., %v, want ..., <nil>", i, tc.want, err)
+			continue
+		}
+		if !b.OverlapsPoint(geom.XY, tc.coord[:2]) {
+			t.Errorf("%d: Decode(%q) == %v, %v, want bounds containing %v", i, tc.want, b, err, tc.coord)
+		}
+		center, err := DecodeCenter(tc.want)
+		if err != nil {
+			t.Errorf("%d: DecodeCenter(%q) == ..., %v, want ..., <nil>", i, tc.want, err)
+			continue
+		}
+		if got, err := Encode(center, tc.precision); err != nil || got != tc.want {
+			t.Errorf("%d: Encode(DecodeCenter(%q), %d) == %q, %v, want %q, <nil>", i, tc.want, tc.precision, got, err, tc.want)
+		}
+	}
+}
+
+func TestEncodePoint(t *testing.T) {
+	p := geom.NewPointFlat(geom.XYM, []float64{-5.6, 42.6, 1})
+	if got, err := EncodePoint(p, 5); err != nil || got != "ezs42" {
+		t.Errorf("EncodePoint(%v, 5) == %q, %v, want \"ezs42\", <nil>", p, got, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for i, tc := range []struct {
+		hash string
+		want *geom.Bounds
+	}{
+		{hash: "s", want: geom.NewBounds(geom.XY).Set(0, 0, 45, 45)},
+		{hash: "0", want: geom.NewBounds(geom.XY).Set(-180, -90, -135, -45)},
+		{hash: "sz", want: geom.NewBounds(geom.XY).Set(33.75, 39.375, 45, 45)},
+	} {
+		if got, err := Decode(tc.hash); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Decode(%q) == %v, %v, want %v, <nil>", i, tc.hash, got, err, tc.want)
+		}
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	for i, tc := range []struct {
+		hash string
+		want []string
+	}{
+		{hash: "s", want: []string{"u", "v", "t", "m", "k", "7", "e", "g"}},
+		{hash: "0", want: []string{"2", "3", "1", "", "", "", "p", "r"}},
+		{hash: "z", want: []string{"", "", "b", "8", "x", "w", "y", ""}},
+		{hash: "ezs42", want: []string{"ezs48", "ezs49", "ezs43", "ezs41", "ezs40", "ezefp", "ezefr", "ezefx"}},
+	} {
+		if got, err := Neighbours(tc.hash); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Neighbours(%q) == %q, %v, want %q, <nil>", i, tc.hash, got, err, tc.want)
+		}
+		for d, want := range tc.want {
+			if got, err := Neighbour(tc.hash, Direction(d)); err != nil || got != want {
+				t.Errorf("%d: Neighbour(%q, %d) == %q, %v, want %q, <nil>", i, tc.hash, d, got, err, want)
+			}
+		}
+	}
+}
+
+func TestCover(t *testing.T) {
+	for i, tc := range []struct {
+		g         geom.T
+		precision int
+		want      []string
+	}{
+		{
+			g:         geom.NewPointFlat(geom.XY, []float64{-5.6, 42.6}),
+			precision: 5,
+			want:      []string{"ezs42"},
+		},
+		{
+			g:         geom.NewMultiPointFlat(geom.XY, []float64{10, 10, 1, 1, -1, -1}),
+			precision: 1,
+			want:      []string{"7", "s"},
+		},
+		{
+			g:         geom.NewLineStringFlat(geom.XY, []float64{1, 1, 50, 1}),
+			precision: 1,
+			want:      []string{"s", "t"},
+		},
+		{
+			g:         geom.NewMultiLineStringFlat(geom.XY, []float64{1, 1, 2, 2, -1, -1, -2, -2}, []int{4, 8}),
+			precision: 1,
+			want:      []string{"7", "s"},
+		},
+		{
+			g:         geom.NewPolygonFlat(geom.XY, []float64{1, 1, 89, 1, 89, 44, 1, 44, 1, 1}, []int{10}),
+			precision: 1,
+			want:      []string{"s", "t"},
+		},
+		{
+			g:         geom.NewPolygonFlat(geom.XY, []float64{0, 0, 45, 0, 45, 45, 0, 45, 0, 0}, []int{10}),
+			precision: 1,
+			want:      []string{"7", "e", "g", "k", "m", "s", "t", "u", "v"},
+		},
+		{
+			g:         geom.NewMultiPolygonFlat(geom.XY, []float64{1, 1, 2, 1, 2, 2, 1, 1, -170, -80, -169, -80, -169, -79, -170, -80}, [][]int{{8}, {16}}),
+			precision: 1,
+			want:      []string{"0", "s"},
+		},
+		{
+			g:         geom.NewMultiPoint(geom.XY),
+			precision: 1,
+			want:      nil,
+		},
+		{
+			g:         geom.NewPolygon(geom.XY),
+			precision: 1,
+			want:      nil,
+		},
+	} {
+		if got, err := Cover(tc.g, tc.precision); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Cover(%v, %d) == %q, %v, want %q, <nil>", i, tc.g, tc.precision, got, err, tc.want)
+		}
+	}
+}
+
+func TestCoverPolygonWithHole(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XY, []float64{
+		0.5, 0.5, 44.5, 0.5, 44.5, 44.5, 0.5, 44.5, 0.5, 0.5,
+		10, 10, 10, 35, 35, 35, 35, 10, 10, 10,
+	}, []int{10, 20})
+	got, err := Cover(g, 2)
+	if err != nil {
+		t.Fatalf("Cover(%v, 2) == ..., %v, want ..., <nil>", g, err)
+	}
+	// the hole contains 2 columns and 4 rows of cells
+	if len(got) != 32-8 {
+		t.Errorf("Cover(%v, 2) == %q, want %d geohashes", g, got, 32-8)
+	}
+	covered := make(map[string]bool)
+	for _, hash := range got {
+		covered[hash] = true
+	}
+	for i := 0; i < len(base32); i++ {
+		hash := "s" + base32[i:i+1]
+		b, err := Decode(hash)
+		if err != nil {
+			t.Fatalf("Decode(%q) == ..., %v, want ..., <nil>", hash, err)
+		}
+		inHole := b.Min(0) > 10 && b.Max(0) < 35 && b.Min(1) > 10 && b.Max(1) < 35
+		if covered[hash] == inHole {
+			t.Errorf("Cover(%v, 2) contains %q == %t, want %t", g, hash, covered[hash], !inHole)
+		}
+	}
+}
+
+func TestCoverWorld(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XY, []float64{-180, -90, 180, -90, 180, 90, -180, 90, -180, -90}, []int{10})
+	got, err := Cover(g, 2)
+	if err != nil || len(got) != 32*32 || !sort.StringsAreSorted(got) {
+		t.Errorf("Cover(%v, 2) == %d sorted(%t) geohashes, %v, want %d sorted geohashes, <nil>", g, len(got), sort.StringsAreSorted(got), err, 32*32)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for i, tc := range []struct {
+		coord     geom.Coord
+		precision int
+		want      error
+	}{
+		{coord: geom.Coord{0, 0}, precision: 0, want: ErrInvalidPrecision(0)},
+		{coord: geom.Coord{0, 0}, precision: 13, want: ErrInvalidPrecision(13)},
+		{coord: geom.Coord{181, 0}, precision: 1, want: ErrInvalidCoord{Coord: geom.Coord{181, 0}}},
+		{coord: geom.Coord{0, -91}, precision: 1, want: ErrInvalidCoord{Coord: geom.Coord{0, -91}}},
+		{coord: geom.Coord{0}, precision: 1, want: ErrInvalidCoord{Coord: geom.Coord{0}}},
+	} {
+		if _, err := Encode(tc.coord, tc.precision); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Encode(%v, %d) == ..., %v, want ..., %v", i, tc.coord, tc.precision, err, tc.want)
+		}
+	}
+	if _, err := Encode(geom.Coord{math.NaN(), 0}, 1); err == nil {
+		t.Errorf("Encode(NaN, 1) == ..., <nil>, want ..., non-<nil>")
+	}
+	for _, hash := range []string{"", "a", "ezs4i", strings.Repeat("0", 13)} {
+		if _, err := Decode(hash); err != ErrInvalidGeohash(hash) {
+			t.Errorf("Decode(%q) == ..., %v, want ..., %v", hash, err, ErrInvalidGeohash(hash))
+		}
+		if _, err := Neighbours(hash); err != ErrInvalidGeohash(hash) {
+			t.Errorf("Neighbours(%q) == ..., %v, want ..., %v", hash, err, ErrInvalidGeohash(hash))
+		}
+	}
+	if _, err := Neighbour("s", Direction(8)); err != ErrInvalidDirection(8) {
+		t.Errorf("Neighbour(\"s\", 8) == ..., %v, want ..., %v", err, ErrInvalidDirection(8))
+	}
+	if _, err := Cover(geom.NewPoint(geom.XY), 0); err != ErrInvalidPrecision(0) {
+		t.Errorf("Cover(..., 0) == ..., %v, want ..., %v", err, ErrInvalidPrecision(0))
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 
 	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/antimeridian"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/orientation"
 )
 
 // DefaultLayout is the default layout for empty geometries.
@@ -160,8 +163,51 @@ func (g *Geometry) Decode() (geom.T, error) {
 	}
 }
 
+// An EncodeOption sets an option for Encode and Marshal.
+type EncodeOption func(*encoder)
+
+type encoder struct {
+	antimeridianSplit bool
+	rfc7946Winding    bool
+}
+
+// WithAntimeridianSplit splits geometries that cross the antimeridian, as
+// recommended by RFC 7946, see antimeridian.Split.  LineStrings and Polygons
+// that cross the antimeridian are encoded as MultiLineStrings and
+// MultiPolygons.
+func WithAntimeridianSplit() EncodeOption {
+	return func(e *encoder) {
+		e.antimeridianSplit = true
+	}
+}
+
+// WithRFC7946Winding orients polygons as required by RFC 7946, with
+// counter-clockwise exterior rings and clockwise interior rings.  By default,
+// rings are encoded in their original orientation.
+func WithRFC7946Winding() EncodeOption {
+	return func(e *encoder) {
+		e.rfc7946Winding = true
+	}
+}
+
 // Encode encodes g as a GeoJSON geometry.
-func Encode(g geom.T) (*Geometry, error) {
+func Encode(g geom.T, opts ...EncodeOption) (*Geometry, error) {
+	e := &encoder{}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.antimeridianSplit {
+		var err error
+		if g, err = antimeridian.Split(g); err != nil {
+			return nil, err
+		}
+	}
+	if e.rfc7946Winding {
+		var err error
+		if g, err = xy.Orient(g, orientation.CounterClockwise); err != nil {
+			return nil, err
+		}
+	}
 
 	switch g := g.(type) {
 	case *geom.Point:
@@ -231,8 +277,8 @@ func Encode(g geom.T) (*Geometry, error) {
 }
 
 // Marshal marshals an arbitrary geometry to a []byte.
-func Marshal(g geom.T) ([]byte, error) {
-	geojson, err := Encode(g)
+func Marshal(g geom.T, opts ...EncodeOption) ([]byte, error) {
+	geojson, err := Encode(g, opts...)
 	if err != nil {
 		return nil, err
 	}
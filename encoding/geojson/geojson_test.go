@@ -105,6 +105,68 @@ func TestGeometry(t *testing.T) {
 	}
 }
 
+func TestRFC7946Winding(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		s    string
+		want string
+	}{
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 0, 3, 3, 3, 3, 0, 0, 0, 1, 1, 2, 1, 2, 2, 1, 1}, []int{10, 18}),
+			s:    `{"type":"Polygon","coordinates":[[[0,0],[0,3],[3,3],[3,0],[0,0]],[[1,1],[2,1],[2,2],[1,1]]]}`,
+			want: `{"type":"Polygon","coordinates":[[[0,0],[3,0],[3,3],[0,3],[0,0]],[[1,1],[2,2],[2,1],[1,1]]]}`,
+		},
+		{
+			g:    geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 2, 2, 2, 3, 3, 3, 2, 2}, [][]int{{8}, {16}}),
+			s:    `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[2,2],[2,3],[3,3],[2,2]]]]}`,
+			want: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[2,2],[3,3],[2,3],[2,2]]]]}`,
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0, 1, 1, 1, 0, 0}),
+			s:    `{"type":"LineString","coordinates":[[0,0],[0,1],[1,1],[0,0]]}`,
+			want: `{"type":"LineString","coordinates":[[0,0],[0,1],[1,1],[0,0]]}`,
+		},
+	} {
+		if got, err := Marshal(tc.g); err != nil || string(got) != tc.s {
+			t.Errorf("Marshal(%#v) == %s, %v, want %s, <nil>", tc.g, got, err, tc.s)
+		}
+		if got, err := Marshal(tc.g, WithRFC7946Winding()); err != nil || string(got) != tc.want {
+			t.Errorf("Marshal(%#v, WithRFC7946Winding()) == %s, %v, want %s, <nil>", tc.g, got, err, tc.want)
+		}
+	}
+}
+
+func TestAntimeridianSplit(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		s    string
+		want string
+	}{
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{170, 0, -170, 10}),
+			s:    `{"type":"LineString","coordinates":[[170,0],[-170,10]]}`,
+			want: `{"type":"MultiLineString","coordinates":[[[170,0],[180,5]],[[-180,5],[-170,10]]]}`,
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{170, -10, -170, -10, -170, 10, 170, 10, 170, -10}, []int{10}),
+			s:    `{"type":"Polygon","coordinates":[[[170,-10],[-170,-10],[-170,10],[170,10],[170,-10]]]}`,
+			want: `{"type":"MultiPolygon","coordinates":[[[[180,10],[170,10],[170,-10],[180,-10],[180,10]]],[[[-180,-10],[-170,-10],[-170,10],[-180,10],[-180,-10]]]]}`,
+		},
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{190, 0}),
+			s:    `{"type":"Point","coordinates":[190,0]}`,
+			want: `{"type":"Point","coordinates":[-170,0]}`,
+		},
+	} {
+		if got, err := Marshal(tc.g); err != nil || string(got) != tc.s {
+			t.Errorf("Marshal(%#v) == %s, %v, want %s, <nil>", tc.g, got, err, tc.s)
+		}
+		if got, err := Marshal(tc.g, WithAntimeridianSplit()); err != nil || string(got) != tc.want {
+			t.Errorf("Marshal(%#v, WithAntimeridianSplit()) == %s, %v, want %s, <nil>", tc.g, got, err, tc.want)
+		}
+	}
+}
+
 func TestFeature(t *testing.T) {
 	for _, tc := range []struct {
 		f *Feature
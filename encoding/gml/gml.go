@@ -0,0 +1,517 @@
+// Package gml implements GML 3.2 geometry encoding and decoding.
+//
+// Geometries are encoded as follows:
+//
+//	*geom.Point            gml:Point
+//	*geom.LineString       gml:LineString
+//	*geom.LinearRing       gml:LinearRing
+//	*geom.Polygon          gml:Polygon
+//	*geom.MultiPoint       gml:MultiPoint
+//	*geom.MultiLineString  gml:MultiCurve
+//	*geom.MultiPolygon     gml:MultiSurface
+//
+// Decoding also accepts the deprecated gml:MultiLineString and
+// gml:MultiPolygon elements, and gml:MultiGeometry elements whose members are
+// all Points, all LineStrings, or all Polygons, which are decoded as
+// MultiPoints, MultiLineStrings, and MultiPolygons respectively.
+//
+// The srsName attribute is mapped to the SRID for EPSG coordinate reference
+// systems, and the srsDimension attribute to the layout: 2 is geom.XY, 3 is
+// geom.XYZ, and 4 is geom.XYZM.  GML has no concept of measures, so geom.XYM
+// geometries cannot be encoded.  Coordinates are encoded and decoded in the
+// order in which they are stored, whatever the axis order of the coordinate
+// reference system.
+//
+// See https://www.ogc.org/standards/gml.
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/geomutil"
+)
+
+// Namespace is the GML 3.2 namespace.
+const Namespace = "http://www.opengis.net/gml/3.2"
+
+var srsNameRegexp = regexp.MustCompile(`(?i)^(?:EPSG:|urn:ogc:def:crs:EPSG:[^:]*:|urn:x-ogc:def:crs:EPSG:[^:]*:|https?://www\.opengis\.net/def/crs/EPSG/[^/]*/|https?://www\.opengis\.net/gml/srs/epsg\.xml#)(\d+)$`)
+
+// An ErrUnsupportedElement is returned when an element cannot be decoded.
+type ErrUnsupportedElement string
+
+func (e ErrUnsupportedElement) Error() string {
+	return fmt.Sprintf("gml: unsupported element %s", string(e))
+}
+
+// An ErrUnsupportedSRSDimension is returned when an srsDimension is not
+// supported.
+type ErrUnsupportedSRSDimension int
+
+func (e ErrUnsupportedSRSDimension) Error() string {
+	return fmt.Sprintf("gml: unsupported srsDimension %d", int(e))
+}
+
+// An ErrInvalidCoordinates is returned when coordinates cannot be decoded.
+type ErrInvalidCoordinates string
+
+func (e ErrInvalidCoordinates) Error() string {
+	return fmt.Sprintf("gml: invalid coordinates %q", string(e))
+}
+
+// An ErrMixedMultiGeometry is returned when a gml:MultiGeometry is empty or
+// its members are not all Points, all LineStrings, or all Polygons.
+type ErrMixedMultiGeometry struct{}
+
+func (e ErrMixedMultiGeometry) Error() string {
+	return "gml: MultiGeometry members must all be Points, LineStrings, or Polygons"
+}
+
+// An Encoder encodes geometries as GML.
+type Encoder struct {
+	// IDPrefix, if not empty, is used to generate the gml:id attributes
+	// required by the GML 3.2 schema.  The ids are IDPrefix followed by a dot
+	// and a sequence number.
+	IDPrefix string
+	// SRSNameFormat is the format used to generate srsName attributes from
+	// SRIDs.  It must contain a single %d verb.  If it is empty then
+	// "urn:ogc:def:crs:EPSG::%d" is used.
+	SRSNameFormat string
+	id            int
+}
+
+// Marshal encodes g as GML using the default Encoder.
+func Marshal(g geom.T) ([]byte, error) {
+	return (&Encoder{}).Marshal(g)
+}
+
+// Marshal encodes g as GML.  The root element declares the GML namespace and
+// has srsName and srsDimension attributes.  srsName is omitted if g's SRID is
+// zero.
+func (e *Encoder) Marshal(g geom.T) ([]byte, error) {
+	var srsDimension int
+	switch g.Layout() {
+	case geom.XY, geom.XYZ, geom.XYZM:
+		srsDimension = g.Stride()
+	default:
+		return nil, geom.ErrUnsupportedLayout(g.Layout())
+	}
+	var attrs []xml.Attr
+	attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:gml"}, Value: Namespace})
+	if srid := g.SRID(); srid != 0 {
+		format := e.SRSNameFormat
+		if format == "" {
+			format = "urn:ogc:def:crs:EPSG::%d"
+		}
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "srsName"}, Value: fmt.Sprintf(format, srid)})
+	}
+	attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "srsDimension"}, Value: strconv.Itoa(srsDimension)})
+	b := &bytes.Buffer{}
+	if err := e.encode(b, g, attrs); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// encode encodes g with the extra attributes attrs.
+func (e *Encoder) encode(b *bytes.Buffer, g geom.T, attrs []xml.Attr) error {
+	stride := g.Stride()
+	switch g := g.(type) {
+	case *geom.Point:
+		e.start(b, "Point", attrs)
+		e.coords(b, "pos", g.FlatCoords(), stride)
+		e.end(b, "Point")
+	case *geom.LineString:
+		e.start(b, "LineString", attrs)
+		e.coords(b, "posList", g.FlatCoords(), stride)
+		e.end(b, "LineString")
+	case *geom.LinearRing:
+		e.start(b, "LinearRing", attrs)
+		e.coords(b, "posList", g.FlatCoords(), stride)
+		e.end(b, "LinearRing")
+	case *geom.Polygon:
+		e.start(b, "Polygon", attrs)
+		for i := 0; i < g.NumLinearRings(); i++ {
+			name := "interior"
+			if i == 0 {
+				name = "exterior"
+			}
+			e.start(b, name, nil)
+			e.start(b, "LinearRing", nil)
+			e.coords(b, "posList", g.LinearRing(i).FlatCoords(), stride)
+			e.end(b, "LinearRing")
+			e.end(b, name)
+		}
+		e.end(b, "Polygon")
+	case *geom.MultiPoint:
+		e.start(b, "MultiPoint", attrs)
+		for i := 0; i < g.NumPoints(); i++ {
+			if err := e.member(b, "pointMember", g.Point(i)); err != nil {
+				return err
+			}
+		}
+		e.end(b, "MultiPoint")
+	case *geom.MultiLineString:
+		e.start(b, "MultiCurve", attrs)
+		for i := 0; i < g.NumLineStrings(); i++ {
+			if err := e.member(b, "curveMember", g.LineString(i)); err != nil {
+				return err
+			}
+		}
+		e.end(b, "MultiCurve")
+	case *geom.MultiPolygon:
+		e.start(b, "MultiSurface", attrs)
+		for i := 0; i < g.NumPolygons(); i++ {
+			if err := e.member(b, "surfaceMember", g.Polygon(i)); err != nil {
+				return err
+			}
+		}
+		e.end(b, "MultiSurface")
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	return nil
+}
+
+// member encodes g as a member of a multi-geometry.
+func (e *Encoder) member(b *bytes.Buffer, name string, g geom.T) error {
+	e.start(b, name, nil)
+	if err := e.encode(b, g, nil); err != nil {
+		return err
+	}
+	e.end(b, name)
+	return nil
+}
+
+// start writes a start element.  Geometry elements, whose names start with
+// an upper case letter, are given a gml:id if e.IDPrefix is set.
+func (e *Encoder) start(b *bytes.Buffer, name string, attrs []xml.Attr) {
+	b.WriteString("<gml:")
+	b.WriteString(name)
+	if e.IDPrefix != "" && 'A' <= name[0] && name[0] <= 'Z' {
+		e.id++
+		attrs = append([]xml.Attr{{Name: xml.Name{Local: "gml:id"}, Value: e.IDPrefix + "." + strconv.Itoa(e.id)}}, attrs...)
+	}
+	for _, attr := range attrs {
+		b.WriteByte(' ')
+		b.WriteString(attr.Name.Local)
+		b.WriteString(`="`)
+		_ = xml.EscapeText(b, []byte(attr.Value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('>')
+}
+
+func (e *Encoder) end(b *bytes.Buffer, name string) {
+	b.WriteString("</gml:")
+	b.WriteString(name)
+	b.WriteByte('>')
+}
+
+// coords writes the element name containing flatCoords.
+func (e *Encoder) coords(b *bytes.Buffer, name string, flatCoords []float64, stride int) {
+	e.start(b, name, nil)
+	for i, x := range flatCoords {
+		if i != 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(formatFloat(x))
+	}
+	e.end(b, name)
+}
+
+// formatFloat formats x using exponential notation only for very large and
+// very small values.
+func formatFloat(x float64) string {
+	if abs := math.Abs(x); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// An element is a generic XML element.
+type element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []element  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+// attr returns the value of the attribute with local name name.
+func (e *element) attr(name string) (string, bool) {
+	for _, attr := range e.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// A context is the inherited state when decoding.
+type context struct {
+	srsDimension int
+}
+
+// update returns the context for e.
+func (c context) update(e *element) (context, error) {
+	if s, ok := e.attr("srsDimension"); ok {
+		srsDimension, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || srsDimension < 2 || srsDimension > 4 {
+			return c, ErrUnsupportedSRSDimension(srsDimension)
+		}
+		c.srsDimension = srsDimension
+	}
+	return c, nil
+}
+
+// Unmarshal decodes a GML geometry.  Elements are matched by local name, so
+// GML 3.1 is also accepted.  The SRID of the returned geometry is set from
+// the srsName attribute of the root element if it identifies an EPSG
+// coordinate reference system, and is zero otherwise.
+func Unmarshal(data []byte) (geom.T, error) {
+	var e element
+	if err := xml.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	g, err := decode(&e, context{})
+	if err != nil {
+		return nil, err
+	}
+	srid := 0
+	if srsName, ok := e.attr("srsName"); ok {
+		if m := srsNameRegexp.FindStringSubmatch(strings.TrimSpace(srsName)); m != nil {
+			srid, _ = strconv.Atoi(m[1])
+		}
+	}
+	return geomutil.SetSRID(g, srid)
+}
+
+// decode decodes the geometry element e.
+func decode(e *element, c context) (geom.T, error) {
+	c, err := c.update(e)
+	if err != nil {
+		return nil, err
+	}
+	switch e.XMLName.Local {
+	case "Point":
+		layout, flatCoords, err := decodeCoords(e, c)
+		if err != nil {
+			return nil, err
+		}
+		if len(flatCoords) > layout.Stride() {
+			return nil, ErrInvalidCoordinates(e.Text)
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case "LineString":
+		layout, flatCoords, err := decodeCoords(e, c)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case "LinearRing":
+		layout, flatCoords, err := decodeCoords(e, c)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLinearRingFlat(layout, flatCoords), nil
+	case "Polygon":
+		return decodePolygon(e, c)
+	case "MultiPoint", "MultiCurve", "MultiLineString", "MultiSurface", "MultiPolygon", "MultiGeometry":
+		gs, err := decodeMembers(e, c)
+		if err != nil {
+			return nil, err
+		}
+		return combine(e.XMLName.Local, gs, layoutOf(c))
+	default:
+		return nil, ErrUnsupportedElement(e.XMLName.Local)
+	}
+}
+
+// decodePolygon decodes a Polygon element.
+func decodePolygon(e *element, c context) (*geom.Polygon, error) {
+	p := geom.NewPolygon(layoutOf(c))
+	for i := range e.Children {
+		child := &e.Children[i]
+		switch child.XMLName.Local {
+		case "exterior", "interior", "outerBoundaryIs", "innerBoundaryIs":
+		default:
+			return nil, ErrUnsupportedElement(child.XMLName.Local)
+		}
+		if len(child.Children) != 1 || child.Children[0].XMLName.Local != "LinearRing" {
+			return nil, ErrUnsupportedElement(child.XMLName.Local)
+		}
+		g, err := decode(&child.Children[0], c)
+		if err != nil {
+			return nil, err
+		}
+		if p.NumLinearRings() == 0 && p.Layout() != g.Layout() {
+			p = geom.NewPolygon(g.Layout())
+		}
+		if err := p.Push(g.(*geom.LinearRing)); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// decodeMembers returns the member geometries of the multi-geometry e.
+// Members may be in individual member elements, e.g. gml:pointMember, or in a
+// single members element, e.g. gml:pointMembers.
+func decodeMembers(e *element, c context) ([]geom.T, error) {
+	var gs []geom.T
+	for i := range e.Children {
+		child := &e.Children[i]
+		if !strings.HasSuffix(child.XMLName.Local, "Member") && !strings.HasSuffix(child.XMLName.Local, "Members") {
+			return nil, ErrUnsupportedElement(child.XMLName.Local)
+		}
+		for j := range child.Children {
+			g, err := decode(&child.Children[j], c)
+			if err != nil {
+				return nil, err
+			}
+			gs = append(gs, g)
+		}
+	}
+	return gs, nil
+}
+
+// combine returns the multi-geometry element name containing gs.  layout is
+// the layout used if gs is empty.
+func combine(name string, gs []geom.T, layout geom.Layout) (geom.T, error) {
+	if len(gs) != 0 {
+		layout = gs[0].Layout()
+	}
+	// invalid is the error returned for members of the wrong type
+	var invalid error = ErrUnsupportedElement(name)
+	if name == "MultiGeometry" {
+		invalid = ErrMixedMultiGeometry{}
+		if len(gs) == 0 {
+			return nil, invalid
+		}
+		switch gs[0].(type) {
+		case *geom.Point:
+			name = "MultiPoint"
+		case *geom.LineString:
+			name = "MultiLineString"
+		case *geom.Polygon:
+			name = "MultiPolygon"
+		default:
+			return nil, invalid
+		}
+	}
+	switch name {
+	case "MultiPoint":
+		mp := geom.NewMultiPoint(layout)
+		for _, g := range gs {
+			p, ok := g.(*geom.Point)
+			if !ok {
+				return nil, invalid
+			}
+			if err := mp.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	case "MultiCurve", "MultiLineString":
+		mls := geom.NewMultiLineString(layout)
+		for _, g := range gs {
+			ls, ok := g.(*geom.LineString)
+			if !ok {
+				return nil, invalid
+			}
+			if err := mls.Push(ls); err != nil {
+				return nil, err
+			}
+		}
+		return mls, nil
+	default:
+		mp := geom.NewMultiPolygon(layout)
+		for _, g := range gs {
+			p, ok := g.(*geom.Polygon)
+			if !ok {
+				return nil, invalid
+			}
+			if err := mp.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	}
+}
+
+// decodeCoords decodes the coordinates of e, which are either in a single
+// gml:posList element or in a sequence of gml:pos elements.
+func decodeCoords(e *element, c context) (geom.Layout, []float64, error) {
+	var flatCoords []float64
+	layout := layoutOf(c)
+	for i := range e.Children {
+		child := &e.Children[i]
+		cc, err := c.update(child)
+		if err != nil {
+			return geom.NoLayout, nil, err
+		}
+		values, err := parseFloats(child.Text)
+		if err != nil {
+			return geom.NoLayout, nil, err
+		}
+		switch child.XMLName.Local {
+		case "pos":
+			// the dimension of a pos is its number of values, unless it is
+			// empty
+			if cc.srsDimension == 0 && len(values) != 0 {
+				if len(values) < 2 || len(values) > 4 {
+					return geom.NoLayout, nil, ErrInvalidCoordinates(child.Text)
+				}
+				cc.srsDimension = len(values)
+			}
+			if len(values) != 0 && len(values) != layoutOf(cc).Stride() {
+				return geom.NoLayout, nil, ErrInvalidCoordinates(child.Text)
+			}
+		case "posList":
+			if len(values)%layoutOf(cc).Stride() != 0 {
+				return geom.NoLayout, nil, ErrInvalidCoordinates(child.Text)
+			}
+		default:
+			return geom.NoLayout, nil, ErrUnsupportedElement(child.XMLName.Local)
+		}
+		if i == 0 {
+			layout = layoutOf(cc)
+		} else if layoutOf(cc) != layout {
+			return geom.NoLayout, nil, geom.ErrLayoutMismatch{Got: layoutOf(cc), Want: layout}
+		}
+		flatCoords = append(flatCoords, values...)
+	}
+	return layout, flatCoords, nil
+}
+
+// parseFloats parses the whitespace-separated floats in s.
+func parseFloats(s string) ([]float64, error) {
+	fields := strings.Fields(s)
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		x, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, ErrInvalidCoordinates(s)
+		}
+		values[i] = x
+	}
+	return values, nil
+}
+
+// layoutOf returns the layout for c's srsDimension.
+func layoutOf(c context) geom.Layout {
+	switch c.srsDimension {
+	case 3:
+		return geom.XYZ
+	case 4:
+		return geom.XYZM
+	default:
+		return geom.XY
+	}
+}
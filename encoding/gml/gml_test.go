@@ -0,0 +1,194 @@
+package gml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want string
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			want: `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326" srsDimension="2"><gml:pos>1 2</gml:pos></gml:Point>`,
+		},
+		{
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1.5, -2.25, 3e-7, 1e21}),
+			want: `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="4"><gml:pos>1.5 -2.25 3e-07 1e+21</gml:pos></gml:Point>`,
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			want: `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="3"><gml:posList>1 2 3 4 5 6</gml:posList></gml:LineString>`,
+		},
+		{
+			g:    geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+			want: `<gml:LinearRing xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="2"><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing>`,
+		},
+		{
+			g: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 3, 0, 3, 3, 0, 0, 1, 1, 2, 1, 2, 2, 1, 1}, []int{8, 16}).SetSRID(3857),
+			want: `<gml:Polygon xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::3857" srsDimension="2">` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 3 0 3 3 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`<gml:interior><gml:LinearRing><gml:posList>1 1 2 1 2 2 1 1</gml:posList></gml:LinearRing></gml:interior>` +
+				`</gml:Polygon>`,
+		},
+		{
+			g: geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+			want: `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="2">` +
+				`<gml:pointMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>` +
+				`<gml:pointMember><gml:Point><gml:pos>3 4</gml:pos></gml:Point></gml:pointMember>` +
+				`</gml:MultiPoint>`,
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{4, 8}),
+			want: `<gml:MultiCurve xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="2">` +
+				`<gml:curveMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:curveMember>` +
+				`<gml:curveMember><gml:LineString><gml:posList>5 6 7 8</gml:posList></gml:LineString></gml:curveMember>` +
+				`</gml:MultiCurve>`,
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XYZ, []float64{0, 0, 1, 1, 0, 1, 1, 1, 1, 0, 0, 1}, [][]int{{12}}),
+			want: `<gml:MultiSurface xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="3">` +
+				`<gml:surfaceMember><gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>0 0 1 1 0 1 1 1 1 0 0 1</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon></gml:surfaceMember>` +
+				`</gml:MultiSurface>`,
+		},
+		{
+			g:    geom.NewMultiPoint(geom.XY),
+			want: `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="2"></gml:MultiPoint>`,
+		},
+	} {
+		got, err := Marshal(tc.g)
+		if err != nil || string(got) != tc.want {
+			t.Errorf("%d: Marshal(%#v) == %s, %v, want %s, <nil>", i, tc.g, got, err, tc.want)
+		}
+		if g, err := Unmarshal([]byte(tc.want)); err != nil || !reflect.DeepEqual(g, tc.g) {
+			t.Errorf("%d: Unmarshal(%s) == %#v, %v, want %#v, <nil>", i, tc.want, g, err, tc.g)
+		}
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	e := &Encoder{
+		IDPrefix:      "g",
+		SRSNameFormat: "http://www.opengis.net/def/crs/EPSG/0/%d",
+	}
+	g := geom.NewMultiPointFlat(geom.XY, []float64{1, 2}).SetSRID(27700)
+	want := `<gml:MultiPoint gml:id="g.1" xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/EPSG/0/27700" srsDimension="2">` +
+		`<gml:pointMember><gml:Point gml:id="g.2"><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>` +
+		`</gml:MultiPoint>`
+	if got, err := e.Marshal(g); err != nil || string(got) != want {
+		t.Errorf("Marshal(%#v) == %s, %v, want %s, <nil>", g, got, err, want)
+	}
+	if got, err := Unmarshal([]byte(want)); err != nil || !reflect.DeepEqual(got, g) {
+		t.Errorf("Unmarshal(%s) == %#v, %v, want %#v, <nil>", want, got, err, g)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	for i, tc := range []struct {
+		s    string
+		want geom.T
+	}{
+		{
+			s:    `<Point xmlns="http://www.opengis.net/gml/3.2" srsName="EPSG:4326"><pos>1 2 3</pos></Point>`,
+			want: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}).SetSRID(4326),
+		},
+		{
+			s:    `<gml:Point xmlns:gml="http://www.opengis.net/gml" srsName="http://www.opengis.net/gml/srs/epsg.xml#4326"><gml:pos srsDimension="2">1 2</gml:pos></gml:Point>`,
+			want: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+		},
+		{
+			s:    `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:OGC:1.3:CRS84"><gml:pos>1 2</gml:pos></gml:Point>`,
+			want: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			s: `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:pos>1 2</gml:pos>
+				<gml:pos>3 4</gml:pos>
+			</gml:LineString>`,
+			want: geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+		},
+		{
+			s: `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:pointMembers>
+					<gml:Point><gml:pos>1 2 3</gml:pos></gml:Point>
+					<gml:Point><gml:pos>4 5 6</gml:pos></gml:Point>
+				</gml:pointMembers>
+			</gml:MultiPoint>`,
+			want: geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		},
+		{
+			s: `<gml:MultiGeometry xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326" srsDimension="2">
+				<gml:geometryMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:geometryMember>
+				<gml:geometryMember><gml:LineString><gml:posList>5 6 7 8</gml:posList></gml:LineString></gml:geometryMember>
+			</gml:MultiGeometry>`,
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{4, 8}).SetSRID(4326),
+		},
+		{
+			s: `<gml:MultiPolygon xmlns:gml="http://www.opengis.net/gml">
+				<gml:polygonMember><gml:Polygon><gml:outerBoundaryIs><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:outerBoundaryIs></gml:Polygon></gml:polygonMember>
+			</gml:MultiPolygon>`,
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		},
+	} {
+		if got, err := Unmarshal([]byte(tc.s)); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Unmarshal(%s) == %#v, %v, want %#v, <nil>", i, tc.s, got, err, tc.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Marshal(geom.NewPointFlat(geom.XYM, []float64{1, 2, 3})); err != geom.ErrUnsupportedLayout(geom.XYM) {
+		t.Errorf("Marshal(XYM point) == ..., %v, want %v", err, geom.ErrUnsupportedLayout(geom.XYM))
+	}
+	for i, tc := range []struct {
+		s    string
+		want error
+	}{
+		{
+			s:    `<gml:Curve xmlns:gml="http://www.opengis.net/gml/3.2"/>`,
+			want: ErrUnsupportedElement("Curve"),
+		},
+		{
+			s:    `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="5"><gml:pos>1 2 3 4 5</gml:pos></gml:Point>`,
+			want: ErrUnsupportedSRSDimension(5),
+		},
+		{
+			s:    `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2" srsDimension="3"><gml:posList>1 2 3 4</gml:posList></gml:LineString>`,
+			want: ErrInvalidCoordinates("1 2 3 4"),
+		},
+		{
+			s:    `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 x</gml:pos></gml:Point>`,
+			want: ErrInvalidCoordinates("1 x"),
+		},
+		{
+			s: `<gml:MultiGeometry xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:geometryMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:geometryMember>
+				<gml:geometryMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:geometryMember>
+			</gml:MultiGeometry>`,
+			want: ErrMixedMultiGeometry{},
+		},
+		{
+			s:    `<gml:MultiGeometry xmlns:gml="http://www.opengis.net/gml/3.2"/>`,
+			want: ErrMixedMultiGeometry{},
+		},
+		{
+			s:    `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pointMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:pointMember></gml:MultiPoint>`,
+			want: ErrUnsupportedElement("MultiPoint"),
+		},
+		{
+			s: `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:pointMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>
+				<gml:pointMember><gml:Point><gml:pos>1 2 3</gml:pos></gml:Point></gml:pointMember>
+			</gml:MultiPoint>`,
+			want: geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+	} {
+		if _, err := Unmarshal([]byte(tc.s)); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Unmarshal(%s) == ..., %v, want %v", i, tc.s, err, tc.want)
+		}
+	}
+}
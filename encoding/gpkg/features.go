@@ -0,0 +1,400 @@
+package gpkg
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+)
+
+// rtreeExtension is the name of the GeoPackage R-tree spatial index extension.
+const rtreeExtension = "gpkg_rtree_index"
+
+// A DB is a *sql.DB or *sql.Tx connected to a SQLite GeoPackage file.  Any
+// database/sql SQLite driver may be used.
+type DB interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// A Content is a row of the gpkg_contents table.
+type Content struct {
+	TableName   string
+	DataType    string
+	Identifier  string
+	Description string
+	// Bounds is nil if the extent is not set.
+	Bounds *geom.Bounds
+	SRID   int
+}
+
+// A Column is an attribute column of a feature table.
+type Column struct {
+	Name string
+	// Type is a GeoPackage data type, e.g. "INTEGER", "REAL", or "TEXT".
+	Type string
+}
+
+// A FeatureTable describes a feature table.
+type FeatureTable struct {
+	Name string
+	// GeometryColumn is the name of the geometry column.
+	GeometryColumn string
+	// GeometryType is a GeoPackage geometry type name, e.g. "POINT" or
+	// "GEOMETRY".
+	GeometryType string
+	SRID         int
+	Layout       geom.Layout
+	Columns      []Column
+	// SpatialIndex is true if the table has an R-tree spatial index.
+	SpatialIndex bool
+}
+
+// A Feature is a row of a feature table.
+type Feature struct {
+	ID int64
+	// Geometry is nil if the geometry is NULL.
+	Geometry   geom.T
+	Properties map[string]interface{}
+}
+
+// Init creates the gpkg_spatial_ref_sys, gpkg_contents,
+// gpkg_geometry_columns, and gpkg_extensions tables if they do not exist, and
+// adds the spatial reference systems required by the GeoPackage
+// specification.
+func Init(db DB) error {
+	for _, query := range []string{
+		`PRAGMA application_id = 1196444487`,
+		`PRAGMA user_version = 10200`,
+		`CREATE TABLE IF NOT EXISTS gpkg_spatial_ref_sys (srs_name TEXT NOT NULL, srs_id INTEGER NOT NULL PRIMARY KEY, organization TEXT NOT NULL, organization_coordsys_id INTEGER NOT NULL, definition TEXT NOT NULL, description TEXT)`,
+		`CREATE TABLE IF NOT EXISTS gpkg_contents (table_name TEXT NOT NULL PRIMARY KEY, data_type TEXT NOT NULL, identifier TEXT UNIQUE, description TEXT DEFAULT '', last_change DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')), min_x DOUBLE, min_y DOUBLE, max_x DOUBLE, max_y DOUBLE, srs_id INTEGER, CONSTRAINT fk_gc_r_srs_id FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id))`,
+		`CREATE TABLE IF NOT EXISTS gpkg_geometry_columns (table_name TEXT NOT NULL, column_name TEXT NOT NULL, geometry_type_name TEXT NOT NULL, srs_id INTEGER NOT NULL, z TINYINT NOT NULL, m TINYINT NOT NULL, CONSTRAINT pk_geom_cols PRIMARY KEY (table_name, column_name), CONSTRAINT fk_gc_tn FOREIGN KEY (table_name) REFERENCES gpkg_contents(table_name), CONSTRAINT fk_gc_srs FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys (srs_id))`,
+		`CREATE TABLE IF NOT EXISTS gpkg_extensions (table_name TEXT, column_name TEXT, extension_name TEXT NOT NULL, definition TEXT NOT NULL, scope TEXT NOT NULL, CONSTRAINT ge_tce UNIQUE (table_name, column_name, extension_name))`,
+		`INSERT OR IGNORE INTO gpkg_spatial_ref_sys (srs_name, srs_id, organization, organization_coordsys_id, definition, description) VALUES ('Undefined cartesian SRS', -1, 'NONE', -1, 'undefined', 'undefined cartesian coordinate reference system'), ('Undefined geographic SRS', 0, 'NONE', 0, 'undefined', 'undefined geographic coordinate reference system'), ('WGS 84 geodetic', 4326, 'EPSG', 4326, 'GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9122"]],AUTHORITY["EPSG","4326"]]', 'longitude/latitude coordinates in decimal degrees on the WGS 84 spheroid')`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Contents returns the rows of the gpkg_contents table, ordered by table
+// name.
+func Contents(db DB) ([]*Content, error) {
+	rows, err := db.Query(`SELECT table_name, data_type, identifier, description, min_x, min_y, max_x, max_y, srs_id FROM gpkg_contents ORDER BY table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var contents []*Content
+	for rows.Next() {
+		var c Content
+		var identifier, description sql.NullString
+		var minX, minY, maxX, maxY sql.NullFloat64
+		var srid sql.NullInt64
+		if err := rows.Scan(&c.TableName, &c.DataType, &identifier, &description, &minX, &minY, &maxX, &maxY, &srid); err != nil {
+			return nil, err
+		}
+		c.Identifier = identifier.String
+		c.Description = description.String
+		if minX.Valid && minY.Valid && maxX.Valid && maxY.Valid {
+			c.Bounds = geom.NewBounds(geom.XY).Set(minX.Float64, minY.Float64, maxX.Float64, maxY.Float64)
+		}
+		c.SRID = int(srid.Int64)
+		contents = append(contents, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// ReadFeatureTable returns the description of the feature table name.  The
+// columns do not include the primary key or the geometry column.
+func ReadFeatureTable(db DB, name string) (*FeatureTable, error) {
+	t, _, err := readFeatureTable(db, name)
+	return t, err
+}
+
+// readFeatureTable returns the description of the feature table name and the
+// name of its primary key column.
+func readFeatureTable(db DB, name string) (*FeatureTable, string, error) {
+	t := &FeatureTable{Name: name}
+	var z, m int
+	if err := queryRow(db, []interface{}{&t.GeometryColumn, &t.GeometryType, &t.SRID, &z, &m}, `SELECT column_name, geometry_type_name, srs_id, z, m FROM gpkg_geometry_columns WHERE table_name = ?`, name); err != nil {
+		return nil, "", err
+	}
+	switch {
+	case z == 1 && m == 1:
+		t.Layout = geom.XYZM
+	case z == 1:
+		t.Layout = geom.XYZ
+	case m == 1:
+		t.Layout = geom.XYM
+	default:
+		t.Layout = geom.XY
+	}
+
+	var n int
+	if err := queryRow(db, []interface{}{&n}, `SELECT COUNT(*) FROM gpkg_extensions WHERE table_name = ? AND column_name = ? AND extension_name = ?`, name, t.GeometryColumn, rtreeExtension); err != nil {
+		return nil, "", err
+	}
+	t.SpatialIndex = n > 0
+
+	columns, pk, err := tableInfo(db, name)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, c := range columns {
+		if c.Name != pk && c.Name != t.GeometryColumn {
+			t.Columns = append(t.Columns, c)
+		}
+	}
+	return t, pk, nil
+}
+
+// ReadFeatures returns the features in the feature table name.  If bounds is
+// not nil then only features whose geometries' bounds overlap bounds in XY
+// are returned, and the table's R-tree spatial index is used if it exists.
+func ReadFeatures(db DB, name string, bounds *geom.Bounds) ([]*Feature, error) {
+	t, pk, err := readFeatureTable(db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT * FROM ` + quoteIdentifier(name)
+	var args []interface{}
+	if bounds != nil && t.SpatialIndex {
+		query += ` WHERE ` + quoteIdentifier(pk) + ` IN (SELECT id FROM ` + quoteIdentifier(rtreeName(t)) + ` WHERE minx <= ? AND maxx >= ? AND miny <= ? AND maxy >= ?)`
+		args = append(args, bounds.Max(0), bounds.Min(0), bounds.Max(1), bounds.Min(1))
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var features []*Feature
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range dest {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		f := &Feature{
+			Properties: make(map[string]interface{}),
+		}
+		for i, column := range columns {
+			// some drivers return BLOBs as strings
+			if value, ok := values[i].(string); ok && column == t.GeometryColumn {
+				values[i] = []byte(value)
+			}
+			switch column {
+			case pk:
+				id, ok := values[i].(int64)
+				if !ok {
+					return nil, fmt.Errorf("gpkg: %s: invalid primary key %v", name, values[i])
+				}
+				f.ID = id
+			case t.GeometryColumn:
+				if values[i] == nil {
+					continue
+				}
+				data, ok := values[i].([]byte)
+				if !ok {
+					return nil, fmt.Errorf("gpkg: %s: invalid geometry %v", name, values[i])
+				}
+				if f.Geometry, err = Unmarshal(data); err != nil {
+					return nil, err
+				}
+			default:
+				f.Properties[column] = values[i]
+			}
+		}
+		// the R-tree stores single precision bounds, so check the exact bounds
+		if bounds != nil && (f.Geometry == nil || !f.Geometry.Bounds().Overlaps(geom.XY, bounds)) {
+			continue
+		}
+		features = append(features, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// CreateFeatureTable creates the feature table t and registers it in the
+// gpkg_contents and gpkg_geometry_columns tables, which must already exist,
+// for example by calling Init.  The table has an INTEGER PRIMARY KEY column
+// named fid.  If t.SpatialIndex is true then an R-tree spatial index is also
+// created.  The spatial index is maintained by WriteFeatures rather than by
+// triggers, as the triggers defined by the GeoPackage specification require
+// spatial SQL functions that plain SQLite does not provide.
+func CreateFeatureTable(db DB, t *FeatureTable) error {
+	columns := []string{
+		`fid INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL`,
+		quoteIdentifier(t.GeometryColumn) + ` ` + t.GeometryType,
+	}
+	for _, c := range t.Columns {
+		columns = append(columns, quoteIdentifier(c.Name)+` `+c.Type)
+	}
+	if _, err := db.Exec(`CREATE TABLE ` + quoteIdentifier(t.Name) + ` (` + strings.Join(columns, `, `) + `)`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`INSERT INTO gpkg_contents (table_name, data_type, identifier, srs_id) VALUES (?, 'features', ?, ?)`, t.Name, t.Name, t.SRID); err != nil {
+		return err
+	}
+	var z, m int
+	switch t.Layout {
+	case geom.XY:
+	case geom.XYZ:
+		z = 1
+	case geom.XYM:
+		m = 1
+	case geom.XYZM:
+		z, m = 1, 1
+	default:
+		return geom.ErrUnsupportedLayout(t.Layout)
+	}
+	if _, err := db.Exec(`INSERT INTO gpkg_geometry_columns (table_name, column_name, geometry_type_name, srs_id, z, m) VALUES (?, ?, ?, ?, ?, ?)`, t.Name, t.GeometryColumn, t.GeometryType, t.SRID, z, m); err != nil {
+		return err
+	}
+	if !t.SpatialIndex {
+		return nil
+	}
+	if _, err := db.Exec(`CREATE VIRTUAL TABLE ` + quoteIdentifier(rtreeName(t)) + ` USING rtree(id, minx, maxx, miny, maxy)`); err != nil {
+		return err
+	}
+	_, err := db.Exec(`INSERT INTO gpkg_extensions (table_name, column_name, extension_name, definition, scope) VALUES (?, ?, ?, 'http://www.geopackage.org/spec120/#extension_rtree', 'write-only')`, t.Name, t.GeometryColumn, rtreeExtension)
+	return err
+}
+
+// WriteFeatures inserts features into the feature table t, ignoring their
+// IDs, and updates the table's extent in gpkg_contents and its spatial index,
+// if any.  Geometries are encoded with t's SRID.  Properties not in t's
+// columns are ignored.
+func WriteFeatures(db DB, t *FeatureTable, features []*Feature) error {
+	columns := []string{quoteIdentifier(t.GeometryColumn)}
+	placeholders := []string{`?`}
+	for _, c := range t.Columns {
+		columns = append(columns, quoteIdentifier(c.Name))
+		placeholders = append(placeholders, `?`)
+	}
+	query := `INSERT INTO ` + quoteIdentifier(t.Name) + ` (` + strings.Join(columns, `, `) + `) VALUES (` + strings.Join(placeholders, `, `) + `)`
+
+	extent := geom.NewBounds(geom.XY)
+	for _, f := range features {
+		args := make([]interface{}, 0, 1+len(t.Columns))
+		var bounds *geom.Bounds
+		if f.Geometry == nil {
+			args = append(args, nil)
+		} else {
+			g, err := withSRID(f.Geometry, t.SRID)
+			if err != nil {
+				return err
+			}
+			data, err := Marshal(g, NDR)
+			if err != nil {
+				return err
+			}
+			args = append(args, data)
+			if len(f.Geometry.FlatCoords()) != 0 {
+				bounds = f.Geometry.Bounds()
+				extent.Extend(f.Geometry)
+			}
+		}
+		for _, c := range t.Columns {
+			args = append(args, f.Properties[c.Name])
+		}
+		result, err := db.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if !t.SpatialIndex || bounds == nil {
+			continue
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if _, err := db.Exec(`INSERT INTO `+quoteIdentifier(rtreeName(t))+` (id, minx, maxx, miny, maxy) VALUES (?, ?, ?, ?, ?)`, id, bounds.Min(0), bounds.Max(0), bounds.Min(1), bounds.Max(1)); err != nil {
+			return err
+		}
+	}
+
+	if extent.IsEmpty() {
+		return nil
+	}
+	minX, minY, maxX, maxY := extent.Min(0), extent.Min(1), extent.Max(0), extent.Max(1)
+	_, err := db.Exec(`UPDATE gpkg_contents SET min_x = min(coalesce(min_x, ?), ?), min_y = min(coalesce(min_y, ?), ?), max_x = max(coalesce(max_x, ?), ?), max_y = max(coalesce(max_y, ?), ?), last_change = strftime('%Y-%m-%dT%H:%M:%fZ','now') WHERE table_name = ?`, minX, minX, minY, minY, maxX, maxX, maxY, maxY, t.Name)
+	return err
+}
+
+// queryRow scans the single row returned by query into dest.
+func queryRow(db DB, dest []interface{}, query string, args ...interface{}) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// tableInfo returns the columns of the table name and the name of its
+// primary key column.
+func tableInfo(db DB, name string) ([]Column, string, error) {
+	rows, err := db.Query(`PRAGMA table_info(` + quoteIdentifier(name) + `)`)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var columns []Column
+	var pk string
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var c Column
+		var defaultValue interface{}
+		if err := rows.Scan(&cid, &c.Name, &c.Type, &notNull, &defaultValue, &primaryKey); err != nil {
+			return nil, "", err
+		}
+		if primaryKey != 0 {
+			pk = c.Name
+		}
+		columns = append(columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	if pk == "" {
+		return nil, "", fmt.Errorf("gpkg: %s: no primary key", name)
+	}
+	return columns, pk, nil
+}
+
+// rtreeName returns the name of t's R-tree spatial index table.
+func rtreeName(t *FeatureTable) string {
+	return "rtree_" + t.Name + "_" + t.GeometryColumn
+}
+
+// quoteIdentifier quotes the SQL identifier s.
+func quoteIdentifier(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
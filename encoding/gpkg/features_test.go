@@ -0,0 +1,233 @@
+package gpkg
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func mustMarshal(t *testing.T, g geom.T) []byte {
+	data, err := Marshal(g, NDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func expectFeatureTable(mock sqlmock.Sqlmock, spatialIndex bool) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT column_name, geometry_type_name, srs_id, z, m FROM gpkg_geometry_columns WHERE table_name = ?`)).
+		WithArgs("cities").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "geometry_type_name", "srs_id", "z", "m"}).AddRow("geom", "POINT", 4326, 0, 0))
+	n := 0
+	if spatialIndex {
+		n = 1
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM gpkg_extensions WHERE table_name = ? AND column_name = ? AND extension_name = ?`)).
+		WithArgs("cities", "geom", "gpkg_rtree_index").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(n))
+	mock.ExpectQuery(regexp.QuoteMeta(`PRAGMA table_info("cities")`)).
+		WillReturnRows(sqlmock.NewRows([]string{"cid", "name", "type", "notnull", "dflt_value", "pk"}).
+			AddRow(0, "fid", "INTEGER", 1, nil, 1).
+			AddRow(1, "geom", "POINT", 0, nil, 0).
+			AddRow(2, "name", "TEXT", 0, nil, 0))
+}
+
+func TestContents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT table_name, data_type, identifier, description, min_x, min_y, max_x, max_y, srs_id FROM gpkg_contents ORDER BY table_name`)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "data_type", "identifier", "description", "min_x", "min_y", "max_x", "max_y", "srs_id"}).
+			AddRow("cities", "features", "Cities", "", -0.1275, 48.8567, 2.3508, 51.50722, 4326).
+			AddRow("empty", "features", nil, nil, nil, nil, nil, nil, nil))
+	got, err := Contents(db)
+	want := []*Content{
+		{
+			TableName:  "cities",
+			DataType:   "features",
+			Identifier: "Cities",
+			Bounds:     geom.NewBounds(geom.XY).Set(-0.1275, 48.8567, 2.3508, 51.50722),
+			SRID:       4326,
+		},
+		{
+			TableName: "empty",
+			DataType:  "features",
+		},
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Contents(...) == %v, %v, want %v, <nil>", got, err, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadFeatures(t *testing.T) {
+	london := geom.NewPointFlat(geom.XY, []float64{-0.1275, 51.50722}).SetSRID(4326)
+	paris := geom.NewPointFlat(geom.XY, []float64{2.3508, 48.8567}).SetSRID(4326)
+	bounds := geom.NewBounds(geom.XY).Set(-1, 50, 1, 52)
+	for i, tc := range []struct {
+		spatialIndex bool
+		bounds       *geom.Bounds
+		query        string
+		args         []driver.Value
+		rows         [][]driver.Value
+		want         []*Feature
+	}{
+		{
+			query: `SELECT * FROM "cities"`,
+			rows: [][]driver.Value{
+				{int64(1), mustMarshal(t, london), "London"},
+				{int64(2), mustMarshal(t, paris), "Paris"},
+				{int64(3), nil, "Atlantis"},
+			},
+			want: []*Feature{
+				{ID: 1, Geometry: london, Properties: map[string]interface{}{"name": "London"}},
+				{ID: 2, Geometry: paris, Properties: map[string]interface{}{"name": "Paris"}},
+				{ID: 3, Properties: map[string]interface{}{"name": "Atlantis"}},
+			},
+		},
+		{
+			bounds: bounds,
+			query:  `SELECT * FROM "cities"`,
+			rows: [][]driver.Value{
+				{int64(1), mustMarshal(t, london), "London"},
+				{int64(2), mustMarshal(t, paris), "Paris"},
+				{int64(3), nil, "Atlantis"},
+			},
+			want: []*Feature{
+				{ID: 1, Geometry: london, Properties: map[string]interface{}{"name": "London"}},
+			},
+		},
+		{
+			spatialIndex: true,
+			bounds:       bounds,
+			query:        `SELECT * FROM "cities" WHERE "fid" IN (SELECT id FROM "rtree_cities_geom" WHERE minx <= ? AND maxx >= ? AND miny <= ? AND maxy >= ?)`,
+			args:         []driver.Value{1.0, -1.0, 52.0, 50.0},
+			rows: [][]driver.Value{
+				{int64(1), string(mustMarshal(t, london)), "London"},
+			},
+			want: []*Feature{
+				{ID: 1, Geometry: london, Properties: map[string]interface{}{"name": "London"}},
+			},
+		},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectFeatureTable(mock, tc.spatialIndex)
+		rows := sqlmock.NewRows([]string{"fid", "geom", "name"})
+		for _, row := range tc.rows {
+			rows.AddRow(row...)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(tc.query) + "$").WithArgs(tc.args...).WillReturnRows(rows)
+		if got, err := ReadFeatures(db, "cities", tc.bounds); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: ReadFeatures(...) == %v, %v, want %v, <nil>", i, got, err, tc.want)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+		db.Close()
+	}
+}
+
+func TestWriteFeatures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ft := &FeatureTable{
+		Name:           "cities",
+		GeometryColumn: "geom",
+		GeometryType:   "POINT",
+		SRID:           4326,
+		Layout:         geom.XY,
+		Columns:        []Column{{Name: "name", Type: "TEXT"}},
+		SpatialIndex:   true,
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE "cities" (fid INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL, "geom" POINT, "name" TEXT)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gpkg_contents (table_name, data_type, identifier, srs_id) VALUES (?, 'features', ?, ?)`)).
+		WithArgs("cities", "cities", 4326).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gpkg_geometry_columns (table_name, column_name, geometry_type_name, srs_id, z, m) VALUES (?, ?, ?, ?, ?, ?)`)).
+		WithArgs("cities", "geom", "POINT", 4326, 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE VIRTUAL TABLE "rtree_cities_geom" USING rtree(id, minx, maxx, miny, maxy)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO gpkg_extensions (table_name, column_name, extension_name, definition, scope) VALUES (?, ?, ?, 'http://www.geopackage.org/spec120/#extension_rtree', 'write-only')`)).
+		WithArgs("cities", "geom", "gpkg_rtree_index").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := CreateFeatureTable(db, ft); err != nil {
+		t.Fatalf("CreateFeatureTable(...) == %v, want <nil>", err)
+	}
+
+	// geometries are written with the table's SRID
+	london := geom.NewPointFlat(geom.XY, []float64{-0.1275, 51.50722})
+	paris := geom.NewPointFlat(geom.XY, []float64{2.3508, 48.8567})
+	features := []*Feature{
+		{Geometry: london, Properties: map[string]interface{}{"name": "London", "ignored": 1}},
+		{Geometry: paris, Properties: map[string]interface{}{"name": "Paris"}},
+		{Properties: map[string]interface{}{}},
+	}
+	insert := regexp.QuoteMeta(`INSERT INTO "cities" ("geom", "name") VALUES (?, ?)`)
+	insertRTree := regexp.QuoteMeta(`INSERT INTO "rtree_cities_geom" (id, minx, maxx, miny, maxy) VALUES (?, ?, ?, ?, ?)`)
+	mock.ExpectExec(insert).
+		WithArgs(mustMarshal(t, geom.NewPointFlat(geom.XY, london.FlatCoords()).SetSRID(4326)), "London").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(insertRTree).
+		WithArgs(1, -0.1275, -0.1275, 51.50722, 51.50722).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(insert).
+		WithArgs(mustMarshal(t, geom.NewPointFlat(geom.XY, paris.FlatCoords()).SetSRID(4326)), "Paris").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(insertRTree).
+		WithArgs(2, 2.3508, 2.3508, 48.8567, 48.8567).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(insert).
+		WithArgs(nil, nil).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE gpkg_contents SET min_x = min(coalesce(min_x, ?), ?), min_y = min(coalesce(min_y, ?), ?), max_x = max(coalesce(max_x, ?), ?), max_y = max(coalesce(max_y, ?), ?), last_change = strftime('%Y-%m-%dT%H:%M:%fZ','now') WHERE table_name = ?`)).
+		WithArgs(-0.1275, -0.1275, 48.8567, 48.8567, 2.3508, 2.3508, 51.50722, 51.50722, "cities").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := WriteFeatures(db, ft, features); err != nil {
+		t.Errorf("WriteFeatures(...) == %v, want <nil>", err)
+	}
+	if london.SRID() != 0 {
+		t.Errorf("WriteFeatures(...) modified geometry SRID")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadFeatureTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	expectFeatureTable(mock, true)
+	want := &FeatureTable{
+		Name:           "cities",
+		GeometryColumn: "geom",
+		GeometryType:   "POINT",
+		SRID:           4326,
+		Layout:         geom.XY,
+		Columns:        []Column{{Name: "name", Type: "TEXT"}},
+		SpatialIndex:   true,
+	}
+	if got, err := ReadFeatureTable(db, "cities"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadFeatureTable(...) == %+v, %v, want %+v, <nil>", got, err, want)
+	}
+}
@@ -0,0 +1,243 @@
+// Package gpkg implements GeoPackage binary geometry encoding and decoding.
+//
+// A GeoPackage binary geometry is a header, containing an SRID and an
+// optional envelope, followed by a WKB geometry.
+//
+// Feature tables in GeoPackage files can be read and written with any
+// database/sql SQLite driver.
+//
+// See http://www.geopackage.org/spec/#gpb_format.
+package gpkg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/geomutil"
+)
+
+var (
+	// XDR is big endian.
+	XDR = wkbcommon.XDR
+	// NDR is little endian.
+	NDR = wkbcommon.NDR
+)
+
+// Header flags.
+const (
+	flagByteOrder = 0x01
+	flagEnvelope  = 0x0e
+	flagEmpty     = 0x10
+	flagExtended  = 0x20
+)
+
+// Envelope contents indicators.
+const (
+	envelopeNone = 0
+	envelopeXY   = 1
+	envelopeXYZ  = 2
+	envelopeXYM  = 3
+	envelopeXYZM = 4
+)
+
+// An ErrBadMagic is returned when data does not start with the GeoPackage
+// binary magic number.
+type ErrBadMagic [2]byte
+
+func (e ErrBadMagic) Error() string {
+	return fmt.Sprintf("gpkg: bad magic %q", e[:])
+}
+
+// An ErrUnsupportedVersion is returned when the version is not supported.
+type ErrUnsupportedVersion byte
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("gpkg: unsupported version %d", byte(e))
+}
+
+// An ErrUnsupportedEnvelope is returned when the envelope contents indicator
+// is not supported.
+type ErrUnsupportedEnvelope byte
+
+func (e ErrUnsupportedEnvelope) Error() string {
+	return fmt.Sprintf("gpkg: unsupported envelope contents indicator %d", byte(e))
+}
+
+// An ErrExtendedGeometry is returned when an extended GeoPackage geometry is
+// encountered.
+type ErrExtendedGeometry struct{}
+
+func (e ErrExtendedGeometry) Error() string {
+	return "gpkg: extended geometries are not supported"
+}
+
+// A Header is a GeoPackage binary geometry header.
+type Header struct {
+	SRID  int
+	Empty bool
+	// Envelope is nil if the header does not contain an envelope.
+	Envelope *geom.Bounds
+	// Size is the size of the header in bytes.
+	Size int
+}
+
+// DecodeHeader decodes the header at the start of data, without decoding the
+// geometry.
+func DecodeHeader(data []byte) (*Header, error) {
+	if len(data) < 8 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if data[0] != 'G' || data[1] != 'P' {
+		return nil, ErrBadMagic{data[0], data[1]}
+	}
+	if data[2] != 0 {
+		return nil, ErrUnsupportedVersion(data[2])
+	}
+	flags := data[3]
+	if flags&flagExtended != 0 {
+		return nil, ErrExtendedGeometry{}
+	}
+	var byteOrder binary.ByteOrder = XDR
+	if flags&flagByteOrder != 0 {
+		byteOrder = NDR
+	}
+	h := &Header{
+		SRID:  int(int32(byteOrder.Uint32(data[4:]))),
+		Empty: flags&flagEmpty != 0,
+		Size:  8,
+	}
+	var layout geom.Layout
+	switch e := (flags & flagEnvelope) >> 1; e {
+	case envelopeNone:
+		return h, nil
+	case envelopeXY:
+		layout = geom.XY
+	case envelopeXYZ:
+		layout = geom.XYZ
+	case envelopeXYM:
+		layout = geom.XYM
+	case envelopeXYZM:
+		layout = geom.XYZM
+	default:
+		return nil, ErrUnsupportedEnvelope(e)
+	}
+	stride := layout.Stride()
+	h.Size += 16 * stride
+	if len(data) < h.Size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	// the envelope is stored as minx, maxx, miny, maxy, ...
+	args := make([]float64, 2*stride)
+	for i := 0; i < stride; i++ {
+		args[i] = math.Float64frombits(byteOrder.Uint64(data[8+16*i:]))
+		args[i+stride] = math.Float64frombits(byteOrder.Uint64(data[16+16*i:]))
+	}
+	h.Envelope = geom.NewBounds(layout).Set(args...)
+	return h, nil
+}
+
+// Unmarshal decodes a GeoPackage binary geometry.  The SRID of the returned
+// geometry is set from the header.  Empty Points, which are encoded with NaN
+// coordinates, are returned as Points with no coordinates.
+func Unmarshal(data []byte) (geom.T, error) {
+	h, err := DecodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	g, err := wkb.Unmarshal(data[h.Size:])
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := g.(*geom.Point); ok && h.Empty {
+		g = geom.NewPointFlat(p.Layout(), nil)
+	}
+	return geomutil.SetSRID(g, h.SRID)
+}
+
+// Marshal encodes g as a GeoPackage binary geometry using byteOrder for both
+// the header and the WKB geometry.  The header's SRID is g's SRID.  An
+// envelope with the same layout as g is included for all non-empty geometries
+// except Points, for which the envelope would duplicate the coordinates.
+func Marshal(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
+	var flags byte
+	switch byteOrder {
+	case XDR:
+	case NDR:
+		flags |= flagByteOrder
+	default:
+		return nil, wkbcommon.ErrUnsupportedByteOrder{}
+	}
+
+	var envelope []float64
+	_, isPoint := g.(*geom.Point)
+	switch {
+	case len(g.FlatCoords()) == 0:
+		flags |= flagEmpty
+	case !isPoint:
+		var e byte
+		switch g.Layout() {
+		case geom.XY:
+			e = envelopeXY
+		case geom.XYZ:
+			e = envelopeXYZ
+		case geom.XYM:
+			e = envelopeXYM
+		case geom.XYZM:
+			e = envelopeXYZM
+		default:
+			return nil, geom.ErrUnsupportedLayout(g.Layout())
+		}
+		flags |= e << 1
+		b := g.Bounds()
+		for i := 0; i < g.Stride(); i++ {
+			envelope = append(envelope, b.Min(i), b.Max(i))
+		}
+	}
+
+	data := make([]byte, 8+8*len(envelope))
+	data[0], data[1], data[2], data[3] = 'G', 'P', 0, flags
+	byteOrder.PutUint32(data[4:], uint32(int32(g.SRID())))
+	for i, x := range envelope {
+		byteOrder.PutUint64(data[8+8*i:], math.Float64bits(x))
+	}
+	// empty Points are encoded with quiet NaN coordinates
+	wg := g
+	if isPoint && len(g.FlatCoords()) == 0 {
+		nans := make([]float64, g.Stride())
+		for i := range nans {
+			nans[i] = math.Float64frombits(0x7ff8000000000000)
+		}
+		wg = geom.NewPointFlat(g.Layout(), nans)
+	}
+	wkbData, err := wkb.Marshal(wg, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, wkbData...), nil
+}
+
+// withSRID returns a geometry with the same coordinates as g, which it
+// shares, and the given SRID.  g is not modified.
+func withSRID(g geom.T, srid int) (geom.T, error) {
+	switch g := g.(type) {
+	case *geom.Point:
+		return geom.NewPointFlat(g.Layout(), g.FlatCoords()).SetSRID(srid), nil
+	case *geom.LineString:
+		return geom.NewLineStringFlat(g.Layout(), g.FlatCoords()).SetSRID(srid), nil
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(g.Layout(), g.FlatCoords(), g.Ends()).SetSRID(srid), nil
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(g.Layout(), g.FlatCoords()).SetSRID(srid), nil
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(g.Layout(), g.FlatCoords(), g.Ends()).SetSRID(srid), nil
+	case *geom.MultiPolygon:
+		return geom.NewMultiPolygonFlat(g.Layout(), g.FlatCoords(), g.Endss()).SetSRID(srid), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
@@ -0,0 +1,121 @@
+package gpkg
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/geomutil"
+	"github.com/twpayne/go-geom/internal/testdata"
+)
+
+func TestRandom(t *testing.T) {
+	for i, tc := range testdata.Random {
+		for _, byteOrder := range []binary.ByteOrder{XDR, NDR} {
+			g, err := geomutil.SetSRID(tc.G, 4326)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := Marshal(g, byteOrder)
+			if err != nil {
+				t.Errorf("%d: Marshal(%#v, %v) == nil, %v, want ..., nil", i, g, byteOrder, err)
+				continue
+			}
+			got, err := Unmarshal(data)
+			if err != nil || !reflect.DeepEqual(got, g) {
+				t.Errorf("%d: Unmarshal(%v) == %#v, %v, want %#v, nil", i, data, got, err, g)
+			}
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for i, tc := range []struct {
+		g         geom.T
+		byteOrder binary.ByteOrder
+		hex       string
+	}{
+		{
+			g:         geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			byteOrder: NDR,
+			hex:       "47500001e6100000" + "0101000000000000000000f03f0000000000000040",
+		},
+		{
+			g:         geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}).SetSRID(4326),
+			byteOrder: XDR,
+			hex: "47500002000010e6" +
+				"3ff0000000000000" + "4008000000000000" + "4000000000000000" + "4010000000000000" +
+				"000000000200000002" + "3ff0000000000000" + "4000000000000000" + "4008000000000000" + "4010000000000000",
+		},
+		{
+			g:         geom.NewPointFlat(geom.XY, nil),
+			byteOrder: XDR,
+			hex:       "4750001000000000" + "00000000017ff80000000000007ff8000000000000",
+		},
+	} {
+		data, err := Marshal(tc.g, tc.byteOrder)
+		if got := hex.EncodeToString(data); err != nil || got != tc.hex {
+			t.Errorf("%d: Marshal(%#v, %v) == %s, %v, want %s, <nil>", i, tc.g, tc.byteOrder, got, err, tc.hex)
+		}
+		want := tc.g
+		got, err := Unmarshal(data)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: Unmarshal(%s) == %#v, %v, want %#v, <nil>", i, tc.hex, got, err, want)
+		}
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 2, 0, 3, 2, 4, 5, 0, 0, 1}, []int{12}).SetSRID(3857)
+	data, err := Marshal(g, NDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Header{
+		SRID:     3857,
+		Envelope: geom.NewBounds(geom.XYZ).Set(0, 0, 1, 2, 4, 5),
+		Size:     56,
+	}
+	if got, err := DecodeHeader(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeHeader(...) == %+v, %v, want %+v, <nil>", got, err, want)
+	}
+}
+
+func TestEmptyPoint(t *testing.T) {
+	data, err := Marshal(geom.NewPointFlat(geom.XYZ, nil), NDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, err := DecodeHeader(data); err != nil || !h.Empty || h.Envelope != nil {
+		t.Errorf("DecodeHeader(...) == %+v, %v, want empty header without envelope", h, err)
+	}
+	g, err := Unmarshal(data)
+	if err != nil || len(g.FlatCoords()) != 0 || g.Layout() != geom.XYZ {
+		t.Errorf("Unmarshal(...) == %#v, %v, want empty XYZ point", g, err)
+	}
+	if !math.IsNaN(math.Float64frombits(binary.LittleEndian.Uint64(data[13:]))) {
+		t.Errorf("Marshal(...) did not encode NaN coordinates")
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for i, tc := range []struct {
+		data []byte
+		want error
+	}{
+		{data: nil, want: io.ErrUnexpectedEOF},
+		{data: []byte("XP\x00\x01\x00\x00\x00\x00"), want: ErrBadMagic{'X', 'P'}},
+		{data: []byte("GP\x01\x01\x00\x00\x00\x00"), want: ErrUnsupportedVersion(1)},
+		{data: []byte("GP\x00\x21\x00\x00\x00\x00"), want: ErrExtendedGeometry{}},
+		{data: []byte("GP\x00\x0b\x00\x00\x00\x00"), want: ErrUnsupportedEnvelope(5)},
+		{data: []byte("GP\x00\x03\x00\x00\x00\x00\x00"), want: io.ErrUnexpectedEOF},
+	} {
+		if _, err := Unmarshal(tc.data); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Unmarshal(%v) == ..., %v, want %v", i, tc.data, err, tc.want)
+		}
+	}
+}
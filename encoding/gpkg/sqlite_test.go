@@ -0,0 +1,108 @@
+//go:build sqlite3
+// +build sqlite3
+
+// The tests in this file use a real SQLite database through
+// github.com/mattn/go-sqlite3, which requires cgo and a newer Go than the
+// rest of the package, so they are only run with -tags sqlite3.
+
+package gpkg
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "test.gpkg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := Init(db); err != nil {
+		t.Fatalf("Init(...) == %v, want <nil>", err)
+	}
+	ft := &FeatureTable{
+		Name:           "cities",
+		GeometryColumn: "geom",
+		GeometryType:   "POINT",
+		SRID:           4326,
+		Layout:         geom.XY,
+		Columns:        []Column{{Name: "name", Type: "TEXT"}},
+		SpatialIndex:   true,
+	}
+	if err := CreateFeatureTable(db, ft); err != nil {
+		t.Fatalf("CreateFeatureTable(...) == %v, want <nil>", err)
+	}
+	if err := WriteFeatures(db, ft, []*Feature{
+		{Geometry: geom.NewPointFlat(geom.XY, []float64{-0.1275, 51.50722}), Properties: map[string]interface{}{"name": "London"}},
+		{Geometry: geom.NewPointFlat(geom.XY, []float64{2.3508, 48.8567}), Properties: map[string]interface{}{"name": "Paris"}},
+		{Properties: map[string]interface{}{"name": "Nowhere"}},
+	}); err != nil {
+		t.Fatalf("WriteFeatures(...) == %v, want <nil>", err)
+	}
+
+	if got, err := ReadFeatureTable(db, "cities"); err != nil || !reflect.DeepEqual(got, ft) {
+		t.Errorf("ReadFeatureTable(...) == %+v, %v, want %+v, <nil>", got, err, ft)
+	}
+
+	wantContents := []*Content{
+		{
+			TableName:  "cities",
+			DataType:   "features",
+			Identifier: "cities",
+			Bounds:     geom.NewBounds(geom.XY).Set(-0.1275, 48.8567, 2.3508, 51.50722),
+			SRID:       4326,
+		},
+	}
+	if got, err := Contents(db); err != nil || !reflect.DeepEqual(got, wantContents) {
+		t.Errorf("Contents(...) == %+v, %v, want %+v, <nil>", got, err, wantContents)
+	}
+
+	london := &Feature{
+		ID:         1,
+		Geometry:   geom.NewPointFlat(geom.XY, []float64{-0.1275, 51.50722}).SetSRID(4326),
+		Properties: map[string]interface{}{"name": "London"},
+	}
+	paris := &Feature{
+		ID:         2,
+		Geometry:   geom.NewPointFlat(geom.XY, []float64{2.3508, 48.8567}).SetSRID(4326),
+		Properties: map[string]interface{}{"name": "Paris"},
+	}
+	nowhere := &Feature{
+		ID:         3,
+		Properties: map[string]interface{}{"name": "Nowhere"},
+	}
+	for i, tc := range []struct {
+		bounds *geom.Bounds
+		want   []*Feature
+	}{
+		{
+			want: []*Feature{london, paris, nowhere},
+		},
+		{
+			bounds: geom.NewBounds(geom.XY).Set(-1, 51, 0, 52),
+			want:   []*Feature{london},
+		},
+		{
+			bounds: geom.NewBounds(geom.XY).Set(10, 10, 11, 11),
+		},
+	} {
+		if got, err := ReadFeatures(db, "cities", tc.bounds); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: ReadFeatures(...) == %+v, %v, want %+v, <nil>", i, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package mvt
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A commandEncoder encodes geometry command integers, keeping track of the
+// cursor position.
+type commandEncoder struct {
+	x, y     int64
+	commands []uint32
+}
+
+func (e *commandEncoder) command(id, count int) {
+	e.commands = append(e.commands, uint32(id&0x7|count<<3))
+}
+
+func (e *commandEncoder) moveTo(pts []int64) {
+	e.command(cmdMoveTo, len(pts)/2)
+	e.params(pts)
+}
+
+func (e *commandEncoder) lineTo(pts []int64) {
+	e.command(cmdLineTo, len(pts)/2)
+	e.params(pts)
+}
+
+func (e *commandEncoder) closePath() {
+	e.command(cmdClosePath, 1)
+}
+
+func (e *commandEncoder) params(pts []int64) {
+	for i := 0; i < len(pts); i += 2 {
+		e.commands = append(e.commands, zigzag(pts[i]-e.x), zigzag(pts[i+1]-e.y))
+		e.x, e.y = pts[i], pts[i+1]
+	}
+}
+
+func zigzag(n int64) uint32 {
+	return uint32((n << 1) ^ (n >> 63))
+}
+
+func unzigzag(u uint32) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// quantize rounds the coordinates between offset and end to integer x and y
+// values, optionally removing consecutive repeated points.
+func quantize(flatCoords []float64, offset, end, stride int, removeRepeated bool) []int64 {
+	pts := make([]int64, 0, (end-offset)/stride*2)
+	for i := offset; i < end; i += stride {
+		x, y := int64(math.Floor(flatCoords[i]+0.5)), int64(math.Floor(flatCoords[i+1]+0.5))
+		if removeRepeated && len(pts) != 0 && pts[len(pts)-2] == x && pts[len(pts)-1] == y {
+			continue
+		}
+		pts = append(pts, x, y)
+	}
+	return pts
+}
+
+// ringDoubleArea returns twice the signed area of the implicitly closed ring
+// pts, positive if the ring is clockwise in tile coordinates.
+func ringDoubleArea(pts []int64) int64 {
+	var sum int64
+	n := len(pts)
+	for i := 0; i < n; i += 2 {
+		j := (i + 2) % n
+		sum += pts[i]*pts[j+1] - pts[j]*pts[i+1]
+	}
+	return sum
+}
+
+func reverseXY(pts []int64) {
+	for i, j := 0, len(pts)-2; i < j; i, j = i+2, j-2 {
+		pts[i], pts[j] = pts[j], pts[i]
+		pts[i+1], pts[j+1] = pts[j+1], pts[i+1]
+	}
+}
+
+// encodeGeometry encodes g as geometry commands.  A nil geometry is encoded
+// with an unknown geometry type and no commands.
+func encodeGeometry(g geom.T) (int, []uint32, error) {
+	if g == nil {
+		return geomTypeUnknown, nil, nil
+	}
+	e := &commandEncoder{}
+	stride := g.Stride()
+	flatCoords := g.FlatCoords()
+	switch g := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		if pts := quantize(flatCoords, 0, len(flatCoords), stride, false); len(pts) != 0 {
+			e.moveTo(pts)
+		}
+		return geomTypePoint, e.commands, nil
+	case *geom.LineString, *geom.LinearRing:
+		e.encodeLine(flatCoords, 0, len(flatCoords), stride)
+		return geomTypeLineString, e.commands, nil
+	case *geom.MultiLineString:
+		offset := 0
+		for _, end := range g.Ends() {
+			e.encodeLine(flatCoords, offset, end, stride)
+			offset = end
+		}
+		return geomTypeLineString, e.commands, nil
+	case *geom.Polygon:
+		e.encodePolygon(flatCoords, 0, g.Ends(), stride)
+		return geomTypePolygon, e.commands, nil
+	case *geom.MultiPolygon:
+		offset := 0
+		for _, ends := range g.Endss() {
+			e.encodePolygon(flatCoords, offset, ends, stride)
+			if len(ends) != 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return geomTypePolygon, e.commands, nil
+	default:
+		return geomTypeUnknown, nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+func (e *commandEncoder) encodeLine(flatCoords []float64, offset, end, stride int) {
+	pts := quantize(flatCoords, offset, end, stride, true)
+	if len(pts) < 4 {
+		return
+	}
+	e.moveTo(pts[:2])
+	e.lineTo(pts[2:])
+}
+
+func (e *commandEncoder) encodePolygon(flatCoords []float64, offset int, ends []int, stride int) {
+	for i, end := range ends {
+		pts := quantize(flatCoords, offset, end, stride, true)
+		offset = end
+		if n := len(pts); n >= 2 && pts[0] == pts[n-2] && pts[1] == pts[n-1] {
+			pts = pts[:n-2]
+		}
+		area := int64(0)
+		if len(pts) >= 6 {
+			area = ringDoubleArea(pts)
+		}
+		if area == 0 {
+			if i == 0 {
+				// the exterior ring has collapsed, so skip the entire polygon
+				return
+			}
+			continue
+		}
+		if (i == 0) != (area > 0) {
+			reverseXY(pts)
+		}
+		e.moveTo(pts[:2])
+		e.lineTo(pts[2:])
+		e.closePath()
+	}
+}
+
+// decodeGeometry decodes geometry commands into a geometry in tile
+// coordinates.  Polygon rings with a positive area start a new polygon and
+// rings with a negative area are added as holes to the preceding polygon.
+func decodeGeometry(geomType int, commands []uint32) (geom.T, error) {
+	var parts [][]float64
+	var x, y int64
+	for i := 0; i < len(commands); {
+		id, count := int(commands[i]&0x7), int(commands[i]>>3)
+		i++
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if count > (len(commands)-i)/2 {
+				return nil, ErrInvalidGeometry("truncated parameters")
+			}
+			if id == cmdMoveTo && (geomType != geomTypePoint || len(parts) == 0) {
+				if geomType != geomTypePoint && count != 1 {
+					return nil, ErrInvalidGeometry("MoveTo with count other than 1")
+				}
+				parts = append(parts, nil)
+			}
+			if len(parts) == 0 {
+				return nil, ErrInvalidGeometry("LineTo without MoveTo")
+			}
+			part := parts[len(parts)-1]
+			for k := 0; k < count; k++ {
+				x += unzigzag(commands[i])
+				y += unzigzag(commands[i+1])
+				i += 2
+				part = append(part, float64(x), float64(y))
+			}
+			parts[len(parts)-1] = part
+		case cmdClosePath:
+			if len(parts) == 0 || len(parts[len(parts)-1]) == 0 {
+				return nil, ErrInvalidGeometry("ClosePath without MoveTo")
+			}
+			part := parts[len(parts)-1]
+			parts[len(parts)-1] = append(part, part[0], part[1])
+		default:
+			return nil, ErrInvalidGeometry("unknown command")
+		}
+	}
+
+	switch geomType {
+	case geomTypePoint:
+		switch {
+		case len(parts) == 0:
+			return nil, nil
+		case len(parts[0]) == 2:
+			return geom.NewPointFlat(geom.XY, parts[0]), nil
+		default:
+			return geom.NewMultiPointFlat(geom.XY, parts[0]), nil
+		}
+	case geomTypeLineString:
+		switch len(parts) {
+		case 0:
+			return nil, nil
+		case 1:
+			return geom.NewLineStringFlat(geom.XY, parts[0]), nil
+		default:
+			var flatCoords []float64
+			var ends []int
+			for _, part := range parts {
+				flatCoords = append(flatCoords, part...)
+				ends = append(ends, len(flatCoords))
+			}
+			return geom.NewMultiLineStringFlat(geom.XY, flatCoords, ends), nil
+		}
+	case geomTypePolygon:
+		var flatCoords []float64
+		var endss [][]int
+		for _, part := range parts {
+			signedArea := 0.0
+			for i := 2; i < len(part); i += 2 {
+				signedArea += part[i-2]*part[i+1] - part[i]*part[i-1]
+			}
+			switch {
+			case signedArea == 0:
+				continue
+			case signedArea > 0:
+				flatCoords = append(flatCoords, part...)
+				endss = append(endss, []int{len(flatCoords)})
+			case len(endss) != 0:
+				flatCoords = append(flatCoords, part...)
+				endss[len(endss)-1] = append(endss[len(endss)-1], len(flatCoords))
+			default:
+				return nil, ErrInvalidGeometry("interior ring without exterior ring")
+			}
+		}
+		switch len(endss) {
+		case 0:
+			return nil, nil
+		case 1:
+			return geom.NewPolygonFlat(geom.XY, flatCoords, endss[0]), nil
+		default:
+			return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss), nil
+		}
+	default:
+		return nil, nil
+	}
+}
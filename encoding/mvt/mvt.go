@@ -0,0 +1,459 @@
+// Package mvt implements Mapbox Vector Tile encoding and decoding.
+//
+// Geometries in a Layer are in tile coordinates: integers between 0 and the
+// layer's extent with the origin at the top left of the tile and the y axis
+// pointing down.  A Tiler converts geometries between source coordinates and
+// tile coordinates.
+//
+// See https://github.com/mapbox/vector-tile-spec/tree/master/2.1.
+package mvt
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Version is the version of the vector tile specification that is encoded.
+const Version = 2
+
+// DefaultExtent is the default extent of a layer.
+const DefaultExtent = 4096
+
+// Geometry types.
+const (
+	geomTypeUnknown    = 0
+	geomTypePoint      = 1
+	geomTypeLineString = 2
+	geomTypePolygon    = 3
+)
+
+// Geometry commands.
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// Field numbers.
+const (
+	tileLayers = 3
+
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+	layerVersion  = 15
+
+	featureID       = 1
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueFloat  = 2
+	valueDouble = 3
+	valueInt    = 4
+	valueUint   = 5
+	valueSint   = 6
+	valueBool   = 7
+)
+
+// An ErrUnsupportedValue is returned when a property value cannot be encoded.
+type ErrUnsupportedValue struct {
+	Value interface{}
+}
+
+func (e ErrUnsupportedValue) Error() string {
+	return fmt.Sprintf("mvt: unsupported value type %T", e.Value)
+}
+
+// An ErrUnsupportedWireType is returned when an unknown protocol buffer wire
+// type is encountered.
+type ErrUnsupportedWireType int
+
+func (e ErrUnsupportedWireType) Error() string {
+	return fmt.Sprintf("mvt: unsupported wire type %d", int(e))
+}
+
+// An ErrInvalidGeometry is returned when a feature's geometry commands are
+// invalid.
+type ErrInvalidGeometry string
+
+func (e ErrInvalidGeometry) Error() string {
+	return fmt.Sprintf("mvt: invalid geometry: %s", string(e))
+}
+
+// A Feature is a vector tile feature.  An ID of zero is not encoded.
+type Feature struct {
+	ID         uint64
+	Geometry   geom.T
+	Properties map[string]interface{}
+}
+
+// A Layer is a vector tile layer.  A Version or Extent of zero is encoded as
+// Version or DefaultExtent respectively.
+type Layer struct {
+	Name     string
+	Version  int
+	Extent   int
+	Features []*Feature
+}
+
+// A Tile is a vector tile.
+type Tile struct {
+	Layers []*Layer
+}
+
+// Marshal encodes t.  Coordinates are rounded to the nearest integer and
+// polygon rings are re-oriented so that exterior rings have a positive area
+// and interior rings a negative area in tile coordinates, as required by the
+// specification.  Exterior rings therefore appear clockwise when the tile is
+// displayed.
+func Marshal(t *Tile) ([]byte, error) {
+	var b []byte
+	for _, l := range t.Layers {
+		data, err := marshalLayer(l)
+		if err != nil {
+			return nil, err
+		}
+		b = appendBytesField(b, tileLayers, data)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes a tile.
+func Unmarshal(data []byte) (*Tile, error) {
+	t := &Tile{}
+	r := &pbfReader{data: data}
+	for {
+		field, wireType, err := r.next()
+		if err == io.EOF {
+			return t, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if field != tileLayers || wireType != wireBytes {
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		data, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		l, err := unmarshalLayer(data)
+		if err != nil {
+			return nil, err
+		}
+		t.Layers = append(t.Layers, l)
+	}
+}
+
+func marshalLayer(l *Layer) ([]byte, error) {
+	version, extent := l.Version, l.Extent
+	if version == 0 {
+		version = Version
+	}
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+
+	var keys []string
+	keyIndex := make(map[string]uint32)
+	var values []interface{}
+	valueIndex := make(map[interface{}]uint32)
+
+	b := appendVarintField(nil, layerVersion, uint64(version))
+	b = appendStringField(b, layerName, l.Name)
+	for _, f := range l.Features {
+		var tags []uint32
+		names := make([]string, 0, len(f.Properties))
+		for name := range f.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value, err := normalizeValue(f.Properties[name])
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				continue
+			}
+			ki, ok := keyIndex[name]
+			if !ok {
+				ki = uint32(len(keys))
+				keyIndex[name] = ki
+				keys = append(keys, name)
+			}
+			vi, ok := valueIndex[value]
+			if !ok {
+				vi = uint32(len(values))
+				valueIndex[value] = vi
+				values = append(values, value)
+			}
+			tags = append(tags, ki, vi)
+		}
+
+		geomType, commands, err := encodeGeometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+
+		var fb []byte
+		if f.ID != 0 {
+			fb = appendVarintField(fb, featureID, f.ID)
+		}
+		if len(tags) != 0 {
+			fb = appendPackedField(fb, featureTags, tags)
+		}
+		fb = appendVarintField(fb, featureType, uint64(geomType))
+		fb = appendPackedField(fb, featureGeometry, commands)
+		b = appendBytesField(b, layerFeatures, fb)
+	}
+	for _, key := range keys {
+		b = appendStringField(b, layerKeys, key)
+	}
+	for _, value := range values {
+		b = appendBytesField(b, layerValues, marshalValue(value))
+	}
+	b = appendVarintField(b, layerExtent, uint64(extent))
+	return b, nil
+}
+
+func unmarshalLayer(data []byte) (*Layer, error) {
+	l := &Layer{Version: 1, Extent: DefaultExtent}
+	var featureData [][]byte
+	var keys []string
+	var values []interface{}
+	r := &pbfReader{data: data}
+	for {
+		field, wireType, err := r.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == layerName && wireType == wireBytes:
+			name, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			l.Name = string(name)
+		case field == layerFeatures && wireType == wireBytes:
+			fd, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			featureData = append(featureData, fd)
+		case field == layerKeys && wireType == wireBytes:
+			key, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, string(key))
+		case field == layerValues && wireType == wireBytes:
+			vd, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			value, err := unmarshalValue(vd)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		case field == layerExtent && wireType == wireVarint:
+			extent, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			l.Extent = int(extent)
+		case field == layerVersion && wireType == wireVarint:
+			version, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			l.Version = int(version)
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// features are decoded last as the keys and values may follow them
+	for _, fd := range featureData {
+		f, err := unmarshalFeature(fd, keys, values)
+		if err != nil {
+			return nil, err
+		}
+		l.Features = append(l.Features, f)
+	}
+	return l, nil
+}
+
+func unmarshalFeature(data []byte, keys []string, values []interface{}) (*Feature, error) {
+	f := &Feature{}
+	geomType := geomTypeUnknown
+	var tags, commands []uint32
+	r := &pbfReader{data: data}
+	for {
+		field, wireType, err := r.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == featureID && wireType == wireVarint:
+			if f.ID, err = r.varint(); err != nil {
+				return nil, err
+			}
+		case field == featureTags && (wireType == wireBytes || wireType == wireVarint):
+			if tags, err = r.packed(wireType, tags); err != nil {
+				return nil, err
+			}
+		case field == featureType && wireType == wireVarint:
+			t, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			geomType = int(t)
+		case field == featureGeometry && (wireType == wireBytes || wireType == wireVarint):
+			if commands, err = r.packed(wireType, commands); err != nil {
+				return nil, err
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(tags)%2 != 0 {
+		return nil, ErrInvalidGeometry("odd number of tags")
+	}
+	if len(tags) != 0 {
+		f.Properties = make(map[string]interface{}, len(tags)/2)
+	}
+	for i := 0; i < len(tags); i += 2 {
+		if int(tags[i]) >= len(keys) || int(tags[i+1]) >= len(values) {
+			return nil, ErrInvalidGeometry("tag index out of range")
+		}
+		f.Properties[keys[tags[i]]] = values[tags[i+1]]
+	}
+	g, err := decodeGeometry(geomType, commands)
+	if err != nil {
+		return nil, err
+	}
+	f.Geometry = g
+	return f, nil
+}
+
+// normalizeValue converts v to one of the types that can be encoded: string,
+// float32, float64, int64, uint64, or bool.  nil values are returned as nil.
+func normalizeValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string, float32, float64, int64, uint64, bool:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	default:
+		return nil, ErrUnsupportedValue{Value: v}
+	}
+}
+
+func marshalValue(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return appendStringField(nil, valueString, v)
+	case float32:
+		return appendFixed32Field(nil, valueFloat, math.Float32bits(v))
+	case float64:
+		return appendFixed64Field(nil, valueDouble, math.Float64bits(v))
+	case int64:
+		if v < 0 {
+			return appendVarintField(nil, valueSint, uint64(v<<1)^uint64(v>>63))
+		}
+		return appendVarintField(nil, valueInt, uint64(v))
+	case uint64:
+		return appendVarintField(nil, valueUint, v)
+	case bool:
+		var u uint64
+		if v {
+			u = 1
+		}
+		return appendVarintField(nil, valueBool, u)
+	default:
+		panic(fmt.Sprintf("mvt: unexpected value type %T", v))
+	}
+}
+
+func unmarshalValue(data []byte) (interface{}, error) {
+	var value interface{}
+	r := &pbfReader{data: data}
+	for {
+		field, wireType, err := r.next()
+		if err == io.EOF {
+			return value, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == valueString && wireType == wireBytes:
+			s, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			value = string(s)
+		case field == valueFloat && wireType == wireFixed32:
+			if value, err = r.float32(); err != nil {
+				return nil, err
+			}
+		case field == valueDouble && wireType == wireFixed64:
+			if value, err = r.float64(); err != nil {
+				return nil, err
+			}
+		case (field == valueInt || field == valueUint || field == valueSint || field == valueBool) && wireType == wireVarint:
+			u, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			switch field {
+			case valueInt:
+				value = int64(u)
+			case valueUint:
+				value = u
+			case valueSint:
+				value = int64(u>>1) ^ -int64(u&1)
+			case valueBool:
+				value = u != 0
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
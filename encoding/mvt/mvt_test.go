@@ -0,0 +1,330 @@
+package mvt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestEncodeGeometry(t *testing.T) {
+	// examples from the vector tile specification
+	for i, tc := range []struct {
+		g        geom.T
+		geomType int
+		commands []uint32
+	}{
+		{
+			g:        geom.NewPointFlat(geom.XY, []float64{25, 17}),
+			geomType: geomTypePoint,
+			commands: []uint32{9, 50, 34},
+		},
+		{
+			g:        geom.NewMultiPointFlat(geom.XY, []float64{5, 7, 3, 2}),
+			geomType: geomTypePoint,
+			commands: []uint32{17, 10, 14, 3, 9},
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 10, 10}),
+			geomType: geomTypeLineString,
+			commands: []uint32{9, 4, 4, 18, 0, 16, 16, 0},
+		},
+		{
+			g:        geom.NewMultiLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 10, 10, 1, 1, 3, 5}, []int{6, 10}),
+			geomType: geomTypeLineString,
+			commands: []uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, []float64{3, 6, 8, 12, 20, 34, 3, 6}, []int{8}),
+			geomType: geomTypePolygon,
+			commands: []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			// the same polygon with the opposite winding order is reversed
+			g:        geom.NewPolygonFlat(geom.XY, []float64{3, 6, 20, 34, 8, 12, 3, 6}, []int{8}),
+			geomType: geomTypePolygon,
+			commands: []uint32{9, 16, 24, 18, 24, 44, 33, 55, 15},
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				11, 11, 20, 11, 20, 20, 11, 20, 11, 11,
+				13, 13, 13, 17, 17, 17, 17, 13, 13, 13,
+			}, [][]int{{10}, {20, 30}}),
+			geomType: geomTypePolygon,
+			commands: []uint32{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+				9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+			},
+		},
+	} {
+		geomType, commands, err := encodeGeometry(tc.g)
+		if err != nil {
+			t.Errorf("%d: encodeGeometry(%v) == _, _, %v, want _, _, <nil>", i, tc.g, err)
+			continue
+		}
+		if geomType != tc.geomType || !reflect.DeepEqual(commands, tc.commands) {
+			t.Errorf("%d: encodeGeometry(%v) == %v, %v, want %v, %v", i, tc.g, geomType, commands, tc.geomType, tc.commands)
+		}
+	}
+}
+
+func TestDecodeGeometry(t *testing.T) {
+	for i, tc := range []struct {
+		geomType int
+		commands []uint32
+		want     geom.T
+	}{
+		{
+			geomType: geomTypePoint,
+			commands: []uint32{17, 10, 14, 3, 9},
+			want:     geom.NewMultiPointFlat(geom.XY, []float64{5, 7, 3, 2}),
+		},
+		{
+			geomType: geomTypeLineString,
+			commands: []uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+			want:     geom.NewMultiLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 10, 10, 1, 1, 3, 5}, []int{6, 10}),
+		},
+		{
+			geomType: geomTypePolygon,
+			commands: []uint32{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+				9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+			},
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				11, 11, 20, 11, 20, 20, 11, 20, 11, 11,
+				13, 13, 13, 17, 17, 17, 17, 13, 13, 13,
+			}, [][]int{{10}, {20, 30}}),
+		},
+	} {
+		got, err := decodeGeometry(tc.geomType, tc.commands)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: decodeGeometry(%v, %v) == %v, %v, want %v, <nil>", i, tc.geomType, tc.commands, got, err, tc.want)
+		}
+	}
+}
+
+func TestDecodeGeometryErrors(t *testing.T) {
+	for i, tc := range []struct {
+		geomType int
+		commands []uint32
+	}{
+		{geomType: geomTypePoint, commands: []uint32{9, 50}},
+		{geomType: geomTypeLineString, commands: []uint32{18, 0, 16}},
+		{geomType: geomTypePolygon, commands: []uint32{15}},
+		{geomType: geomTypePolygon, commands: []uint32{9, 0, 0, 26, 0, 20, 20, 0, 0, 19, 15}},
+		{geomType: geomTypeLineString, commands: []uint32{12}},
+	} {
+		if _, err := decodeGeometry(tc.geomType, tc.commands); err == nil {
+			t.Errorf("%d: decodeGeometry(%v, %v) == _, <nil>, want _, !<nil>", i, tc.geomType, tc.commands)
+		}
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	tile := &Tile{
+		Layers: []*Layer{
+			{
+				Name:    "water",
+				Version: 2,
+				Extent:  4096,
+				Features: []*Feature{
+					{
+						ID:       1,
+						Geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 0, 10, 0, 0}, []int{10}),
+						Properties: map[string]interface{}{
+							"name":   "lake",
+							"depth":  int64(-12),
+							"area":   float64(100.5),
+							"ratio":  float32(0.25),
+							"count":  uint64(3),
+							"public": true,
+							"rank":   int64(7),
+						},
+					},
+					{
+						Geometry: geom.NewLineStringFlat(geom.XY, []float64{1, 1, 2, 2, 3, 1}),
+						Properties: map[string]interface{}{
+							"name": "river",
+						},
+					},
+				},
+			},
+			{
+				Name:    "poi",
+				Version: 2,
+				Extent:  512,
+				Features: []*Feature{
+					{
+						ID:       7,
+						Geometry: geom.NewPointFlat(geom.XY, []float64{25, 17}),
+					},
+				},
+			},
+		},
+	}
+	data, err := Marshal(tile)
+	if err != nil {
+		t.Fatalf("Marshal(%v) == _, %v, want _, <nil>", tile, err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(%v) == _, %v, want _, <nil>", data, err)
+	}
+	if !reflect.DeepEqual(got, tile) {
+		t.Errorf("Unmarshal(Marshal(%v)) == %v, want %v", tile, got, tile)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for i, tile := range []*Tile{
+		{Layers: []*Layer{{Features: []*Feature{{Geometry: geom.NewPointFlat(geom.XY, []float64{0, 0}), Properties: map[string]interface{}{"a": []int{1}}}}}}},
+	} {
+		if _, err := Marshal(tile); err == nil {
+			t.Errorf("%d: Marshal(%v) == _, <nil>, want _, !<nil>", i, tile)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for i, data := range [][]byte{
+		{0x1a},
+		{0x1a, 0x05, 0x0a},
+		{0x1a, 0x02, 0x13, 0x00},
+	} {
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("%d: Unmarshal(%v) == _, <nil>, want _, !<nil>", i, data)
+		}
+	}
+}
+
+func TestTilerClip(t *testing.T) {
+	tiler := NewTiler(geom.NewBounds(geom.XY).Set(0, 0, 100, 100)).SetExtent(100).SetBuffer(10)
+	for i, tc := range []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XYZ, []float64{25.2, 80.7, 3}),
+			want: geom.NewPointFlat(geom.XY, []float64{25, 19}),
+		},
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{150, 50}),
+			want: nil,
+		},
+		{
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{150, 50, 50, 50}),
+			want: geom.NewMultiPointFlat(geom.XY, []float64{50, 50}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{-50, 50, 150, 50}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{-10, 50, 110, 50}),
+		},
+		{
+			// a line that leaves and re-enters the tile is split
+			g:    geom.NewLineStringFlat(geom.XY, []float64{50, 90, 50, 150, 60, 150, 60, 90}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{50, 10, 50, -10, 60, -10, 60, 10}, []int{4, 8}),
+		},
+		{
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				-50, -50, 150, -50, 150, 150, -50, 150, -50, -50,
+				40, 40, 60, 40, 60, 60, 40, 60, 40, 40,
+			}, []int{10, 20}),
+			// exterior rings are oriented counter-clockwise and holes
+			// clockwise in tile coordinates
+			want: geom.NewPolygonFlat(geom.XY, []float64{
+				-10, -10, 110, -10, 110, 110, -10, 110, -10, -10,
+				40, 60, 60, 60, 60, 40, 40, 40, 40, 60,
+			}, []int{10, 20}),
+		},
+		{
+			// a polygon that leaves and re-enters the tile is split into
+			// separate polygons
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				20, 80, 40, 80, 40, 150, 60, 150, 60, 80, 80, 80, 80, 200, 20, 200, 20, 80,
+			}, []int{18}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				80, -10, 80, 20, 60, 20, 60, -10, 80, -10,
+				40, -10, 40, 20, 20, 20, 20, -10, 40, -10,
+			}, [][]int{{10}, {20}}),
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{200, 200, 300, 200, 300, 300, 200, 200}, []int{8}),
+			want: nil,
+		},
+	} {
+		got, err := tiler.Clip(tc.g)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: tiler.Clip(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+}
+
+func TestTilerRoundTrip(t *testing.T) {
+	tiler := NewTiler(geom.NewBounds(geom.XY).Set(0, 0, 4096, 4096))
+	features := []*Feature{
+		{
+			ID: 1,
+			// counter-clockwise exterior and clockwise hole, as in RFC 7946
+			Geometry: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 100, 0, 100, 100, 0, 100, 0, 0,
+				25, 25, 25, 75, 75, 75, 75, 25, 25, 25,
+			}, []int{10, 20}),
+			Properties: map[string]interface{}{"kind": "building"},
+		},
+	}
+	layer, err := tiler.Layer("buildings", features)
+	if err != nil {
+		t.Fatalf("tiler.Layer(...) == _, %v, want _, <nil>", err)
+	}
+	data, err := Marshal(&Tile{Layers: []*Layer{layer}})
+	if err != nil {
+		t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+	}
+	tile, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	// vector tiles use clockwise exterior rings and counter-clockwise
+	// interior rings, so the decoded rings are reversed
+	want := []*Feature{
+		{
+			ID: 1,
+			Geometry: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 100, 100, 100, 100, 0, 0, 0, 0, 100,
+				75, 25, 75, 75, 25, 75, 25, 25, 75, 25,
+			}, []int{10, 20}),
+			Properties: map[string]interface{}{"kind": "building"},
+		},
+	}
+	if got := tiler.Features(tile.Layers[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip == %v, want %v", got[0].Geometry, want[0].Geometry)
+	}
+}
+
+func TestTilerNilGeometry(t *testing.T) {
+	tiler := NewTiler(geom.NewBounds(geom.XY).Set(0, 0, 100, 100))
+	if got, err := tiler.Clip(nil); err != nil || got != nil {
+		t.Errorf("tiler.Clip(nil) == %v, %v, want <nil>, <nil>", got, err)
+	}
+	features := []*Feature{
+		{ID: 1, Properties: map[string]interface{}{"kind": "unknown"}},
+		{ID: 2, Geometry: geom.NewPointFlat(geom.XY, []float64{500, 500})},
+	}
+	layer, err := tiler.Layer("features", features)
+	if err != nil {
+		t.Fatalf("tiler.Layer(...) == _, %v, want _, <nil>", err)
+	}
+	want := []*Feature{
+		{ID: 1, Properties: map[string]interface{}{"kind": "unknown"}},
+	}
+	if !reflect.DeepEqual(layer.Features, want) {
+		t.Errorf("tiler.Layer(...).Features == %v, want %v", layer.Features, want)
+	}
+	if _, err := Marshal(&Tile{Layers: []*Layer{layer}}); err != nil {
+		t.Errorf("Marshal(...) == _, %v, want _, <nil>", err)
+	}
+}
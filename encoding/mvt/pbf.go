@@ -0,0 +1,177 @@
+package mvt
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("mvt: truncated data")
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendKey(b []byte, field, wireType int) []byte {
+	return appendVarint(b, uint64(field<<3|wireType))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	return appendVarint(appendKey(b, field, wireVarint), v)
+}
+
+func appendBytesField(b []byte, field int, data []byte) []byte {
+	b = appendVarint(appendKey(b, field, wireBytes), uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendStringField(b []byte, field int, s string) []byte {
+	b = appendVarint(appendKey(b, field, wireBytes), uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendFixed32Field(b []byte, field int, v uint32) []byte {
+	b = appendKey(b, field, wireFixed32)
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendFixed64Field(b []byte, field int, v uint64) []byte {
+	b = appendKey(b, field, wireFixed64)
+	for i := uint(0); i < 64; i += 8 {
+		b = append(b, byte(v>>i))
+	}
+	return b
+}
+
+func appendPackedField(b []byte, field int, vs []uint32) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = appendVarint(packed, uint64(v))
+	}
+	return appendBytesField(b, field, packed)
+}
+
+// A pbfReader reads protocol buffer fields from a byte slice.
+type pbfReader struct {
+	data []byte
+	pos  int
+}
+
+// next returns the field number and wire type of the next field, or io.EOF
+// if there are no more fields.
+func (r *pbfReader) next() (field, wireType int, err error) {
+	if r.pos == len(r.data) {
+		return 0, 0, io.EOF
+	}
+	key, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(key >> 3), int(key & 0x7), nil
+}
+
+func (r *pbfReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *pbfReader) bytes() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		return nil, errTruncated
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+func (r *pbfReader) fixed32() (uint32, error) {
+	if len(r.data)-r.pos < 4 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+func (r *pbfReader) fixed64() (uint64, error) {
+	if len(r.data)-r.pos < 8 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	return v, nil
+}
+
+func (r *pbfReader) float32() (float32, error) {
+	v, err := r.fixed32()
+	return math.Float32frombits(v), err
+}
+
+func (r *pbfReader) float64() (float64, error) {
+	v, err := r.fixed64()
+	return math.Float64frombits(v), err
+}
+
+// packed reads a packed repeated field of varints.  For compatibility, a
+// single unpacked varint is also accepted.
+func (r *pbfReader) packed(wireType int, vs []uint32) ([]uint32, error) {
+	if wireType == wireVarint {
+		v, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		return append(vs, uint32(v)), nil
+	}
+	data, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	pr := &pbfReader{data: data}
+	for pr.pos < len(pr.data) {
+		v, err := pr.varint()
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, uint32(v))
+	}
+	return vs, nil
+}
+
+// skip skips over a field with the given wire type.
+func (r *pbfReader) skip(wireType int) error {
+	var err error
+	switch wireType {
+	case wireVarint:
+		_, err = r.varint()
+	case wireFixed64:
+		_, err = r.fixed64()
+	case wireBytes:
+		_, err = r.bytes()
+	case wireFixed32:
+		_, err = r.fixed32()
+	default:
+		err = ErrUnsupportedWireType(wireType)
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package mvt
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/clip"
+)
+
+// DefaultBuffer is the default size of the buffer around a tile, in tile
+// coordinates, within which geometries are kept when clipping.
+const DefaultBuffer = 64
+
+// A Tiler transforms geometries between source coordinates and the tile
+// coordinates of a single tile.
+type Tiler struct {
+	bounds *geom.Bounds
+	extent int
+	buffer int
+}
+
+// NewTiler returns a new Tiler for the tile that covers bounds in source
+// coordinates, for example Web Mercator meters.  The minimum x and maximum y
+// of bounds correspond to the top left corner of the tile.
+func NewTiler(bounds *geom.Bounds) *Tiler {
+	return &Tiler{
+		bounds: bounds,
+		extent: DefaultExtent,
+		buffer: DefaultBuffer,
+	}
+}
+
+// SetBuffer sets the size of the buffer around the tile, in tile coordinates.
+func (t *Tiler) SetBuffer(buffer int) *Tiler {
+	t.buffer = buffer
+	return t
+}
+
+// SetExtent sets the extent of the tile.
+func (t *Tiler) SetExtent(extent int) *Tiler {
+	t.extent = extent
+	return t
+}
+
+// Clip transforms g into tile coordinates, clips it to the tile and its
+// buffer, and rounds the coordinates to integers.  Repeated points are
+// removed, as are lines and rings that collapse.  The result always has
+// layout XY.  If g is nil or nothing remains of g then nil is returned.
+func (t *Tiler) Clip(g geom.T) (geom.T, error) {
+	if g == nil {
+		return nil, nil
+	}
+	buffer, extent := float64(t.buffer), float64(t.extent)
+	bounds := geom.NewBounds(geom.XY).Set(-buffer, -buffer, extent+buffer, extent+buffer)
+	projected := withFlatCoordsXY(g, t.project(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride()))
+	if projected == nil {
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	clipped, err := clip.ToBounds(projected, bounds)
+	if err != nil {
+		return nil, err
+	}
+	switch clipped := clipped.(type) {
+	case *geom.MultiPoint:
+		pts := clipped.FlatCoords()
+		for i := range pts {
+			pts[i] = math.Floor(pts[i] + 0.5)
+		}
+		switch {
+		case len(pts) == 0:
+			return nil, nil
+		case len(pts) == 2:
+			if _, ok := g.(*geom.Point); ok {
+				return geom.NewPointFlat(geom.XY, pts), nil
+			}
+		}
+		return geom.NewMultiPointFlat(geom.XY, pts), nil
+	case *geom.MultiLineString:
+		return roundLines(clipped), nil
+	case *geom.MultiPolygon:
+		return roundPolygons(clipped), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// Layer returns a new Layer called name containing features, with their
+// geometries clipped as by Clip.  Features whose geometries are clipped away
+// entirely are omitted.  Features without a geometry are kept.
+func (t *Tiler) Layer(name string, features []*Feature) (*Layer, error) {
+	l := &Layer{
+		Name:    name,
+		Version: Version,
+		Extent:  t.extent,
+	}
+	for _, f := range features {
+		g, err := t.Clip(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil && f.Geometry != nil {
+			continue
+		}
+		l.Features = append(l.Features, &Feature{
+			ID:         f.ID,
+			Geometry:   g,
+			Properties: f.Properties,
+		})
+	}
+	return l, nil
+}
+
+// Features returns l's features with their geometries transformed from tile
+// coordinates back into source coordinates.
+func (t *Tiler) Features(l *Layer) []*Feature {
+	extent := float64(l.Extent)
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+	minX, maxY := t.bounds.Min(0), t.bounds.Max(1)
+	sx := (t.bounds.Max(0) - minX) / extent
+	sy := (maxY - t.bounds.Min(1)) / extent
+	features := make([]*Feature, 0, len(l.Features))
+	for _, f := range l.Features {
+		var g geom.T
+		if f.Geometry != nil {
+			flatCoords := make([]float64, 0, len(f.Geometry.FlatCoords())/f.Geometry.Stride()*2)
+			for i := 0; i < len(f.Geometry.FlatCoords()); i += f.Geometry.Stride() {
+				x, y := f.Geometry.FlatCoords()[i], f.Geometry.FlatCoords()[i+1]
+				flatCoords = append(flatCoords, minX+x*sx, maxY-y*sy)
+			}
+			g = withFlatCoordsXY(f.Geometry, flatCoords)
+		}
+		features = append(features, &Feature{
+			ID:         f.ID,
+			Geometry:   g,
+			Properties: f.Properties,
+		})
+	}
+	return features
+}
+
+// project returns the x and y ordinates of the coordinates between offset and
+// end transformed into tile coordinates.
+func (t *Tiler) project(flatCoords []float64, offset, end, stride int) []float64 {
+	minX, maxY := t.bounds.Min(0), t.bounds.Max(1)
+	sx := float64(t.extent) / (t.bounds.Max(0) - minX)
+	sy := float64(t.extent) / (maxY - t.bounds.Min(1))
+	xy := make([]float64, 0, (end-offset)/stride*2)
+	for i := offset; i < end; i += stride {
+		xy = append(xy, (flatCoords[i]-minX)*sx, (maxY-flatCoords[i+1])*sy)
+	}
+	return xy
+}
+
+// roundLines rounds the coordinates of the line strings of mls to integers
+// and removes those that collapse.
+func roundLines(mls *geom.MultiLineString) geom.T {
+	var flatCoords []float64
+	var ends []int
+	offset := 0
+	for _, end := range mls.Ends() {
+		if line := roundXY(mls.FlatCoords()[offset:end]); len(line) >= 4 {
+			flatCoords = append(flatCoords, line...)
+			ends = append(ends, len(flatCoords))
+		}
+		offset = end
+	}
+	switch len(ends) {
+	case 0:
+		return nil
+	case 1:
+		return geom.NewLineStringFlat(geom.XY, flatCoords)
+	default:
+		return geom.NewMultiLineStringFlat(geom.XY, flatCoords, ends)
+	}
+}
+
+// roundPolygons rounds the coordinates of the polygons of mp to integers and
+// removes rings that collapse, and polygons whose exterior ring collapses.
+func roundPolygons(mp *geom.MultiPolygon) geom.T {
+	var flatCoords []float64
+	var endss [][]int
+	offset := 0
+	for _, ends := range mp.Endss() {
+		var polygonEnds []int
+		for i, end := range ends {
+			ring := roundXY(mp.FlatCoords()[offset:end])
+			offset = end
+			if len(ring) < 8 || geom.NewLinearRingFlat(geom.XY, ring).Area() == 0 {
+				if i == 0 {
+					break
+				}
+				continue
+			}
+			flatCoords = append(flatCoords, ring...)
+			polygonEnds = append(polygonEnds, len(flatCoords))
+		}
+		if len(ends) != 0 {
+			offset = ends[len(ends)-1]
+		}
+		if len(polygonEnds) != 0 {
+			endss = append(endss, polygonEnds)
+		}
+	}
+	switch len(endss) {
+	case 0:
+		return nil
+	case 1:
+		return geom.NewPolygonFlat(geom.XY, flatCoords, endss[0])
+	default:
+		return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss)
+	}
+}
+
+// roundXY rounds the flat XY coordinates to integers in place, removing
+// consecutive repeated points.
+func roundXY(xy []float64) []float64 {
+	rounded := xy[:0]
+	for i := 0; i < len(xy); i += 2 {
+		x, y := math.Floor(xy[i]+0.5), math.Floor(xy[i+1]+0.5)
+		if n := len(rounded); n != 0 && rounded[n-2] == x && rounded[n-1] == y {
+			continue
+		}
+		rounded = append(rounded, x, y)
+	}
+	return rounded
+}
+
+// withFlatCoordsXY returns a geometry of the same type as g with layout XY and
+// the given flat coordinates.
+func withFlatCoordsXY(g geom.T, flatCoords []float64) geom.T {
+	stride := g.Stride()
+	ends := make([]int, len(g.Ends()))
+	for i, end := range g.Ends() {
+		ends[i] = end / stride * 2
+	}
+	endss := make([][]int, len(g.Endss()))
+	for i, es := range g.Endss() {
+		endss[i] = make([]int, len(es))
+		for j, end := range es {
+			endss[i][j] = end / stride * 2
+		}
+	}
+	switch g.(type) {
+	case *geom.Point:
+		return geom.NewPointFlat(geom.XY, flatCoords)
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(geom.XY, flatCoords)
+	case *geom.LineString:
+		return geom.NewLineStringFlat(geom.XY, flatCoords)
+	case *geom.LinearRing:
+		return geom.NewLinearRingFlat(geom.XY, flatCoords)
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(geom.XY, flatCoords, ends)
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(geom.XY, flatCoords, ends)
+	case *geom.MultiPolygon:
+		return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss)
+	default:
+		return nil
+	}
+}
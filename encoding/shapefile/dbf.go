@@ -0,0 +1,323 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twpayne/go-geom/internal/property"
+)
+
+const (
+	dbfVersion          = 0x03
+	dbfHeaderSize       = 32
+	dbfFieldSize        = 32
+	dbfHeaderTerminator = 0x0d
+	dbfEOF              = 0x1a
+	dbfMaxNameLength    = 10
+	dbfMaxLength        = 254
+	dbfDateFormat       = "20060102"
+)
+
+// A FieldType is a dBASE field type.
+type FieldType byte
+
+// Field types.
+const (
+	FieldTypeCharacter FieldType = 'C'
+	FieldTypeDate      FieldType = 'D'
+	FieldTypeFloat     FieldType = 'F'
+	FieldTypeLogical   FieldType = 'L'
+	FieldTypeNumeric   FieldType = 'N'
+)
+
+// A Field describes a property.  Character fields are decoded as strings,
+// date fields as time.Times, logical fields as bools, numeric fields with no
+// decimals as int64s, and float fields and numeric fields with decimals as
+// float64s.  Blank values are decoded as nil.
+type Field struct {
+	Name     string
+	Type     FieldType
+	Length   int
+	Decimals int
+}
+
+// An ErrInvalidField is returned when a field cannot be encoded.
+type ErrInvalidField Field
+
+func (e ErrInvalidField) Error() string {
+	return fmt.Sprintf("shapefile: %s: invalid field type %q, length %d, decimals %d", e.Name, byte(e.Type), e.Length, e.Decimals)
+}
+
+// An ErrInvalidValue is returned when a property value cannot be encoded in
+// its field, or a field value cannot be decoded.
+type ErrInvalidValue struct {
+	Name  string
+	Value interface{}
+}
+
+func (e ErrInvalidValue) Error() string {
+	return fmt.Sprintf("shapefile: %s: invalid value %v", e.Name, e.Value)
+}
+
+// readDBF reads the fields and records of a .dbf file.
+func readDBF(r io.Reader) ([]Field, []map[string]interface{}, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) < dbfHeaderSize {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	numRecords := int(binary.LittleEndian.Uint32(data[4:]))
+	headerSize := int(binary.LittleEndian.Uint16(data[8:]))
+	recordSize := int(binary.LittleEndian.Uint16(data[10:]))
+	if recordSize < 1 || len(data) < headerSize || (len(data)-headerSize)/recordSize < numRecords {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+
+	var fields []Field
+	var offsets []int
+	offset := 1 // the deletion flag
+	for i := dbfHeaderSize; i+dbfFieldSize <= headerSize && data[i] != dbfHeaderTerminator; i += dbfFieldSize {
+		descriptor := data[i : i+dbfFieldSize]
+		name := descriptor[:11]
+		if j := bytes.IndexByte(name, 0); j != -1 {
+			name = name[:j]
+		}
+		f := Field{
+			Name:     string(name),
+			Type:     FieldType(descriptor[11]),
+			Length:   int(descriptor[16]),
+			Decimals: int(descriptor[17]),
+		}
+		fields = append(fields, f)
+		offsets = append(offsets, offset)
+		offset += f.Length
+	}
+	if offset > recordSize {
+		return nil, nil, ErrInvalidField(fields[len(fields)-1])
+	}
+
+	records := make([]map[string]interface{}, numRecords)
+	for i := range records {
+		record := data[headerSize+i*recordSize : headerSize+(i+1)*recordSize]
+		properties := make(map[string]interface{}, len(fields))
+		for j, f := range fields {
+			value, err := decodeValue(f, record[offsets[j]:offsets[j]+f.Length])
+			if err != nil {
+				return nil, nil, err
+			}
+			properties[f.Name] = value
+		}
+		records[i] = properties
+	}
+	return fields, records, nil
+}
+
+// decodeValue decodes the value of field f in data.
+func decodeValue(f Field, data []byte) (interface{}, error) {
+	s := strings.TrimRight(string(data), " \x00")
+	if f.Type == FieldTypeCharacter {
+		return s, nil
+	}
+	s = strings.TrimLeft(s, " ")
+	if s == "" {
+		return nil, nil
+	}
+	switch f.Type {
+	case FieldTypeDate:
+		t, err := time.Parse(dbfDateFormat, s)
+		if err != nil {
+			return nil, ErrInvalidValue{Name: f.Name, Value: s}
+		}
+		return t, nil
+	case FieldTypeLogical:
+		switch s {
+		case "T", "t", "Y", "y":
+			return true, nil
+		case "F", "f", "N", "n":
+			return false, nil
+		default:
+			return nil, nil
+		}
+	case FieldTypeNumeric, FieldTypeFloat:
+		// asterisks indicate a value that did not fit in the field
+		if s[0] == '*' {
+			return nil, nil
+		}
+		if f.Type == FieldTypeNumeric && f.Decimals == 0 {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, ErrInvalidValue{Name: f.Name, Value: s}
+		}
+		return x, nil
+	default:
+		return s, nil
+	}
+}
+
+// writeDBF writes the properties of features to a .dbf file.
+func writeDBF(w io.Writer, fields []Field, features []*Feature) error {
+	recordSize := 1
+	for _, f := range fields {
+		if len(f.Name) == 0 || len(f.Name) > dbfMaxNameLength || f.Length <= 0 || f.Length > dbfMaxLength || f.Decimals < 0 || f.Decimals > f.Length {
+			return ErrInvalidField(f)
+		}
+		switch f.Type {
+		case FieldTypeCharacter, FieldTypeDate, FieldTypeFloat, FieldTypeLogical, FieldTypeNumeric:
+		default:
+			return ErrInvalidField(f)
+		}
+		recordSize += f.Length
+	}
+	headerSize := dbfHeaderSize + dbfFieldSize*len(fields) + 1
+	if recordSize > 0xffff || headerSize > 0xffff {
+		return ErrInvalidField(fields[len(fields)-1])
+	}
+
+	data := make([]byte, headerSize, headerSize+len(features)*recordSize+1)
+	// the last update date is left unset so that output is reproducible
+	data[0] = dbfVersion
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(features)))
+	binary.LittleEndian.PutUint16(data[8:], uint16(headerSize))
+	binary.LittleEndian.PutUint16(data[10:], uint16(recordSize))
+	for i, f := range fields {
+		descriptor := data[dbfHeaderSize+i*dbfFieldSize:]
+		copy(descriptor[:11], f.Name)
+		descriptor[11] = byte(f.Type)
+		descriptor[16] = byte(f.Length)
+		descriptor[17] = byte(f.Decimals)
+	}
+	data[headerSize-1] = dbfHeaderTerminator
+
+	for _, feature := range features {
+		data = append(data, ' ')
+		for _, f := range fields {
+			s, err := encodeValue(f, feature.Properties[f.Name])
+			if err != nil {
+				return err
+			}
+			if len(s) > f.Length {
+				return ErrInvalidValue{Name: f.Name, Value: feature.Properties[f.Name]}
+			}
+			// numbers are right-aligned, everything else is left-aligned
+			padding := strings.Repeat(" ", f.Length-len(s))
+			if f.Type == FieldTypeNumeric || f.Type == FieldTypeFloat {
+				s = padding + s
+			} else {
+				s += padding
+			}
+			data = append(data, s...)
+		}
+	}
+	data = append(data, dbfEOF)
+
+	_, err := w.Write(data)
+	return err
+}
+
+// encodeValue encodes value in field f, without padding.
+func encodeValue(f Field, value interface{}) (string, error) {
+	if value == nil {
+		if f.Type == FieldTypeLogical {
+			return "?", nil
+		}
+		return "", nil
+	}
+	switch f.Type {
+	case FieldTypeCharacter:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case FieldTypeDate:
+		if t, ok := value.(time.Time); ok {
+			return t.Format(dbfDateFormat), nil
+		}
+	case FieldTypeLogical:
+		if b, ok := value.(bool); ok {
+			if b {
+				return "T", nil
+			}
+			return "F", nil
+		}
+	case FieldTypeNumeric, FieldTypeFloat:
+		if i, ok := property.ToInt64(value); ok {
+			return strconv.FormatInt(i, 10), nil
+		}
+		var x float64
+		switch value := value.(type) {
+		case float32:
+			x = float64(value)
+		case float64:
+			x = value
+		default:
+			return "", ErrInvalidValue{Name: f.Name, Value: value}
+		}
+		// fall back to the shortest representation if the value does not
+		// fit with the field's number of decimals
+		if s := strconv.FormatFloat(x, 'f', f.Decimals, 64); len(s) <= f.Length {
+			return s, nil
+		}
+		return strconv.FormatFloat(x, 'g', -1, 64), nil
+	}
+	return "", ErrInvalidValue{Name: f.Name, Value: value}
+}
+
+// inferFields returns fields for all the properties of features, sorted by
+// name.
+func inferFields(features []*Feature) ([]Field, error) {
+	fields := make(map[string]Field)
+	var names []string
+	for _, feature := range features {
+		for name, value := range feature.Properties {
+			if value == nil {
+				continue
+			}
+			f, ok := fields[name]
+			if !ok {
+				f.Name = name
+				switch value.(type) {
+				case string:
+					f.Type = FieldTypeCharacter
+					f.Length = 1
+				case time.Time:
+					f.Type, f.Length = FieldTypeDate, 8
+				case bool:
+					f.Type, f.Length = FieldTypeLogical, 1
+				case float32, float64:
+					f.Type, f.Length, f.Decimals = FieldTypeNumeric, 24, 15
+				default:
+					if _, ok := property.ToInt64(value); !ok {
+						return nil, ErrInvalidValue{Name: name, Value: value}
+					}
+					f.Type, f.Length = FieldTypeNumeric, 18
+				}
+				names = append(names, name)
+			}
+			if s, ok := value.(string); ok && f.Type == FieldTypeCharacter && len(s) > f.Length {
+				f.Length = len(s)
+				if f.Length > dbfMaxLength {
+					return nil, ErrInvalidValue{Name: name, Value: value}
+				}
+			}
+			fields[name] = f
+		}
+	}
+	sort.Strings(names)
+	result := make([]Field, len(names))
+	for i, name := range names {
+		result[i] = fields[name]
+	}
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package shapefile
+
+import (
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+)
+
+// A ring is a ring of a decoded polygon.
+type ring struct {
+	flatCoords []float64
+	area       float64
+}
+
+// decodePolygon returns the MultiPolygon encoded by the rings in flatCoords.
+// Clockwise rings are exterior rings.  Counter-clockwise rings are interior
+// rings of the smallest exterior ring that contains them, or exterior rings if
+// no exterior ring contains them.  Rings are reversed so that exterior rings
+// are counter-clockwise and interior rings clockwise.
+func decodePolygon(layout geom.Layout, flatCoords []float64, ends []int) *geom.MultiPolygon {
+	stride := layout.Stride()
+	var exteriors, interiors []*ring
+	offset := 0
+	for _, end := range ends {
+		r := &ring{
+			flatCoords: flatCoords[offset:end],
+			area:       signedArea(flatCoords[offset:end], stride),
+		}
+		if r.area < 0 {
+			flat.Reverse(r.flatCoords, stride)
+			r.area = -r.area
+			exteriors = append(exteriors, r)
+		} else {
+			interiors = append(interiors, r)
+		}
+		offset = end
+	}
+
+	holes := make([][]*ring, len(exteriors))
+	for _, interior := range interiors {
+		best := -1
+		for i, exterior := range exteriors {
+			if (best == -1 || exterior.area < exteriors[best].area) && ringContainsRing(exterior.flatCoords, interior.flatCoords, stride) {
+				best = i
+			}
+		}
+		if best == -1 {
+			exteriors = append(exteriors, interior)
+			holes = append(holes, nil)
+			continue
+		}
+		flat.Reverse(interior.flatCoords, stride)
+		holes[best] = append(holes[best], interior)
+	}
+
+	mpFlatCoords := make([]float64, 0, len(flatCoords))
+	endss := make([][]int, len(exteriors))
+	for i, exterior := range exteriors {
+		mpFlatCoords = append(mpFlatCoords, exterior.flatCoords...)
+		endss[i] = append(endss[i], len(mpFlatCoords))
+		for _, hole := range holes[i] {
+			mpFlatCoords = append(mpFlatCoords, hole.flatCoords...)
+			endss[i] = append(endss[i], len(mpFlatCoords))
+		}
+	}
+	return geom.NewMultiPolygonFlat(layout, mpFlatCoords, endss)
+}
+
+// encodePolygon returns the rings of the polygons described by flatCoords
+// and endss, reversed as necessary so that exterior rings are clockwise and
+// interior rings are counter-clockwise.
+func encodePolygon(flatCoords []float64, stride int, endss [][]int) ([]float64, []int) {
+	result := make([]float64, 0, len(flatCoords))
+	var resultEnds []int
+	offset := 0
+	for _, ends := range endss {
+		for i, end := range ends {
+			start := len(result)
+			result = append(result, flatCoords[offset:end]...)
+			area := signedArea(result[start:], stride)
+			if i == 0 && area > 0 || i > 0 && area < 0 {
+				flat.Reverse(result[start:], stride)
+			}
+			resultEnds = append(resultEnds, len(result))
+			offset = end
+		}
+	}
+	return result, resultEnds
+}
+
+// signedArea returns the signed area of the ring in flatCoords, which is
+// positive if the ring is counter-clockwise.
+func signedArea(flatCoords []float64, stride int) float64 {
+	n := len(flatCoords)
+	if n == 0 {
+		return 0
+	}
+	var area float64
+	for i, j := 0, n-stride; i < n; j, i = i, i+stride {
+		area += flatCoords[j]*flatCoords[i+1] - flatCoords[i]*flatCoords[j+1]
+	}
+	return area / 2
+}
+
+// ringContainsRing returns true if the ring in outer contains the ring in
+// inner, tested using the first coordinate of inner that is not a coordinate
+// of outer.
+func ringContainsRing(outer, inner []float64, stride int) bool {
+	for i := 0; i < len(inner); i += stride {
+		x, y := inner[i], inner[i+1]
+		if !hasCoord(outer, stride, x, y) {
+			return ringContainsPoint(outer, stride, x, y)
+		}
+	}
+	return false
+}
+
+// hasCoord returns true if flatCoords contains a coordinate at (x, y).
+func hasCoord(flatCoords []float64, stride int, x, y float64) bool {
+	for i := 0; i < len(flatCoords); i += stride {
+		if flatCoords[i] == x && flatCoords[i+1] == y {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContainsPoint returns true if the ring in flatCoords contains (x, y),
+// using the even-odd rule.
+func ringContainsPoint(flatCoords []float64, stride int, x, y float64) bool {
+	n := len(flatCoords)
+	inside := false
+	for i, j := 0, n-stride; i < n; j, i = i, i+stride {
+		xi, yi := flatCoords[i], flatCoords[i+1]
+		xj, yj := flatCoords[j], flatCoords[j+1]
+		if (yi > y) != (yj > y) && x < xi+(y-yi)*(xj-xi)/(yj-yi) {
+			inside = !inside
+		}
+	}
+	return inside
+}
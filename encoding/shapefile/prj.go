@@ -0,0 +1,69 @@
+package shapefile
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// prjs are the Esri WKT coordinate reference systems written to .prj files,
+// by SRID.
+var prjs = map[int]string{
+	4326: `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`,
+	3857: `PROJCS["WGS_1984_Web_Mercator_Auxiliary_Sphere",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Mercator_Auxiliary_Sphere"],PARAMETER["False_Easting",0.0],PARAMETER["False_Northing",0.0],PARAMETER["Central_Meridian",0.0],PARAMETER["Standard_Parallel_1",0.0],PARAMETER["Auxiliary_Sphere_Type",0.0],UNIT["Meter",1.0]]`,
+}
+
+var (
+	prjNameRegexp      = regexp.MustCompile(`^\s*(?:GEOGCS|PROJCS)\["([^"]*)"`)
+	prjAuthorityRegexp = regexp.MustCompile(`^AUTHORITY\["EPSG",\s*"?(\d+)"?\]`)
+)
+
+// An ErrUnknownSRID is returned when a .prj file cannot be written for an
+// SRID.
+type ErrUnknownSRID int
+
+func (e ErrUnknownSRID) Error() string {
+	return fmt.Sprintf("shapefile: unknown SRID %d", int(e))
+}
+
+// sridFromPRJ returns the SRID of the coordinate reference system in wkt, the
+// contents of a .prj file, or zero if it is not recognized.  The SRID is taken
+// from the EPSG authority of the outermost coordinate reference system if
+// present, otherwise the coordinate reference system's name is compared with
+// the names of known Esri coordinate reference systems.
+func sridFromPRJ(wkt string) int {
+	depth := 0
+	for i := 0; i < len(wkt); i++ {
+		switch wkt[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '"':
+			// skip quoted strings
+			if j := strings.IndexByte(wkt[i+1:], '"'); j != -1 {
+				i += j + 1
+			}
+		case 'A':
+			if depth != 1 {
+				continue
+			}
+			if m := prjAuthorityRegexp.FindStringSubmatch(wkt[i:]); m != nil {
+				if srid, err := strconv.Atoi(m[1]); err == nil {
+					return srid
+				}
+			}
+		}
+	}
+	m := prjNameRegexp.FindStringSubmatch(wkt)
+	if m == nil {
+		return 0
+	}
+	for srid, prj := range prjs {
+		if prjNameRegexp.FindStringSubmatch(prj)[1] == m[1] {
+			return srid
+		}
+	}
+	return 0
+}
@@ -0,0 +1,393 @@
+// Package shapefile implements Esri Shapefile encoding and decoding.
+//
+// A shapefile is a set of files with the same base name: the .shp file
+// contains the geometries, the .shx file an index of the geometries, the .dbf
+// file the attributes, and the optional .prj file the coordinate reference
+// system.
+//
+// Geometries are decoded as follows:
+//
+//	Point, PointM, PointZ                 *geom.Point
+//	MultiPoint, MultiPointM, MultiPointZ  *geom.MultiPoint
+//	PolyLine, PolyLineM, PolyLineZ        *geom.MultiLineString
+//	Polygon, PolygonM, PolygonZ           *geom.MultiPolygon
+//
+// The M shape types are decoded with layout geom.XYM.  The Z shape types are
+// decoded with layout geom.XYZM if they contain any measures, and geom.XYZ
+// otherwise.  Measures that are "no data" are decoded as NaN.
+//
+// Shapefile polygons have clockwise exterior rings and counter-clockwise
+// interior rings.  Decoded polygons have counter-clockwise exterior rings and
+// clockwise interior rings, as in the OGC Simple Features specification, with
+// each interior ring assigned to the exterior ring that contains it.  Encoding
+// reverses rings as needed.
+//
+// See https://www.esri.com/library/whitepapers/pdfs/shapefile.pdf.
+package shapefile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/geomutil"
+)
+
+const (
+	fileCode   = 9994
+	version    = 1000
+	headerSize = 100
+)
+
+// A ShapeType is a shape type.
+type ShapeType int32
+
+// Shape types.
+const (
+	ShapeTypeNull        ShapeType = 0
+	ShapeTypePoint       ShapeType = 1
+	ShapeTypePolyLine    ShapeType = 3
+	ShapeTypePolygon     ShapeType = 5
+	ShapeTypeMultiPoint  ShapeType = 8
+	ShapeTypePointZ      ShapeType = 11
+	ShapeTypePolyLineZ   ShapeType = 13
+	ShapeTypePolygonZ    ShapeType = 15
+	ShapeTypeMultiPointZ ShapeType = 18
+	ShapeTypePointM      ShapeType = 21
+	ShapeTypePolyLineM   ShapeType = 23
+	ShapeTypePolygonM    ShapeType = 25
+	ShapeTypeMultiPointM ShapeType = 28
+)
+
+// An ErrBadFileCode is returned when a .shp or .shx file does not start with
+// the shapefile file code.
+type ErrBadFileCode int32
+
+func (e ErrBadFileCode) Error() string {
+	return fmt.Sprintf("shapefile: bad file code %d", int32(e))
+}
+
+// An ErrUnsupportedShapeType is returned when a shape type is not supported.
+type ErrUnsupportedShapeType ShapeType
+
+func (e ErrUnsupportedShapeType) Error() string {
+	return fmt.Sprintf("shapefile: unsupported shape type %d", int32(e))
+}
+
+// An ErrShapeTypeMismatch is returned when a geometry does not match the
+// shape type of a file.
+type ErrShapeTypeMismatch struct {
+	Got  ShapeType
+	Want ShapeType
+}
+
+func (e ErrShapeTypeMismatch) Error() string {
+	return fmt.Sprintf("shapefile: shape type mismatch, got %d, want %d", int32(e.Got), int32(e.Want))
+}
+
+// An ErrRecordCountMismatch is returned when the .shp and .dbf files contain
+// different numbers of records.
+type ErrRecordCountMismatch struct {
+	SHP int
+	DBF int
+}
+
+func (e ErrRecordCountMismatch) Error() string {
+	return fmt.Sprintf("shapefile: record count mismatch, .shp has %d, .dbf has %d", e.SHP, e.DBF)
+}
+
+// A Header describes the features in a shapefile.
+type Header struct {
+	ShapeType ShapeType
+	// Bounds is the XY bounds of all geometries.
+	Bounds *geom.Bounds
+	SRID   int
+	Fields []Field
+}
+
+// A Feature is a geometry with properties.
+type Feature struct {
+	// Geometry is nil for null shapes.
+	Geometry   geom.T
+	Properties map[string]interface{}
+}
+
+// Read reads a shapefile.  shx, dbf, and prj may be nil.  If shx is not nil
+// then it is used to locate the records in shp.  If dbf is nil then features
+// have no properties.  If prj is nil, or the coordinate reference system is
+// not recognized, then the SRID is zero.  The SRID of each decoded geometry is
+// set to the SRID in the returned Header.
+func Read(shp, shx, dbf, prj io.Reader) (*Header, []*Feature, error) {
+	shpData, err := ioutil.ReadAll(shp)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := decodeHeader(shpData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var contents [][]byte
+	if shx != nil {
+		shxData, err := ioutil.ReadAll(shx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if contents, err = indexedRecords(shpData, shxData); err != nil {
+			return nil, nil, err
+		}
+	} else if contents, err = records(shpData); err != nil {
+		return nil, nil, err
+	}
+
+	features := make([]*Feature, len(contents))
+	for i, content := range contents {
+		g, err := decodeShape(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		features[i] = &Feature{Geometry: g}
+	}
+
+	if dbf != nil {
+		fields, properties, err := readDBF(dbf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(properties) != len(features) {
+			return nil, nil, ErrRecordCountMismatch{SHP: len(features), DBF: len(properties)}
+		}
+		h.Fields = fields
+		for i, f := range features {
+			f.Properties = properties[i]
+		}
+	}
+
+	if prj != nil {
+		prjData, err := ioutil.ReadAll(prj)
+		if err != nil {
+			return nil, nil, err
+		}
+		h.SRID = sridFromPRJ(string(prjData))
+		for _, f := range features {
+			if f.Geometry == nil {
+				continue
+			}
+			if f.Geometry, err = geomutil.SetSRID(f.Geometry, h.SRID); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return h, features, nil
+}
+
+// ReadFiles reads the shapefile with the given base name, i.e. without
+// extension.  The .shx and .prj files are optional.
+func ReadFiles(basename string) (*Header, []*Feature, error) {
+	var readers [4]io.Reader
+	for i, ext := range []string{".shp", ".shx", ".dbf", ".prj"} {
+		f, err := os.Open(basename + ext)
+		switch {
+		case os.IsNotExist(err) && (ext == ".shx" || ext == ".prj"):
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+		defer f.Close()
+		readers[i] = f
+	}
+	return Read(readers[0], readers[1], readers[2], readers[3])
+}
+
+// Write writes features as a shapefile.  If h.ShapeType is ShapeTypeNull then
+// it is inferred from the first non-nil geometry.  If h.Fields is nil then the
+// fields are inferred from the features' properties.  prj may be nil, and is
+// not written if h.SRID is zero.  h.Bounds is ignored.
+func Write(shp, shx, dbf, prj io.Writer, h *Header, features []*Feature) error {
+	shapeType := h.ShapeType
+	if shapeType == ShapeTypeNull {
+		for _, f := range features {
+			if f.Geometry == nil {
+				continue
+			}
+			var err error
+			if shapeType, err = shapeTypeOf(f.Geometry); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	e := newExtent()
+	contents := make([][]byte, len(features))
+	for i, f := range features {
+		content, err := encodeShape(f.Geometry, shapeType)
+		if err != nil {
+			return err
+		}
+		contents[i] = content
+		if f.Geometry != nil {
+			e.extend(f.Geometry)
+		}
+	}
+
+	// write the .shp and .shx files
+	shpLength := headerSize
+	for _, content := range contents {
+		shpLength += 8 + len(content)
+	}
+	shxLength := headerSize + 8*len(contents)
+	shpData := make([]byte, headerSize, shpLength)
+	shxData := make([]byte, headerSize, shxLength)
+	encodeHeader(shpData, shapeType, shpLength, e)
+	encodeHeader(shxData, shapeType, shxLength, e)
+	for i, content := range contents {
+		var record [8]byte
+		binary.BigEndian.PutUint32(record[:4], uint32(len(shpData)/2))
+		binary.BigEndian.PutUint32(record[4:], uint32(len(content)/2))
+		shxData = append(shxData, record[:]...)
+		binary.BigEndian.PutUint32(record[:4], uint32(i+1))
+		shpData = append(shpData, record[:]...)
+		shpData = append(shpData, content...)
+	}
+	if _, err := shp.Write(shpData); err != nil {
+		return err
+	}
+	if _, err := shx.Write(shxData); err != nil {
+		return err
+	}
+
+	fields := h.Fields
+	if fields == nil {
+		var err error
+		if fields, err = inferFields(features); err != nil {
+			return err
+		}
+	}
+	if err := writeDBF(dbf, fields, features); err != nil {
+		return err
+	}
+
+	if prj == nil || h.SRID == 0 {
+		return nil
+	}
+	wkt, ok := prjs[h.SRID]
+	if !ok {
+		return ErrUnknownSRID(h.SRID)
+	}
+	_, err := io.WriteString(prj, wkt)
+	return err
+}
+
+// WriteFiles writes features as a shapefile with the given base name, i.e.
+// without extension.  A .prj file is written if h.SRID is not zero.
+func WriteFiles(basename string, h *Header, features []*Feature) (err error) {
+	exts := []string{".shp", ".shx", ".dbf"}
+	if h.SRID != 0 {
+		exts = append(exts, ".prj")
+	}
+	var writers [4]io.Writer
+	for i, ext := range exts {
+		f, createErr := os.Create(basename + ext)
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		writers[i] = f
+	}
+	return Write(writers[0], writers[1], writers[2], writers[3], h, features)
+}
+
+// decodeHeader decodes the header of a .shp file.
+func decodeHeader(data []byte) (*Header, error) {
+	if len(data) < headerSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if code := int32(binary.BigEndian.Uint32(data)); code != fileCode {
+		return nil, ErrBadFileCode(code)
+	}
+	h := &Header{
+		ShapeType: ShapeType(binary.LittleEndian.Uint32(data[32:])),
+	}
+	var bbox [4]float64
+	for i := range bbox {
+		bbox[i] = float64frombits(data[36+8*i:])
+	}
+	h.Bounds = geom.NewBounds(geom.XY).Set(bbox[:]...)
+	return h, nil
+}
+
+// encodeHeader encodes the header of a .shp or .shx file of length bytes into
+// data.
+func encodeHeader(data []byte, shapeType ShapeType, length int, e *extent) {
+	binary.BigEndian.PutUint32(data, fileCode)
+	binary.BigEndian.PutUint32(data[24:], uint32(length/2))
+	binary.LittleEndian.PutUint32(data[28:], version)
+	binary.LittleEndian.PutUint32(data[32:], uint32(shapeType))
+	minX, maxX := e.bounds(0)
+	minY, maxY := e.bounds(1)
+	minZ, maxZ := e.bounds(2)
+	minM, maxM := e.bounds(3)
+	for i, x := range []float64{minX, minY, maxX, maxY, minZ, maxZ, minM, maxM} {
+		putFloat64(data[36+8*i:], x)
+	}
+}
+
+// records returns the contents of the records in data, a .shp file.
+func records(data []byte) ([][]byte, error) {
+	var contents [][]byte
+	for offset := headerSize; offset < len(data); {
+		content, err := record(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, content)
+		offset += 8 + len(content)
+	}
+	return contents, nil
+}
+
+// indexedRecords returns the contents of the records in shpData, a .shp file,
+// located using shxData, its .shx file.
+func indexedRecords(shpData, shxData []byte) ([][]byte, error) {
+	if len(shxData) < headerSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if code := int32(binary.BigEndian.Uint32(shxData)); code != fileCode {
+		return nil, ErrBadFileCode(code)
+	}
+	index := shxData[headerSize:]
+	if len(index)%8 != 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	contents := make([][]byte, len(index)/8)
+	for i := range contents {
+		offset := 2 * int(binary.BigEndian.Uint32(index[8*i:]))
+		content, err := record(shpData, offset)
+		if err != nil {
+			return nil, err
+		}
+		contents[i] = content
+	}
+	return contents, nil
+}
+
+// record returns the content of the record at offset in data.
+func record(data []byte, offset int) ([]byte, error) {
+	if offset < headerSize || offset+8 > len(data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	end := offset + 8 + 2*int(binary.BigEndian.Uint32(data[offset+4:]))
+	if end < offset+8 || end > len(data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data[offset+8 : end], nil
+}
@@ -0,0 +1,325 @@
+package shapefile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/twpayne/go-geom"
+)
+
+func writeAndRead(t *testing.T, h *Header, features []*Feature) (*Header, []*Feature) {
+	var shp, shx, dbf, prj bytes.Buffer
+	if err := Write(&shp, &shx, &dbf, &prj, h, features); err != nil {
+		t.Fatalf("Write(...) == %v, want <nil>", err)
+	}
+	gotH, gotFeatures, err := Read(bytes.NewReader(shp.Bytes()), bytes.NewReader(shx.Bytes()), bytes.NewReader(dbf.Bytes()), bytes.NewReader(prj.Bytes()))
+	if err != nil {
+		t.Fatalf("Read(...) == %v, want <nil>", err)
+	}
+	// reading without the index gives the same result, compared as strings
+	// because missing measures are NaN
+	_, unindexedFeatures, err := Read(bytes.NewReader(shp.Bytes()), nil, bytes.NewReader(dbf.Bytes()), nil)
+	if got, want := featuresString(unindexedFeatures), featuresString(gotFeatures); err != nil || got != want {
+		t.Errorf("Read(..., nil, ...) == %s, %v, want %s, <nil>", got, err, want)
+	}
+	return gotH, gotFeatures
+}
+
+func featuresString(features []*Feature) string {
+	var b bytes.Buffer
+	for _, f := range features {
+		if f.Geometry != nil {
+			fmt.Fprintf(&b, "%d %v %v ", f.Geometry.Layout(), f.Geometry.FlatCoords(), f.Geometry.Ends())
+		}
+		fmt.Fprintf(&b, "%v\n", f.Properties)
+	}
+	return b.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{
+			g: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			g: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+		},
+		{
+			g: geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}),
+		},
+		{
+			g: geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+		},
+		{
+			g: geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+		},
+		{
+			g: geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		},
+		{
+			g: geom.NewMultiPointFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4}, []int{4}),
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, []int{6, 12}),
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, []int{6, 12}),
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 3, 0, 3, 3, 0, 3, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1}, []int{10, 20}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 3, 0, 3, 3, 0, 3, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1}, [][]int{{10, 20}}),
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XYZM, []float64{
+				0, 0, 1, 2, 1, 0, 1, 2, 1, 1, 1, 2, 0, 0, 1, 2,
+				5, 5, 1, 2, 6, 5, 1, 2, 6, 6, 1, 2, 5, 5, 1, 2,
+			}, [][]int{{16}, {32}}),
+		},
+	} {
+		want := tc.want
+		if want == nil {
+			want = tc.g
+		}
+		h, features := writeAndRead(t, &Header{}, []*Feature{{Geometry: tc.g}})
+		wantShapeType, err := shapeTypeOf(tc.g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.ShapeType != wantShapeType {
+			t.Errorf("%d: got shape type %d, want %d", i, h.ShapeType, wantShapeType)
+		}
+		wantBounds := geom.NewBounds(geom.XY).Set(want.Bounds().Min(0), want.Bounds().Min(1), want.Bounds().Max(0), want.Bounds().Max(1))
+		if !reflect.DeepEqual(h.Bounds, wantBounds) {
+			t.Errorf("%d: got bounds %v, want %v", i, h.Bounds, wantBounds)
+		}
+		if len(features) != 1 || !reflect.DeepEqual(features[0].Geometry, want) {
+			t.Errorf("%d: got %#v, want %#v", i, features[0].Geometry, want)
+		}
+	}
+}
+
+func TestMissingMeasures(t *testing.T) {
+	_, features := writeAndRead(t, &Header{}, []*Feature{
+		{Geometry: geom.NewMultiPointFlat(geom.XYM, []float64{1, 2, math.NaN(), 3, 4, 5})},
+	})
+	mp := features[0].Geometry.(*geom.MultiPoint)
+	if mp.Layout() != geom.XYM || !math.IsNaN(mp.FlatCoords()[2]) || mp.FlatCoords()[5] != 5 {
+		t.Errorf("got %v, want [1 2 NaN 3 4 5]", mp.FlatCoords())
+	}
+	_, features = writeAndRead(t, &Header{}, []*Feature{
+		{Geometry: geom.NewPointFlat(geom.XYM, []float64{1, 2, math.NaN()})},
+	})
+	if p := features[0].Geometry.(*geom.Point); !math.IsNaN(p.M()) {
+		t.Errorf("got %v, want [1 2 NaN]", p.FlatCoords())
+	}
+}
+
+func TestPolygonOrientation(t *testing.T) {
+	// OGC orientation: counter-clockwise exterior, clockwise interior
+	p := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1}, []int{10, 20})
+	content, err := encodeShape(p, ShapeTypePolygon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &shapeDecoder{data: content[44+2*4:]}
+	flatCoords := make([]float64, 20)
+	if err := d.float64s(flatCoords, 0, 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if signedArea(flatCoords[:10], 2) >= 0 || signedArea(flatCoords[10:], 2) <= 0 {
+		t.Errorf("encodeShape(...) wrote rings %v, want clockwise exterior and counter-clockwise interior", flatCoords)
+	}
+
+	// holes are assigned to the smallest exterior ring that contains them,
+	// wherever they appear
+	got := decodePolygon(geom.XY, []float64{
+		0, 0, 0, 10, 10, 10, 10, 0, 0, 0, // clockwise, contains the hole and the inner polygon
+		3, 3, 3, 7, 7, 7, 7, 3, 3, 3, // clockwise, inside the first ring, contains the hole
+		20, 20, 20, 21, 21, 21, 21, 20, 20, 20, // clockwise, disjoint
+		4, 4, 6, 4, 6, 6, 4, 6, 4, 4, // counter-clockwise hole
+		30, 30, 31, 30, 31, 31, 30, 31, 30, 30, // counter-clockwise, not contained
+	}, []int{10, 20, 30, 40, 50})
+	want := geom.NewMultiPolygonFlat(geom.XY, []float64{
+		0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+		3, 3, 7, 3, 7, 7, 3, 7, 3, 3,
+		4, 4, 4, 6, 6, 6, 6, 4, 4, 4,
+		20, 20, 21, 20, 21, 21, 20, 21, 20, 20,
+		30, 30, 31, 30, 31, 31, 30, 31, 30, 30,
+	}, [][]int{{10}, {20, 30}, {40}, {50}})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodePolygon(...) == %v, want %v", got.Endss(), want.Endss())
+	}
+}
+
+func TestProperties(t *testing.T) {
+	date := time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)
+	features := []*Feature{
+		{
+			Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			Properties: map[string]interface{}{
+				"name":    "London",
+				"pop":     8982000,
+				"area":    1572.15,
+				"big":     1.5e200,
+				"capital": true,
+				"founded": date,
+			},
+		},
+		{
+			Properties: map[string]interface{}{
+				"name": "Nowhere",
+			},
+		},
+	}
+	h, got := writeAndRead(t, &Header{SRID: 4326}, features)
+	wantFields := []Field{
+		{Name: "area", Type: FieldTypeNumeric, Length: 24, Decimals: 15},
+		{Name: "big", Type: FieldTypeNumeric, Length: 24, Decimals: 15},
+		{Name: "capital", Type: FieldTypeLogical, Length: 1},
+		{Name: "founded", Type: FieldTypeDate, Length: 8},
+		{Name: "name", Type: FieldTypeCharacter, Length: 7},
+		{Name: "pop", Type: FieldTypeNumeric, Length: 18},
+	}
+	if !reflect.DeepEqual(h.Fields, wantFields) {
+		t.Errorf("got fields %v, want %v", h.Fields, wantFields)
+	}
+	if h.SRID != 4326 {
+		t.Errorf("got SRID %d, want 4326", h.SRID)
+	}
+	wantProperties := []map[string]interface{}{
+		{"name": "London", "pop": int64(8982000), "area": 1572.15, "big": 1.5e200, "capital": true, "founded": date},
+		{"name": "Nowhere", "pop": nil, "area": nil, "big": nil, "capital": nil, "founded": nil},
+	}
+	for i, f := range got {
+		if !reflect.DeepEqual(f.Properties, wantProperties[i]) {
+			t.Errorf("%d: got properties %v, want %v", i, f.Properties, wantProperties[i])
+		}
+	}
+	if got[1].Geometry != nil {
+		t.Errorf("got geometry %v, want <nil>", got[1].Geometry)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shapefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	basename := filepath.Join(dir, "test")
+	features := []*Feature{
+		{
+			Geometry:   geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(3857),
+			Properties: map[string]interface{}{"name": "a"},
+		},
+	}
+	if err := WriteFiles(basename, &Header{SRID: 3857}, features); err != nil {
+		t.Fatalf("WriteFiles(...) == %v, want <nil>", err)
+	}
+	h, got, err := ReadFiles(basename)
+	if err != nil {
+		t.Fatalf("ReadFiles(...) == %v, want <nil>", err)
+	}
+	if h.SRID != 3857 || !reflect.DeepEqual(got, features) {
+		t.Errorf("ReadFiles(...) == %v, %v, want SRID 3857, %v", h, got, features)
+	}
+}
+
+func TestSRIDFromPRJ(t *testing.T) {
+	for i, tc := range []struct {
+		wkt  string
+		want int
+	}{
+		{wkt: prjs[4326], want: 4326},
+		{wkt: prjs[3857], want: 3857},
+		{
+			wkt:  `PROJCS["OSGB 1936 / British National Grid",GEOGCS["OSGB 1936",DATUM["OSGB_1936",SPHEROID["Airy 1830",6377563.396,299.3249646,AUTHORITY["EPSG","7001"]],AUTHORITY["EPSG","6277"]],PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433],AUTHORITY["EPSG","4277"]],PROJECTION["Transverse_Mercator"],UNIT["metre",1],AUTHORITY["EPSG","27700"]]`,
+			want: 27700,
+		},
+		{wkt: `GEOGCS["GCS_Unknown",DATUM["D_Unknown",SPHEROID["Unknown",6378137.0,298.257223563]]]`, want: 0},
+		{wkt: ``, want: 0},
+	} {
+		if got := sridFromPRJ(tc.wkt); got != tc.want {
+			t.Errorf("%d: sridFromPRJ(%q) == %d, want %d", i, tc.wkt, got, tc.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var shp, shx, dbf, prj bytes.Buffer
+	for i, tc := range []struct {
+		h        *Header
+		features []*Feature
+		want     error
+	}{
+		{
+			h: &Header{},
+			features: []*Feature{
+				{Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+				{Geometry: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3})},
+			},
+			want: ErrShapeTypeMismatch{Got: ShapeTypePointZ, Want: ShapeTypePoint},
+		},
+		{
+			h:        &Header{SRID: 1},
+			features: []*Feature{{Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})}},
+			want:     ErrUnknownSRID(1),
+		},
+		{
+			h:        &Header{},
+			features: []*Feature{{Properties: map[string]interface{}{"name": []int{}}}},
+			want:     ErrInvalidValue{Name: "name", Value: []int{}},
+		},
+		{
+			h:        &Header{Fields: []Field{{Name: "n", Type: FieldTypeNumeric, Length: 2}}},
+			features: []*Feature{{Properties: map[string]interface{}{"n": 100}}},
+			want:     ErrInvalidValue{Name: "n", Value: 100},
+		},
+		{
+			h:    &Header{Fields: []Field{{Name: "toolongname", Type: FieldTypeCharacter, Length: 1}}},
+			want: ErrInvalidField{Name: "toolongname", Type: FieldTypeCharacter, Length: 1},
+		},
+	} {
+		if err := Write(&shp, &shx, &dbf, &prj, tc.h, tc.features); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Write(...) == %v, want %v", i, err, tc.want)
+		}
+	}
+
+	shp.Reset()
+	shx.Reset()
+	dbf.Reset()
+	features := []*Feature{{Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})}}
+	if err := Write(&shp, &shx, &dbf, nil, &Header{}, features); err != nil {
+		t.Fatal(err)
+	}
+	var otherDBF bytes.Buffer
+	if err := writeDBF(&otherDBF, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	badSHP := append([]byte{0, 0, 0, 0}, shp.Bytes()[4:]...)
+	for i, tc := range []struct {
+		shp, dbf []byte
+		want     error
+	}{
+		{shp: badSHP, dbf: dbf.Bytes(), want: ErrBadFileCode(0)},
+		{shp: shp.Bytes(), dbf: otherDBF.Bytes(), want: ErrRecordCountMismatch{SHP: 1, DBF: 0}},
+	} {
+		if _, _, err := Read(bytes.NewReader(tc.shp), nil, bytes.NewReader(tc.dbf), nil); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Read(...) == %v, want %v", i, err, tc.want)
+		}
+	}
+}
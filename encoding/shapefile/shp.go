@@ -0,0 +1,451 @@
+package shapefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+var (
+	errInvalidParts = errors.New("shapefile: invalid parts")
+	errTooLarge     = errors.New("shapefile: geometry too large")
+)
+
+// noData is the value used to encode missing measures.  Any value less than
+// -1e38 is considered to be "no data".
+const noData = -1e39
+
+// isNoData returns true if m is a "no data" measure.
+func isNoData(m float64) bool {
+	return m < -1e38
+}
+
+// maxPoints is the maximum number of points in a shape.  The content length
+// of a record is a 32-bit count of 16-bit words, so a shape cannot contain
+// more than 2^32 bytes.
+const maxPoints = math.MaxUint32 / 16
+
+// shapeTypeOf returns the shape type used to encode g.
+func shapeTypeOf(g geom.T) (ShapeType, error) {
+	var shapeType ShapeType
+	switch g.(type) {
+	case *geom.Point:
+		shapeType = ShapeTypePoint
+	case *geom.LineString, *geom.MultiLineString:
+		shapeType = ShapeTypePolyLine
+	case *geom.Polygon, *geom.MultiPolygon:
+		shapeType = ShapeTypePolygon
+	case *geom.MultiPoint:
+		shapeType = ShapeTypeMultiPoint
+	default:
+		return ShapeTypeNull, geom.ErrUnsupportedType{Value: g}
+	}
+	switch g.Layout() {
+	case geom.XY:
+		return shapeType, nil
+	case geom.XYZ, geom.XYZM:
+		return shapeType + 10, nil
+	case geom.XYM:
+		return shapeType + 20, nil
+	default:
+		return ShapeTypeNull, geom.ErrUnsupportedLayout(g.Layout())
+	}
+}
+
+// baseShapeType returns the shape type of shapeType without Z or M, and
+// whether shapeType has Z values and M values.
+func baseShapeType(shapeType ShapeType) (ShapeType, bool, bool, error) {
+	switch shapeType {
+	case ShapeTypeNull, ShapeTypePoint, ShapeTypePolyLine, ShapeTypePolygon, ShapeTypeMultiPoint:
+		return shapeType, false, false, nil
+	case ShapeTypePointZ, ShapeTypePolyLineZ, ShapeTypePolygonZ, ShapeTypeMultiPointZ:
+		return shapeType - 10, true, true, nil
+	case ShapeTypePointM, ShapeTypePolyLineM, ShapeTypePolygonM, ShapeTypeMultiPointM:
+		return shapeType - 20, false, true, nil
+	default:
+		return ShapeTypeNull, false, false, ErrUnsupportedShapeType(shapeType)
+	}
+}
+
+// A shapeDecoder decodes the content of a record.
+type shapeDecoder struct {
+	data []byte
+}
+
+func (d *shapeDecoder) int32() (int, error) {
+	if len(d.data) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	i := int32(binary.LittleEndian.Uint32(d.data))
+	d.data = d.data[4:]
+	return int(i), nil
+}
+
+// float64s reads n values into every stride-th element of dst, starting at
+// offset.
+func (d *shapeDecoder) float64s(dst []float64, offset, stride, n int) error {
+	if len(d.data) < 8*n {
+		return io.ErrUnexpectedEOF
+	}
+	for i := 0; i < n; i++ {
+		dst[offset+i*stride] = float64frombits(d.data[8*i:])
+	}
+	d.data = d.data[8*n:]
+	return nil
+}
+
+func (d *shapeDecoder) skip(n int) error {
+	if len(d.data) < n {
+		return io.ErrUnexpectedEOF
+	}
+	d.data = d.data[n:]
+	return nil
+}
+
+// points decodes n points with Z values if hasZ and M values if hasM.  M
+// values are optional.
+func (d *shapeDecoder) points(n int, hasZ, hasM bool) (geom.Layout, []float64, error) {
+	if n < 0 || n > maxPoints || len(d.data) < 16*n {
+		return geom.NoLayout, nil, io.ErrUnexpectedEOF
+	}
+	layout := geom.XY
+	switch {
+	case hasZ:
+		layout = geom.XYZM
+	case hasM:
+		layout = geom.XYM
+	}
+	stride := layout.Stride()
+	flatCoords := make([]float64, n*stride)
+	// the X and Y values are interleaved
+	for i := 0; i < n; i++ {
+		if err := d.float64s(flatCoords, i*stride, 1, 2); err != nil {
+			return geom.NoLayout, nil, err
+		}
+	}
+	if hasZ {
+		if err := d.skip(16); err != nil {
+			return geom.NoLayout, nil, err
+		}
+		if err := d.float64s(flatCoords, 2, stride, n); err != nil {
+			return geom.NoLayout, nil, err
+		}
+	}
+	if !hasM {
+		return layout, flatCoords, nil
+	}
+	mIndex := layout.MIndex()
+	if len(d.data) < 16+8*n {
+		// measures are absent
+		if hasZ {
+			return geom.XYZ, deflateM(flatCoords, stride), nil
+		}
+		for i := 0; i < n; i++ {
+			flatCoords[i*stride+mIndex] = math.NaN()
+		}
+		return layout, flatCoords, nil
+	}
+	if err := d.skip(16); err != nil {
+		return geom.NoLayout, nil, err
+	}
+	if err := d.float64s(flatCoords, mIndex, stride, n); err != nil {
+		return geom.NoLayout, nil, err
+	}
+	hasMeasures := false
+	for i := 0; i < n; i++ {
+		if m := &flatCoords[i*stride+mIndex]; isNoData(*m) {
+			*m = math.NaN()
+		} else {
+			hasMeasures = true
+		}
+	}
+	if hasZ && !hasMeasures {
+		return geom.XYZ, deflateM(flatCoords, stride), nil
+	}
+	return layout, flatCoords, nil
+}
+
+// deflateM removes the M values from flatCoords, which has layout geom.XYZM.
+func deflateM(flatCoords []float64, stride int) []float64 {
+	result := flatCoords[:0]
+	for i := 0; i < len(flatCoords); i += stride {
+		result = append(result, flatCoords[i:i+3]...)
+	}
+	return result
+}
+
+// decodeShape decodes the content of a record.
+func decodeShape(content []byte) (geom.T, error) {
+	d := &shapeDecoder{data: content}
+	t, err := d.int32()
+	if err != nil {
+		return nil, err
+	}
+	shapeType := ShapeType(t)
+	base, hasZ, hasM, err := baseShapeType(shapeType)
+	if err != nil {
+		return nil, err
+	}
+	switch base {
+	case ShapeTypeNull:
+		return nil, nil
+	case ShapeTypePoint:
+		if hasZ {
+			// the M value of a PointZ is not optional, so decode it as a
+			// single point with Z and M values and drop the M value if it is
+			// "no data"
+			if len(d.data) < 32 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			flatCoords := make([]float64, 4)
+			if err := d.float64s(flatCoords, 0, 1, 4); err != nil {
+				return nil, err
+			}
+			if isNoData(flatCoords[3]) {
+				return geom.NewPointFlat(geom.XYZ, flatCoords[:3]), nil
+			}
+			return geom.NewPointFlat(geom.XYZM, flatCoords), nil
+		}
+		layout := geom.XY
+		if hasM {
+			layout = geom.XYM
+		}
+		flatCoords := make([]float64, layout.Stride())
+		if err := d.float64s(flatCoords, 0, 1, len(flatCoords)); err != nil {
+			return nil, err
+		}
+		if hasM && isNoData(flatCoords[2]) {
+			flatCoords[2] = math.NaN()
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case ShapeTypeMultiPoint:
+		if err := d.skip(32); err != nil {
+			return nil, err
+		}
+		n, err := d.int32()
+		if err != nil {
+			return nil, err
+		}
+		layout, flatCoords, err := d.points(n, hasZ, hasM)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	default:
+		if err := d.skip(32); err != nil {
+			return nil, err
+		}
+		numParts, err := d.int32()
+		if err != nil {
+			return nil, err
+		}
+		numPoints, err := d.int32()
+		if err != nil {
+			return nil, err
+		}
+		if numParts < 0 || numParts > numPoints {
+			return nil, errInvalidParts
+		}
+		if len(d.data) < 4*numParts {
+			return nil, io.ErrUnexpectedEOF
+		}
+		parts := make([]int, numParts+1)
+		for i := 0; i < numParts; i++ {
+			parts[i], _ = d.int32()
+			if i == 0 && parts[i] != 0 || i > 0 && parts[i] <= parts[i-1] || parts[i] >= numPoints {
+				return nil, errInvalidParts
+			}
+		}
+		parts[numParts] = numPoints
+		layout, flatCoords, err := d.points(numPoints, hasZ, hasM)
+		if err != nil {
+			return nil, err
+		}
+		stride := layout.Stride()
+		ends := make([]int, numParts)
+		for i := range ends {
+			ends[i] = parts[i+1] * stride
+		}
+		if base == ShapeTypePolyLine {
+			return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+		}
+		return decodePolygon(layout, flatCoords, ends), nil
+	}
+}
+
+// encodeShape encodes g, which may be nil, as the content of a record of the
+// given shape type.
+func encodeShape(g geom.T, shapeType ShapeType) ([]byte, error) {
+	if g == nil || len(g.FlatCoords()) == 0 {
+		var content [4]byte
+		return content[:], nil
+	}
+	gShapeType, err := shapeTypeOf(g)
+	if err != nil {
+		return nil, err
+	}
+	if gShapeType != shapeType {
+		return nil, ErrShapeTypeMismatch{Got: gShapeType, Want: shapeType}
+	}
+	base, hasZ, hasM, err := baseShapeType(shapeType)
+	if err != nil {
+		return nil, err
+	}
+
+	var flatCoords []float64
+	var ends []int
+	switch g := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		flatCoords = g.FlatCoords()
+	case *geom.LineString:
+		flatCoords, ends = g.FlatCoords(), []int{len(g.FlatCoords())}
+	case *geom.MultiLineString:
+		flatCoords, ends = g.FlatCoords(), g.Ends()
+	case *geom.Polygon:
+		flatCoords, ends = encodePolygon(g.FlatCoords(), g.Stride(), [][]int{g.Ends()})
+	case *geom.MultiPolygon:
+		flatCoords, ends = encodePolygon(g.FlatCoords(), g.Stride(), g.Endss())
+	}
+	if len(flatCoords)/g.Stride() > maxPoints {
+		return nil, errTooLarge
+	}
+
+	e := &shapeEncoder{}
+	e.int32(int(shapeType))
+	layout := g.Layout()
+	stride := layout.Stride()
+	n := len(flatCoords) / stride
+	if base == ShapeTypePoint {
+		e.float64s(flatCoords, 0, stride, 1)
+		e.float64s(flatCoords, 1, stride, 1)
+		if hasZ {
+			e.float64s(flatCoords, 2, stride, 1)
+		}
+		switch {
+		case hasM && layout.MIndex() == -1:
+			e.float64s([]float64{noData}, 0, 1, 1)
+		case hasM:
+			e.float64s(flatCoords, layout.MIndex(), stride, 1)
+		}
+		return e.data, nil
+	}
+
+	box := []float64{flatCoords[0], flatCoords[1], flatCoords[0], flatCoords[1]}
+	for i := stride; i < len(flatCoords); i += stride {
+		box[0], box[2] = math.Min(box[0], flatCoords[i]), math.Max(box[2], flatCoords[i])
+		box[1], box[3] = math.Min(box[1], flatCoords[i+1]), math.Max(box[3], flatCoords[i+1])
+	}
+	e.float64s(box, 0, 1, 4)
+	if base != ShapeTypeMultiPoint {
+		e.int32(len(ends))
+	}
+	e.int32(n)
+	if base != ShapeTypeMultiPoint {
+		offset := 0
+		for _, end := range ends {
+			e.int32(offset / stride)
+			offset = end
+		}
+	}
+	for i := 0; i < len(flatCoords); i += stride {
+		e.float64s(flatCoords, i, 1, 2)
+	}
+	if hasZ {
+		e.ranged(flatCoords, 2, stride)
+	}
+	if hasM && layout.MIndex() != -1 {
+		e.ranged(flatCoords, layout.MIndex(), stride)
+	}
+	return e.data, nil
+}
+
+// A shapeEncoder encodes the content of a record.
+type shapeEncoder struct {
+	data []byte
+}
+
+func (e *shapeEncoder) int32(i int) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(int32(i)))
+	e.data = append(e.data, buf[:]...)
+}
+
+// float64s writes n values from every stride-th element of src, starting at
+// offset.  NaN values, which are used for missing measures, are encoded as
+// "no data".
+func (e *shapeEncoder) float64s(src []float64, offset, stride, n int) {
+	var buf [8]byte
+	for i := 0; i < n; i++ {
+		x := src[offset+i*stride]
+		if math.IsNaN(x) {
+			x = noData
+		}
+		putFloat64(buf[:], x)
+		e.data = append(e.data, buf[:]...)
+	}
+}
+
+// ranged writes the range of every stride-th element of src, starting at
+// offset, followed by the elements themselves.
+func (e *shapeEncoder) ranged(src []float64, offset, stride int) {
+	n := len(src) / stride
+	min, max := math.Inf(1), math.Inf(-1)
+	for i := offset; i < len(src); i += stride {
+		min = math.Min(min, src[i])
+		max = math.Max(max, src[i])
+	}
+	if min > max {
+		min, max = math.NaN(), math.NaN()
+	}
+	e.float64s([]float64{min, max}, 0, 1, 2)
+	e.float64s(src, offset, stride, n)
+}
+
+// An extent accumulates the bounds of geometries for a file header.
+type extent struct {
+	min, max [4]float64
+}
+
+func newExtent() *extent {
+	e := &extent{}
+	for i := range e.min {
+		e.min[i], e.max[i] = math.Inf(1), math.Inf(-1)
+	}
+	return e
+}
+
+// extend extends e with the coordinates of g.  NaN measures are ignored.
+func (e *extent) extend(g geom.T) {
+	layout := g.Layout()
+	indexes := [4]int{0, 1, layout.ZIndex(), layout.MIndex()}
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	for i := 0; i < len(flatCoords); i += stride {
+		for j, index := range indexes {
+			if index == -1 || math.IsNaN(flatCoords[i+index]) {
+				continue
+			}
+			e.min[j] = math.Min(e.min[j], flatCoords[i+index])
+			e.max[j] = math.Max(e.max[j], flatCoords[i+index])
+		}
+	}
+}
+
+// bounds returns the minimum and maximum of dimension i, or zero if there are
+// no values.
+func (e *extent) bounds(i int) (float64, float64) {
+	if e.min[i] > e.max[i] {
+		return 0, 0
+	}
+	return e.min[i], e.max[i]
+}
+
+func float64frombits(data []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(data))
+}
+
+func putFloat64(data []byte, x float64) {
+	binary.LittleEndian.PutUint64(data, math.Float64bits(x))
+}
@@ -0,0 +1,502 @@
+// Package spatialite implements SpatiaLite BLOB geometry encoding and
+// decoding.
+//
+// See https://www.gaia-gis.it/gaia-sins/BLOB-Geometry.html.
+package spatialite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/geomutil"
+)
+
+var (
+	// XDR is big endian.
+	XDR = wkbcommon.XDR
+	// NDR is little endian.
+	NDR = wkbcommon.NDR
+)
+
+// Markers.
+const (
+	markerStart  = 0x00
+	markerMBREnd = 0x7c
+	markerEntity = 0x69
+	markerEnd    = 0xfe
+)
+
+// Class type offsets.
+const (
+	classZ          = 1000
+	classM          = 2000
+	classZM         = 3000
+	classCompressed = 1000000
+)
+
+// headerSize is the size of the header, from the start marker to the class
+// type inclusive.
+const headerSize = 43
+
+var errEmptyPoint = errors.New("spatialite: empty points cannot be encoded")
+
+// An ErrInvalidMarker is returned when an expected marker is not found.
+type ErrInvalidMarker struct {
+	Got  byte
+	Want byte
+}
+
+func (e ErrInvalidMarker) Error() string {
+	return fmt.Sprintf("spatialite: invalid marker 0x%02x, want 0x%02x", e.Got, e.Want)
+}
+
+// An ErrUnsupportedClassType is returned when a class type is not supported.
+type ErrUnsupportedClassType uint32
+
+func (e ErrUnsupportedClassType) Error() string {
+	return fmt.Sprintf("spatialite: unsupported class type %d", uint32(e))
+}
+
+// A Header is a SpatiaLite BLOB geometry header.
+type Header struct {
+	SRID int
+	// MBR is the minimum bounding rectangle stored in the header.
+	MBR *geom.Bounds
+}
+
+// DecodeHeader decodes the header at the start of data, without decoding the
+// geometry.
+func DecodeHeader(data []byte) (*Header, error) {
+	d, err := newDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Header{
+		SRID: d.srid,
+		MBR:  d.mbr,
+	}, nil
+}
+
+// Unmarshal decodes a SpatiaLite BLOB geometry.  The SRID of the returned
+// geometry is set from the header.  Compressed geometries are supported.
+func Unmarshal(data []byte) (geom.T, error) {
+	d, err := newDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	class, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	g, err := d.geometry(class, geom.NoLayout)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.marker(markerEnd); err != nil {
+		return nil, err
+	}
+	return geomutil.SetSRID(g, d.srid)
+}
+
+// Marshal encodes g as an uncompressed SpatiaLite BLOB geometry using
+// byteOrder.  The SRID is g's SRID.
+func Marshal(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
+	e := &encoder{byteOrder: byteOrder}
+	switch byteOrder {
+	case XDR:
+		e.data = []byte{markerStart, wkbcommon.XDRID}
+	case NDR:
+		e.data = []byte{markerStart, wkbcommon.NDRID}
+	default:
+		return nil, wkbcommon.ErrUnsupportedByteOrder{}
+	}
+	e.uint32(uint32(int32(g.SRID())))
+	var minX, minY, maxX, maxY float64
+	if b := g.Bounds(); !b.IsEmpty() {
+		minX, minY, maxX, maxY = b.Min(0), b.Min(1), b.Max(0), b.Max(1)
+	}
+	e.float64s([]float64{minX, minY, maxX, maxY})
+	e.data = append(e.data, markerMBREnd)
+	if err := e.geometry(g); err != nil {
+		return nil, err
+	}
+	e.data = append(e.data, markerEnd)
+	return e.data, nil
+}
+
+// A decoder decodes a SpatiaLite BLOB geometry.
+type decoder struct {
+	data      []byte
+	byteOrder binary.ByteOrder
+	srid      int
+	mbr       *geom.Bounds
+}
+
+// newDecoder returns a new decoder positioned at the class type after the
+// header.
+func newDecoder(data []byte) (*decoder, error) {
+	if len(data) < headerSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if data[0] != markerStart {
+		return nil, ErrInvalidMarker{Got: data[0], Want: markerStart}
+	}
+	d := &decoder{data: data[2:]}
+	switch data[1] {
+	case wkbcommon.XDRID:
+		d.byteOrder = XDR
+	case wkbcommon.NDRID:
+		d.byteOrder = NDR
+	default:
+		return nil, wkbcommon.ErrUnknownByteOrder(data[1])
+	}
+	srid, _ := d.uint32()
+	d.srid = int(int32(srid))
+	mbr, _ := d.float64s(nil, 4)
+	d.mbr = geom.NewBounds(geom.XY).Set(mbr...)
+	if err := d.marker(markerMBREnd); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *decoder) marker(want byte) error {
+	if len(d.data) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+	if got := d.data[0]; got != want {
+		return ErrInvalidMarker{Got: got, Want: want}
+	}
+	d.data = d.data[1:]
+	return nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if len(d.data) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	u := d.byteOrder.Uint32(d.data)
+	d.data = d.data[4:]
+	return u, nil
+}
+
+// count reads a number of elements at level.
+func (d *decoder) count(level int) (int, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if n > wkbcommon.MaxGeometryElements[level] {
+		return 0, wkbcommon.ErrGeometryTooLarge{Level: level, N: n, Limit: wkbcommon.MaxGeometryElements[level]}
+	}
+	return int(n), nil
+}
+
+// float64s appends n float64s to dst.
+func (d *decoder) float64s(dst []float64, n int) ([]float64, error) {
+	if len(d.data) < 8*n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	for i := 0; i < n; i++ {
+		dst = append(dst, math.Float64frombits(d.byteOrder.Uint64(d.data[8*i:])))
+	}
+	d.data = d.data[8*n:]
+	return dst, nil
+}
+
+func (d *decoder) float32() (float64, error) {
+	if len(d.data) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	f := math.Float32frombits(d.byteOrder.Uint32(d.data))
+	d.data = d.data[4:]
+	return float64(f), nil
+}
+
+// coords appends a count-prefixed sequence of coordinates to flatCoords.
+func (d *decoder) coords(flatCoords []float64, layout geom.Layout, compressed bool) ([]float64, error) {
+	n, err := d.count(1)
+	if err != nil {
+		return nil, err
+	}
+	stride := layout.Stride()
+	if !compressed {
+		return d.float64s(flatCoords, n*stride)
+	}
+	// the first and last coordinates are stored in full, the others as
+	// float32 offsets from the previous coordinate, except for M values
+	// which are never compressed
+	mIndex := layout.MIndex()
+	for i := 0; i < n; i++ {
+		if i == 0 || i == n-1 {
+			if flatCoords, err = d.float64s(flatCoords, stride); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		prev := flatCoords[len(flatCoords)-stride:]
+		for j := 0; j < stride; j++ {
+			var x float64
+			if j == mIndex {
+				var m []float64
+				if m, err = d.float64s(nil, 1); err == nil {
+					x = m[0]
+				}
+			} else {
+				var delta float64
+				if delta, err = d.float32(); err == nil {
+					x = prev[j] + delta
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			flatCoords = append(flatCoords, x)
+		}
+	}
+	return flatCoords, nil
+}
+
+// geometry decodes a geometry of the given class.  If parentLayout is not
+// geom.NoLayout then the geometry's layout must match it.
+func (d *decoder) geometry(class uint32, parentLayout geom.Layout) (geom.T, error) {
+	compressed := class >= classCompressed
+	t := class % classCompressed
+	var layout geom.Layout
+	switch t / 1000 * 1000 {
+	case 0:
+		layout = geom.XY
+	case classZ:
+		layout = geom.XYZ
+	case classM:
+		layout = geom.XYM
+	case classZM:
+		layout = geom.XYZM
+	default:
+		return nil, ErrUnsupportedClassType(class)
+	}
+	if parentLayout != geom.NoLayout && layout != parentLayout {
+		return nil, geom.ErrLayoutMismatch{Got: layout, Want: parentLayout}
+	}
+	t %= 1000
+	if compressed && t != wkbcommon.LineStringID && t != wkbcommon.PolygonID {
+		return nil, ErrUnsupportedClassType(class)
+	}
+
+	switch t {
+	case wkbcommon.PointID:
+		flatCoords, err := d.float64s(nil, layout.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case wkbcommon.LineStringID:
+		flatCoords, err := d.coords(nil, layout, compressed)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case wkbcommon.PolygonID:
+		n, err := d.count(2)
+		if err != nil {
+			return nil, err
+		}
+		var flatCoords []float64
+		var ends []int
+		for i := 0; i < n; i++ {
+			if flatCoords, err = d.coords(flatCoords, layout, compressed); err != nil {
+				return nil, err
+			}
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+	case wkbcommon.MultiPointID:
+		gs, err := d.entities(1, layout)
+		if err != nil {
+			return nil, err
+		}
+		mp := geom.NewMultiPoint(layout)
+		for _, g := range gs {
+			p, ok := g.(*geom.Point)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Point{}}
+			}
+			if err := mp.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	case wkbcommon.MultiLineStringID:
+		gs, err := d.entities(2, layout)
+		if err != nil {
+			return nil, err
+		}
+		mls := geom.NewMultiLineString(layout)
+		for _, g := range gs {
+			ls, ok := g.(*geom.LineString)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.LineString{}}
+			}
+			if err := mls.Push(ls); err != nil {
+				return nil, err
+			}
+		}
+		return mls, nil
+	case wkbcommon.MultiPolygonID:
+		gs, err := d.entities(3, layout)
+		if err != nil {
+			return nil, err
+		}
+		mp := geom.NewMultiPolygon(layout)
+		for _, g := range gs {
+			p, ok := g.(*geom.Polygon)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Polygon{}}
+			}
+			if err := mp.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	default:
+		return nil, ErrUnsupportedClassType(class)
+	}
+}
+
+// entities decodes the entities of a multi-geometry.
+func (d *decoder) entities(level int, layout geom.Layout) ([]geom.T, error) {
+	n, err := d.count(level)
+	if err != nil {
+		return nil, err
+	}
+	gs := make([]geom.T, n)
+	for i := range gs {
+		if err := d.marker(markerEntity); err != nil {
+			return nil, err
+		}
+		class, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if gs[i], err = d.geometry(class, layout); err != nil {
+			return nil, err
+		}
+	}
+	return gs, nil
+}
+
+// An encoder encodes a SpatiaLite BLOB geometry.
+type encoder struct {
+	data      []byte
+	byteOrder binary.ByteOrder
+}
+
+func (e *encoder) uint32(u uint32) {
+	var buf [4]byte
+	e.byteOrder.PutUint32(buf[:], u)
+	e.data = append(e.data, buf[:]...)
+}
+
+func (e *encoder) float64s(fs []float64) {
+	var buf [8]byte
+	for _, f := range fs {
+		e.byteOrder.PutUint64(buf[:], math.Float64bits(f))
+		e.data = append(e.data, buf[:]...)
+	}
+}
+
+// coords encodes a count-prefixed sequence of coordinates.
+func (e *encoder) coords(flatCoords []float64, stride int) {
+	e.uint32(uint32(len(flatCoords) / stride))
+	e.float64s(flatCoords)
+}
+
+// class encodes the class type of g.
+func (e *encoder) class(g geom.T) error {
+	var class uint32
+	switch g.(type) {
+	case *geom.Point:
+		class = wkbcommon.PointID
+	case *geom.LineString:
+		class = wkbcommon.LineStringID
+	case *geom.Polygon:
+		class = wkbcommon.PolygonID
+	case *geom.MultiPoint:
+		class = wkbcommon.MultiPointID
+	case *geom.MultiLineString:
+		class = wkbcommon.MultiLineStringID
+	case *geom.MultiPolygon:
+		class = wkbcommon.MultiPolygonID
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	switch g.Layout() {
+	case geom.XY:
+	case geom.XYZ:
+		class += classZ
+	case geom.XYM:
+		class += classM
+	case geom.XYZM:
+		class += classZM
+	default:
+		return geom.ErrUnsupportedLayout(g.Layout())
+	}
+	e.uint32(class)
+	return nil
+}
+
+// geometry encodes the class type and body of g.
+func (e *encoder) geometry(g geom.T) error {
+	if err := e.class(g); err != nil {
+		return err
+	}
+	switch g := g.(type) {
+	case *geom.Point:
+		if len(g.FlatCoords()) == 0 {
+			return errEmptyPoint
+		}
+		e.float64s(g.FlatCoords())
+	case *geom.LineString:
+		e.coords(g.FlatCoords(), g.Stride())
+	case *geom.Polygon:
+		e.uint32(uint32(g.NumLinearRings()))
+		offset := 0
+		for _, end := range g.Ends() {
+			e.coords(g.FlatCoords()[offset:end], g.Stride())
+			offset = end
+		}
+	case *geom.MultiPoint:
+		e.uint32(uint32(g.NumPoints()))
+		for i := 0; i < g.NumPoints(); i++ {
+			if err := e.entity(g.Point(i)); err != nil {
+				return err
+			}
+		}
+	case *geom.MultiLineString:
+		e.uint32(uint32(g.NumLineStrings()))
+		for i := 0; i < g.NumLineStrings(); i++ {
+			if err := e.entity(g.LineString(i)); err != nil {
+				return err
+			}
+		}
+	case *geom.MultiPolygon:
+		e.uint32(uint32(g.NumPolygons()))
+		for i := 0; i < g.NumPolygons(); i++ {
+			if err := e.entity(g.Polygon(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// entity encodes an element of a multi-geometry.
+func (e *encoder) entity(g geom.T) error {
+	e.data = append(e.data, markerEntity)
+	return e.geometry(g)
+}
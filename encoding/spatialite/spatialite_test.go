@@ -0,0 +1,176 @@
+package spatialite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/geomutil"
+	"github.com/twpayne/go-geom/internal/testdata"
+)
+
+func TestRandom(t *testing.T) {
+	for i, tc := range testdata.Random {
+		for _, byteOrder := range []binary.ByteOrder{XDR, NDR} {
+			g, err := geomutil.SetSRID(tc.G, 4326)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := Marshal(g, byteOrder)
+			if err != nil {
+				t.Errorf("%d: Marshal(%#v, %v) == nil, %v, want ..., nil", i, g, byteOrder, err)
+				continue
+			}
+			got, err := Unmarshal(data)
+			if err != nil || !reflect.DeepEqual(got, g) {
+				t.Errorf("%d: Unmarshal(%v) == %#v, %v, want %#v, nil", i, data, got, err, g)
+			}
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for i, tc := range []struct {
+		g   geom.T
+		hex string
+	}{
+		{
+			g: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			hex: "0001e6100000" +
+				"000000000000f03f" + "0000000000000040" + "000000000000f03f" + "0000000000000040" + "7c" +
+				"01000000" + "000000000000f03f" + "0000000000000040" +
+				"fe",
+		},
+		{
+			g: geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			hex: "000100000000" +
+				"000000000000f03f" + "0000000000000040" + "000000000000f03f" + "0000000000000040" + "7c" +
+				"ec030000" + "01000000" +
+				"69" + "e9030000" + "000000000000f03f" + "0000000000000040" + "0000000000000840" +
+				"fe",
+		},
+	} {
+		data, err := Marshal(tc.g, NDR)
+		if got := hex.EncodeToString(data); err != nil || got != tc.hex {
+			t.Errorf("%d: Marshal(%#v, NDR) == %s, %v, want %s, <nil>", i, tc.g, got, err, tc.hex)
+		}
+		if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("%d: Unmarshal(%s) == %#v, %v, want %#v, <nil>", i, tc.hex, got, err, tc.g)
+		}
+	}
+}
+
+func TestUnmarshalCompressed(t *testing.T) {
+	for i, tc := range []struct {
+		class uint32
+		body  []interface{}
+		want  geom.T
+	}{
+		{
+			class: classCompressed + wkbcommon.LineStringID,
+			body: []interface{}{
+				uint32(3),
+				float64(1), float64(2),
+				float32(0.5), float32(-1),
+				float64(4), float64(5),
+			},
+			want: geom.NewLineStringFlat(geom.XY, []float64{1, 2, 1.5, 1, 4, 5}),
+		},
+		{
+			class: classCompressed + classM + wkbcommon.LineStringID,
+			body: []interface{}{
+				uint32(4),
+				float64(1), float64(2), float64(10),
+				float32(1), float32(1), float64(20),
+				float32(1), float32(1), float64(30),
+				float64(4), float64(5), float64(40),
+			},
+			want: geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 10, 2, 3, 20, 3, 4, 30, 4, 5, 40}),
+		},
+		{
+			class: classCompressed + classZ + wkbcommon.PolygonID,
+			body: []interface{}{
+				uint32(1), uint32(4),
+				float64(0), float64(0), float64(0),
+				float32(1), float32(0), float32(1),
+				float32(0), float32(1), float32(1),
+				float64(0), float64(0), float64(0),
+			},
+			want: geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 1, 1, 1, 2, 0, 0, 0}, []int{12}),
+		},
+	} {
+		data := newBlob(t, 4326, append([]interface{}{tc.class}, tc.body...)...)
+		want, err := geomutil.SetSRID(tc.want, 4326)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: Unmarshal(%v) == %#v, %v, want %#v, <nil>", i, data, got, err, want)
+		}
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	g := geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6}).SetSRID(-1)
+	data, err := Marshal(g, XDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Header{
+		SRID: -1,
+		MBR:  geom.NewBounds(geom.XY).Set(1, 2, 4, 5),
+	}
+	if got, err := DecodeHeader(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeHeader(...) == %+v, %v, want %+v, <nil>", got, err, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Marshal(geom.NewPointFlat(geom.XY, nil), NDR); err != errEmptyPoint {
+		t.Errorf("Marshal(empty point, NDR) == ..., %v, want %v", err, errEmptyPoint)
+	}
+	for i, tc := range []struct {
+		data []byte
+		want error
+	}{
+		{data: nil, want: io.ErrUnexpectedEOF},
+		{data: newBlob(t, 0, uint32(wkbcommon.PointID))[:headerSize-1], want: io.ErrUnexpectedEOF},
+		{data: append([]byte{0x01}, newBlob(t, 0, uint32(wkbcommon.PointID))[1:]...), want: ErrInvalidMarker{Got: 0x01, Want: markerStart}},
+		{data: newBlob(t, 0, uint32(wkbcommon.PointID), float64(1), float64(2), float64(3)), want: ErrInvalidMarker{Got: 0x00, Want: markerEnd}},
+		{data: newBlob(t, 0, uint32(wkbcommon.PointID), float64(1)), want: io.ErrUnexpectedEOF},
+		{data: newBlob(t, 0, uint32(7)), want: ErrUnsupportedClassType(7)},
+		{data: newBlob(t, 0, uint32(4001)), want: ErrUnsupportedClassType(4001)},
+		{data: newBlob(t, 0, uint32(classCompressed+wkbcommon.PointID)), want: ErrUnsupportedClassType(classCompressed + wkbcommon.PointID)},
+		{
+			data: newBlob(t, 0, uint32(wkbcommon.MultiPointID), uint32(1), byte(markerEntity), uint32(classZ+wkbcommon.PointID)),
+			want: geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+		{
+			data: newBlob(t, 0, uint32(wkbcommon.MultiPointID), uint32(1), byte(markerEntity), uint32(wkbcommon.LineStringID), uint32(0)),
+			want: wkbcommon.ErrUnexpectedType{Got: geom.NewLineStringFlat(geom.XY, nil), Want: &geom.Point{}},
+		},
+	} {
+		if _, err := Unmarshal(tc.data); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Unmarshal(%v) == ..., %v, want %v", i, tc.data, err, tc.want)
+		}
+	}
+}
+
+// newBlob returns a little endian SpatiaLite BLOB with the given SRID, an
+// all-zero MBR, and the given values as the class type and body.
+func newBlob(t *testing.T, srid int32, values ...interface{}) []byte {
+	b := &bytes.Buffer{}
+	b.Write([]byte{markerStart, wkbcommon.NDRID})
+	for _, v := range append([]interface{}{srid, [4]float64{}, byte(markerMBREnd)}, values...) {
+		if err := binary.Write(b, NDR, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.WriteByte(markerEnd)
+	return b.Bytes()
+}
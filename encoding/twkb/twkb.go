@@ -0,0 +1,599 @@
+// Package twkb implements Tiny Well Known Binary encoding and decoding.
+//
+// TWKB stores coordinates as variable length integers, delta encoded from
+// the previous coordinate after scaling by a per-dimension precision.  It is
+// compatible with PostGIS's ST_AsTWKB and ST_GeomFromTWKB.
+//
+// See https://github.com/TWKB/Specification/blob/master/twkb.md.
+package twkb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// Geometry type IDs.
+const (
+	pointID              = 1
+	lineStringID         = 2
+	polygonID            = 3
+	multiPointID         = 4
+	multiLineStringID    = 5
+	multiPolygonID       = 6
+	geometryCollectionID = 7
+)
+
+// Metadata header flags.
+const (
+	flagBBox              = 1 << 0
+	flagSize              = 1 << 1
+	flagIDList            = 1 << 2
+	flagExtendedPrecision = 1 << 3
+	flagEmpty             = 1 << 4
+)
+
+var errTruncated = errors.New("twkb: truncated data")
+
+// An ErrInvalidPrecision is returned when a precision is out of range.
+type ErrInvalidPrecision int
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("twkb: invalid precision %d", int(e))
+}
+
+// An ErrIDsMismatch is returned when the number of IDs does not match the
+// number of geometries in a multi-geometry.
+type ErrIDsMismatch struct {
+	Got  int
+	Want int
+}
+
+func (e ErrIDsMismatch) Error() string {
+	return fmt.Sprintf("twkb: got %d ids, want %d", e.Got, e.Want)
+}
+
+// An ErrUnknownType is returned when an unknown geometry type is encountered.
+type ErrUnknownType byte
+
+func (e ErrUnknownType) Error() string {
+	return fmt.Sprintf("twkb: unknown type: %d", byte(e))
+}
+
+// An EncodeOption sets an option for Marshal.
+type EncodeOption func(*encoder) error
+
+// WithPrecision sets the number of decimal places kept for the x and y, z,
+// and m ordinates.  xy must be between -7 and 7 (negative values round to
+// tens, hundreds, etc.), z and m must be between 0 and 7.  The default
+// precision is 0 for all ordinates.
+func WithPrecision(xy, z, m int) EncodeOption {
+	return func(e *encoder) error {
+		if xy < -7 || 7 < xy {
+			return ErrInvalidPrecision(xy)
+		}
+		if z < 0 || 7 < z {
+			return ErrInvalidPrecision(z)
+		}
+		if m < 0 || 7 < m {
+			return ErrInvalidPrecision(m)
+		}
+		e.precisionXY, e.precisionZ, e.precisionM = xy, z, m
+		return nil
+	}
+}
+
+// WithBBox includes the bounding box in the output.
+func WithBBox() EncodeOption {
+	return func(e *encoder) error {
+		e.bbox = true
+		return nil
+	}
+}
+
+// WithSize includes the size of the geometry in bytes in the output, which
+// allows readers to skip over geometries.
+func WithSize() EncodeOption {
+	return func(e *encoder) error {
+		e.size = true
+		return nil
+	}
+}
+
+// WithIDs includes an ID for each element of a MultiPoint, MultiLineString,
+// or MultiPolygon.  The number of ids must match the number of elements.
+func WithIDs(ids []int64) EncodeOption {
+	return func(e *encoder) error {
+		e.ids = ids
+		return nil
+	}
+}
+
+// Marshal marshals an arbitrary geometry to a []byte.
+func Marshal(g geom.T, opts ...EncodeOption) ([]byte, error) {
+	e := &encoder{}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	return e.encode(g)
+}
+
+// Unmarshal unmarshals an arbitrary geometry from a []byte.
+func Unmarshal(data []byte) (geom.T, error) {
+	g, _, err := UnmarshalWithIDs(data)
+	return g, err
+}
+
+// UnmarshalWithIDs unmarshals an arbitrary geometry from a []byte, also
+// returning the IDs of the elements of a multi-geometry if present.
+func UnmarshalWithIDs(data []byte) (geom.T, []int64, error) {
+	d := &decoder{data: data}
+	return d.decode()
+}
+
+func appendUvarint(b []byte, u uint64) []byte {
+	for u >= 0x80 {
+		b = append(b, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(b, byte(u))
+}
+
+func zigzag(n int64) uint64 {
+	return uint64(n<<1) ^ uint64(n>>63)
+}
+
+func unzigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// quantize scales x by 10^precision and rounds half away from zero, like
+// PostGIS.
+func quantize(x float64, precision int) int64 {
+	if precision >= 0 {
+		x *= math.Pow10(precision)
+	} else {
+		x /= math.Pow10(-precision)
+	}
+	if x < 0 {
+		return -int64(math.Floor(-x + 0.5))
+	}
+	return int64(math.Floor(x + 0.5))
+}
+
+// dequantize is the inverse of quantize.
+func dequantize(v int64, precision int) float64 {
+	if precision >= 0 {
+		return float64(v) / math.Pow10(precision)
+	}
+	return float64(v) * math.Pow10(-precision)
+}
+
+type encoder struct {
+	precisionXY int
+	precisionZ  int
+	precisionM  int
+	bbox        bool
+	size        bool
+	ids         []int64
+
+	buf        []byte
+	precisions []int
+	prev       []int64
+}
+
+func (e *encoder) encode(g geom.T) ([]byte, error) {
+	var typeID byte
+	n := -1
+	switch g := g.(type) {
+	case *geom.Point:
+		typeID = pointID
+	case *geom.LineString:
+		typeID = lineStringID
+	case *geom.Polygon:
+		typeID = polygonID
+	case *geom.MultiPoint:
+		typeID, n = multiPointID, g.NumPoints()
+	case *geom.MultiLineString:
+		typeID, n = multiLineStringID, g.NumLineStrings()
+	case *geom.MultiPolygon:
+		typeID, n = multiPolygonID, g.NumPolygons()
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+
+	layout := g.Layout()
+	var hasZ, hasM bool
+	switch layout {
+	case geom.XY:
+	case geom.XYZ:
+		hasZ = true
+	case geom.XYM:
+		hasM = true
+	case geom.XYZM:
+		hasZ, hasM = true, true
+	default:
+		return nil, geom.ErrUnsupportedLayout(layout)
+	}
+	if e.ids != nil && len(e.ids) != n {
+		return nil, ErrIDsMismatch{Got: len(e.ids), Want: n}
+	}
+
+	e.precisions = []int{e.precisionXY, e.precisionXY}
+	if hasZ {
+		e.precisions = append(e.precisions, e.precisionZ)
+	}
+	if hasM {
+		e.precisions = append(e.precisions, e.precisionM)
+	}
+	e.prev = make([]int64, len(e.precisions))
+
+	var metadata byte
+	empty := len(g.FlatCoords()) == 0
+	if empty {
+		metadata |= flagEmpty
+	}
+	if e.bbox && !empty {
+		metadata |= flagBBox
+	}
+	if e.size {
+		metadata |= flagSize
+	}
+	if e.ids != nil && !empty {
+		metadata |= flagIDList
+	}
+	if hasZ || hasM {
+		metadata |= flagExtendedPrecision
+	}
+
+	header := []byte{typeID | byte(zigzag(int64(e.precisionXY)))<<4, metadata}
+	if hasZ || hasM {
+		var extended byte
+		if hasZ {
+			extended |= 1 | byte(e.precisionZ)<<2
+		}
+		if hasM {
+			extended |= 2 | byte(e.precisionM)<<5
+		}
+		header = append(header, extended)
+	}
+
+	if !empty {
+		if e.bbox {
+			e.encodeBBox(g)
+		}
+		switch g := g.(type) {
+		case *geom.Point:
+			e.writeCoords(g.FlatCoords(), g.Stride())
+		case *geom.LineString:
+			e.writeCoords1(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride())
+		case *geom.Polygon:
+			e.writeCoords2(g.FlatCoords(), 0, g.Ends(), g.Stride())
+		case *geom.MultiPoint:
+			e.writeUvarint(uint64(n))
+			e.writeIDs()
+			e.writeCoords(g.FlatCoords(), g.Stride())
+		case *geom.MultiLineString:
+			e.writeUvarint(uint64(n))
+			e.writeIDs()
+			offset := 0
+			for _, end := range g.Ends() {
+				e.writeCoords1(g.FlatCoords(), offset, end, g.Stride())
+				offset = end
+			}
+		case *geom.MultiPolygon:
+			e.writeUvarint(uint64(n))
+			e.writeIDs()
+			offset := 0
+			for _, ends := range g.Endss() {
+				e.writeCoords2(g.FlatCoords(), offset, ends, g.Stride())
+				if len(ends) != 0 {
+					offset = ends[len(ends)-1]
+				}
+			}
+		}
+	}
+
+	if e.size {
+		header = appendUvarint(header, uint64(len(e.buf)))
+	}
+	return append(header, e.buf...), nil
+}
+
+func (e *encoder) encodeBBox(g geom.T) {
+	stride := g.Stride()
+	flatCoords := g.FlatCoords()
+	for d, precision := range e.precisions {
+		min, max := int64(math.MaxInt64), int64(math.MinInt64)
+		for i := d; i < len(flatCoords); i += stride {
+			v := quantize(flatCoords[i], precision)
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		e.writeVarint(min)
+		e.writeVarint(max - min)
+	}
+}
+
+func (e *encoder) writeUvarint(u uint64) {
+	e.buf = appendUvarint(e.buf, u)
+}
+
+func (e *encoder) writeVarint(n int64) {
+	e.writeUvarint(zigzag(n))
+}
+
+func (e *encoder) writeIDs() {
+	for _, id := range e.ids {
+		e.writeVarint(id)
+	}
+}
+
+func (e *encoder) writeCoords(flatCoords []float64, stride int) {
+	for i := 0; i < len(flatCoords); i += stride {
+		for d, precision := range e.precisions {
+			v := quantize(flatCoords[i+d], precision)
+			e.writeVarint(v - e.prev[d])
+			e.prev[d] = v
+		}
+	}
+}
+
+func (e *encoder) writeCoords1(flatCoords []float64, offset, end, stride int) {
+	e.writeUvarint(uint64((end - offset) / stride))
+	e.writeCoords(flatCoords[offset:end], stride)
+}
+
+func (e *encoder) writeCoords2(flatCoords []float64, offset int, ends []int, stride int) {
+	e.writeUvarint(uint64(len(ends)))
+	for _, end := range ends {
+		e.writeCoords1(flatCoords, offset, end, stride)
+		offset = end
+	}
+}
+
+type decoder struct {
+	data       []byte
+	pos        int
+	layout     geom.Layout
+	precisions []int
+	prev       []int64
+}
+
+func (d *decoder) decode() (geom.T, []int64, error) {
+	if len(d.data)-d.pos < 2 {
+		return nil, nil, errTruncated
+	}
+	typeID, precisionXY := d.data[d.pos]&0xf, unzigzag(uint64(d.data[d.pos]>>4))
+	metadata := d.data[d.pos+1]
+	d.pos += 2
+
+	d.layout = geom.XY
+	d.precisions = []int{int(precisionXY), int(precisionXY)}
+	if metadata&flagExtendedPrecision != 0 {
+		if d.pos == len(d.data) {
+			return nil, nil, errTruncated
+		}
+		extended := d.data[d.pos]
+		d.pos++
+		hasZ, hasM := extended&1 != 0, extended&2 != 0
+		switch {
+		case hasZ && hasM:
+			d.layout = geom.XYZM
+		case hasZ:
+			d.layout = geom.XYZ
+		case hasM:
+			d.layout = geom.XYM
+		}
+		if hasZ {
+			d.precisions = append(d.precisions, int(extended>>2&0x7))
+		}
+		if hasM {
+			d.precisions = append(d.precisions, int(extended>>5&0x7))
+		}
+	}
+	d.prev = make([]int64, len(d.precisions))
+
+	if metadata&flagSize != 0 {
+		size, err := d.readUvarint()
+		if err != nil {
+			return nil, nil, err
+		}
+		if size > uint64(len(d.data)-d.pos) {
+			return nil, nil, errTruncated
+		}
+	}
+
+	if metadata&flagEmpty != 0 {
+		switch typeID {
+		case pointID:
+			return geom.NewPointFlat(d.layout, nil), nil, nil
+		case lineStringID:
+			return geom.NewLineString(d.layout), nil, nil
+		case polygonID:
+			return geom.NewPolygon(d.layout), nil, nil
+		case multiPointID:
+			return geom.NewMultiPoint(d.layout), nil, nil
+		case multiLineStringID:
+			return geom.NewMultiLineString(d.layout), nil, nil
+		case multiPolygonID:
+			return geom.NewMultiPolygon(d.layout), nil, nil
+		case geometryCollectionID:
+			return nil, nil, wkbcommon.ErrUnsupportedType(typeID)
+		default:
+			return nil, nil, ErrUnknownType(typeID)
+		}
+	}
+
+	if metadata&flagBBox != 0 {
+		for range d.precisions {
+			if _, err := d.readVarint(); err != nil {
+				return nil, nil, err
+			}
+			if _, err := d.readVarint(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	switch typeID {
+	case pointID:
+		flatCoords, err := d.readCoords(1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewPointFlat(d.layout, flatCoords), nil, nil
+	case lineStringID:
+		flatCoords, err := d.readCoords1()
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewLineStringFlat(d.layout, flatCoords), nil, nil
+	case polygonID:
+		flatCoords, ends, err := d.readCoords2(nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewPolygonFlat(d.layout, flatCoords, ends), nil, nil
+	case multiPointID:
+		n, ids, err := d.readCountAndIDs(metadata, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		flatCoords, err := d.readCoords(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewMultiPointFlat(d.layout, flatCoords), ids, nil
+	case multiLineStringID:
+		n, ids, err := d.readCountAndIDs(metadata, 2)
+		if err != nil {
+			return nil, nil, err
+		}
+		var flatCoords []float64
+		ends := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			fcs, err := d.readCoords1()
+			if err != nil {
+				return nil, nil, err
+			}
+			flatCoords = append(flatCoords, fcs...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(d.layout, flatCoords, ends), ids, nil
+	case multiPolygonID:
+		n, ids, err := d.readCountAndIDs(metadata, 3)
+		if err != nil {
+			return nil, nil, err
+		}
+		var flatCoords []float64
+		endss := make([][]int, 0, n)
+		for i := 0; i < n; i++ {
+			var ends []int
+			flatCoords, ends, err = d.readCoords2(flatCoords)
+			if err != nil {
+				return nil, nil, err
+			}
+			endss = append(endss, ends)
+		}
+		return geom.NewMultiPolygonFlat(d.layout, flatCoords, endss), ids, nil
+	case geometryCollectionID:
+		return nil, nil, wkbcommon.ErrUnsupportedType(typeID)
+	default:
+		return nil, nil, ErrUnknownType(typeID)
+	}
+}
+
+func (d *decoder) readUvarint() (uint64, error) {
+	u, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.pos += n
+	return u, nil
+}
+
+func (d *decoder) readVarint() (int64, error) {
+	u, err := d.readUvarint()
+	return unzigzag(u), err
+}
+
+// readCount reads a number of elements and checks it against the limit for
+// level.
+func (d *decoder) readCount(level int) (int, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if limit := wkbcommon.MaxGeometryElements[level]; n > uint64(limit) {
+		return 0, wkbcommon.ErrGeometryTooLarge{Level: level, N: uint32(n), Limit: limit}
+	}
+	return int(n), nil
+}
+
+func (d *decoder) readCountAndIDs(metadata byte, level int) (int, []int64, error) {
+	n, err := d.readCount(level)
+	if err != nil {
+		return 0, nil, err
+	}
+	if metadata&flagIDList == 0 {
+		return n, nil, nil
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		if ids[i], err = d.readVarint(); err != nil {
+			return 0, nil, err
+		}
+	}
+	return n, ids, nil
+}
+
+func (d *decoder) readCoords(n int) ([]float64, error) {
+	flatCoords := make([]float64, 0, n*len(d.precisions))
+	for i := 0; i < n; i++ {
+		for j, precision := range d.precisions {
+			delta, err := d.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			d.prev[j] += delta
+			flatCoords = append(flatCoords, dequantize(d.prev[j], precision))
+		}
+	}
+	return flatCoords, nil
+}
+
+func (d *decoder) readCoords1() ([]float64, error) {
+	n, err := d.readCount(1)
+	if err != nil {
+		return nil, err
+	}
+	return d.readCoords(n)
+}
+
+func (d *decoder) readCoords2(flatCoords []float64) ([]float64, []int, error) {
+	n, err := d.readCount(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	ends := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		fcs, err := d.readCoords1()
+		if err != nil {
+			return nil, nil, err
+		}
+		flatCoords = append(flatCoords, fcs...)
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends, nil
+}
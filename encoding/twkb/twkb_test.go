@@ -0,0 +1,177 @@
+package twkb
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func test(t *testing.T, g geom.T, opts []EncodeOption, twkb string) {
+	data, err := hex.DecodeString(twkb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Marshal(g, opts...); err != nil || !reflect.DeepEqual(got, data) {
+		t.Errorf("Marshal(%#v, ...) == %s, %v, want %s, nil", g, hex.EncodeToString(got), err, twkb)
+	}
+	if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, g) {
+		t.Errorf("Unmarshal(%s) == %#v, %v, want %#v, nil", twkb, got, err, g)
+	}
+}
+
+func TestPoint(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		opts []EncodeOption
+		twkb string
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			twkb: "01000204",
+		},
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{1.5, 2.5}),
+			opts: []EncodeOption{WithPrecision(1, 0, 0)},
+			twkb: "21001e32",
+		},
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{-1200, 3400}),
+			opts: []EncodeOption{WithPrecision(-2, 0, 0)},
+			twkb: "31001744",
+		},
+		{
+			g:    geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			twkb: "0108010204" + "06",
+		},
+		{
+			g:    geom.NewPointFlat(geom.XYM, []float64{1, 2, 0.5}),
+			opts: []EncodeOption{WithPrecision(0, 0, 1)},
+			twkb: "01082202040a",
+		},
+		{
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			twkb: "010803020406" + "08",
+		},
+		{
+			g:    geom.NewPointFlat(geom.XY, nil),
+			twkb: "0110",
+		},
+	} {
+		test(t, tc.g, tc.opts, tc.twkb)
+	}
+}
+
+func TestLineString(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		opts []EncodeOption
+		twkb string
+	}{
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 1, 5, 5}),
+			twkb: "02000202020808",
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 1, 5, 5}),
+			opts: []EncodeOption{WithBBox()},
+			twkb: "0201" + "02080208" + "0202020808",
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 1, 5, 5}),
+			opts: []EncodeOption{WithSize()},
+			twkb: "0202" + "05" + "0202020808",
+		},
+		{
+			g:    geom.NewLineString(geom.XY),
+			twkb: "0210",
+		},
+	} {
+		test(t, tc.g, tc.opts, tc.twkb)
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	test(t, geom.NewPolygonFlat(geom.XY, []float64{
+		0, 0, 2, 0, 2, 2, 0, 0,
+		1, 1, 1, 1.5, 1.5, 1, 1, 1,
+	}, []int{8, 16}), []EncodeOption{WithPrecision(1, 0, 0)},
+		"2300"+"02"+"04"+"0000"+"2800"+"0028"+"2727"+"04"+"1414"+"000a"+"0a09"+"0900")
+}
+
+func TestMulti(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		opts []EncodeOption
+		twkb string
+		ids  []int64
+	}{
+		{
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+			twkb: "0400" + "02" + "0204" + "0404",
+		},
+		{
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+			opts: []EncodeOption{WithIDs([]int64{10, -1})},
+			twkb: "0404" + "02" + "1401" + "0204" + "0404",
+			ids:  []int64{10, -1},
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			twkb: "0500" + "02" + "02" + "0000" + "0202" + "02" + "0202" + "0202",
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				2, 2, 3, 2, 3, 3, 2, 2,
+			}, [][]int{{8}, {16}}),
+			opts: []EncodeOption{WithIDs([]int64{1, 2})},
+			twkb: "0604" + "02" + "0204" +
+				"01" + "04" + "0000" + "0200" + "0002" + "0101" +
+				"01" + "04" + "0404" + "0200" + "0002" + "0101",
+			ids: []int64{1, 2},
+		},
+		{
+			g:    geom.NewMultiPolygon(geom.XYZ),
+			twkb: "061801",
+		},
+	} {
+		test(t, tc.g, tc.opts, tc.twkb)
+		data, _ := hex.DecodeString(tc.twkb)
+		if _, ids, err := UnmarshalWithIDs(data); err != nil || !reflect.DeepEqual(ids, tc.ids) {
+			t.Errorf("UnmarshalWithIDs(%s) == _, %v, %v, want _, %v, nil", tc.twkb, ids, err, tc.ids)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		opts []EncodeOption
+	}{
+		{g: geom.NewPointFlat(geom.XY, []float64{0, 0}), opts: []EncodeOption{WithPrecision(8, 0, 0)}},
+		{g: geom.NewPointFlat(geom.XY, []float64{0, 0}), opts: []EncodeOption{WithPrecision(0, -1, 0)}},
+		{g: geom.NewMultiPointFlat(geom.XY, []float64{0, 0}), opts: []EncodeOption{WithIDs([]int64{1, 2})}},
+		{g: geom.NewLinearRingFlat(geom.XY, []float64{0, 0})},
+	} {
+		if _, err := Marshal(tc.g, tc.opts...); err == nil {
+			t.Errorf("Marshal(%#v, ...) == _, nil, want _, !nil", tc.g)
+		}
+	}
+	for _, twkb := range []string{
+		"",
+		"01",
+		"0100",
+		"010002",
+		"0108",
+		"0f000000",
+		"07000000",
+		"02020a0202",
+	} {
+		data, _ := hex.DecodeString(twkb)
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("Unmarshal(%s) == _, nil, want _, !nil", twkb)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package wkb
 
 import (
+	"database/sql/driver"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/twpayne/go-geom"
@@ -21,135 +23,365 @@ type Point struct {
 	geom.Point
 }
 
+// A NullPoint is a WKB-encoded Point that may be NULL.
+type NullPoint struct {
+	Point
+	Valid bool
+}
+
 // A LineString is a WKB-encoded LineString.
 type LineString struct {
 	geom.LineString
 }
 
+// A NullLineString is a WKB-encoded LineString that may be NULL.
+type NullLineString struct {
+	LineString
+	Valid bool
+}
+
 // A Polygon is a WKB-encoded Polygon.
 type Polygon struct {
 	geom.Polygon
 }
 
+// A NullPolygon is a WKB-encoded Polygon that may be NULL.
+type NullPolygon struct {
+	Polygon
+	Valid bool
+}
+
 // A MultiPoint is a WKB-encoded MultiPoint.
 type MultiPoint struct {
 	geom.MultiPoint
 }
 
+// A NullMultiPoint is a WKB-encoded MultiPoint that may be NULL.
+type NullMultiPoint struct {
+	MultiPoint
+	Valid bool
+}
+
 // A MultiLineString is a WKB-encoded MultiLineString.
 type MultiLineString struct {
 	geom.MultiLineString
 }
 
+// A NullMultiLineString is a WKB-encoded MultiLineString that may be NULL.
+type NullMultiLineString struct {
+	MultiLineString
+	Valid bool
+}
+
 // A MultiPolygon is a WKB-encoded MultiPolygon.
 type MultiPolygon struct {
 	geom.MultiPolygon
 }
 
-// Scan scans from a []byte.
-func (p *Point) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return ErrExpectedByteSlice{Value: src}
+// A NullMultiPolygon is a WKB-encoded MultiPolygon that may be NULL.
+type NullMultiPolygon struct {
+	MultiPolygon
+	Valid bool
+}
+
+// A Geom is a WKB-encoded geometry of any type.  A NULL value is scanned
+// as a nil geom.T and a nil geom.T is stored as NULL.
+type Geom struct {
+	geom.T
+}
+
+// decode decodes src, which may be WKB or hex-encoded WKB text as
+// returned by some drivers.
+func decode(src interface{}) (geom.T, error) {
+	var b []byte
+	switch src := src.(type) {
+	case []byte:
+		// binary WKB always starts with a byte order of 0 or 1, hex-encoded
+		// WKB with the character '0'
+		if len(src) != 0 && src[0] == '0' {
+			data, err := hex.DecodeString(string(src))
+			if err != nil {
+				return nil, err
+			}
+			b = data
+		} else {
+			b = src
+		}
+	case string:
+		data, err := hex.DecodeString(src)
+		if err != nil {
+			return nil, err
+		}
+		b = data
+	default:
+		return nil, ErrExpectedByteSlice{Value: src}
 	}
-	got, err := Unmarshal(b)
+	return Unmarshal(b)
+}
+
+// value returns the WKB encoding of g.
+func value(g geom.T) (driver.Value, error) {
+	return Marshal(g, NDR)
+}
+
+// Scan scans from a []byte or a hex-encoded string.
+func (p *Point) Scan(src interface{}) error {
+	got, err := decode(src)
 	if err != nil {
 		return err
 	}
 	p1, ok := got.(*geom.Point)
 	if !ok {
-		return wkbcommon.ErrUnexpectedType{Got: p1, Want: p}
+		return wkbcommon.ErrUnexpectedType{Got: got, Want: p}
 	}
 	p.Swap(p1)
 	return nil
 }
 
-// Scan scans from a []byte.
-func (ls *LineString) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return ErrExpectedByteSlice{Value: src}
+// Value returns the WKB encoding of p.
+func (p Point) Value() (driver.Value, error) {
+	return value(&p.Point)
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (n *NullPoint) Scan(src interface{}) error {
+	if src == nil {
+		n.Point, n.Valid = Point{}, false
+		return nil
+	}
+	if err := n.Point.Scan(src); err != nil {
+		return err
 	}
-	got, err := Unmarshal(b)
+	n.Valid = true
+	return nil
+}
+
+// Value returns the WKB encoding of n, or NULL if n is not valid.
+func (n NullPoint) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Point.Value()
+}
+
+// Scan scans from a []byte or a hex-encoded string.
+func (ls *LineString) Scan(src interface{}) error {
+	got, err := decode(src)
 	if err != nil {
 		return err
 	}
-	p1, ok := got.(*geom.LineString)
+	ls1, ok := got.(*geom.LineString)
 	if !ok {
-		return wkbcommon.ErrUnexpectedType{Got: p1, Want: ls}
+		return wkbcommon.ErrUnexpectedType{Got: got, Want: ls}
 	}
-	ls.Swap(p1)
+	ls.Swap(ls1)
 	return nil
 }
 
-// Scan scans from a []byte.
-func (p *Polygon) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return ErrExpectedByteSlice{Value: src}
+// Value returns the WKB encoding of ls.
+func (ls LineString) Value() (driver.Value, error) {
+	return value(&ls.LineString)
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (n *NullLineString) Scan(src interface{}) error {
+	if src == nil {
+		n.LineString, n.Valid = LineString{}, false
+		return nil
 	}
-	got, err := Unmarshal(b)
+	if err := n.LineString.Scan(src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value returns the WKB encoding of n, or NULL if n is not valid.
+func (n NullLineString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.LineString.Value()
+}
+
+// Scan scans from a []byte or a hex-encoded string.
+func (p *Polygon) Scan(src interface{}) error {
+	got, err := decode(src)
 	if err != nil {
 		return err
 	}
 	p1, ok := got.(*geom.Polygon)
 	if !ok {
-		return wkbcommon.ErrUnexpectedType{Got: p1, Want: p}
+		return wkbcommon.ErrUnexpectedType{Got: got, Want: p}
 	}
 	p.Swap(p1)
 	return nil
 }
 
-// Scan scans from a []byte.
-func (mp *MultiPoint) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return ErrExpectedByteSlice{Value: src}
+// Value returns the WKB encoding of p.
+func (p Polygon) Value() (driver.Value, error) {
+	return value(&p.Polygon)
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (n *NullPolygon) Scan(src interface{}) error {
+	if src == nil {
+		n.Polygon, n.Valid = Polygon{}, false
+		return nil
 	}
-	got, err := Unmarshal(b)
+	if err := n.Polygon.Scan(src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value returns the WKB encoding of n, or NULL if n is not valid.
+func (n NullPolygon) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Polygon.Value()
+}
+
+// Scan scans from a []byte or a hex-encoded string.
+func (mp *MultiPoint) Scan(src interface{}) error {
+	got, err := decode(src)
 	if err != nil {
 		return err
 	}
 	mp1, ok := got.(*geom.MultiPoint)
 	if !ok {
-		return wkbcommon.ErrUnexpectedType{Got: mp1, Want: mp}
+		return wkbcommon.ErrUnexpectedType{Got: got, Want: mp}
 	}
 	mp.Swap(mp1)
 	return nil
 }
 
-// Scan scans from a []byte.
-func (mls *MultiLineString) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return ErrExpectedByteSlice{Value: src}
+// Value returns the WKB encoding of mp.
+func (mp MultiPoint) Value() (driver.Value, error) {
+	return value(&mp.MultiPoint)
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (n *NullMultiPoint) Scan(src interface{}) error {
+	if src == nil {
+		n.MultiPoint, n.Valid = MultiPoint{}, false
+		return nil
 	}
-	got, err := Unmarshal(b)
+	if err := n.MultiPoint.Scan(src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value returns the WKB encoding of n, or NULL if n is not valid.
+func (n NullMultiPoint) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.MultiPoint.Value()
+}
+
+// Scan scans from a []byte or a hex-encoded string.
+func (mls *MultiLineString) Scan(src interface{}) error {
+	got, err := decode(src)
 	if err != nil {
 		return err
 	}
 	mls1, ok := got.(*geom.MultiLineString)
 	if !ok {
-		return wkbcommon.ErrUnexpectedType{Got: mls1, Want: mls}
+		return wkbcommon.ErrUnexpectedType{Got: got, Want: mls}
 	}
 	mls.Swap(mls1)
 	return nil
 }
 
-// Scan scans from a []byte.
-func (mp *MultiPolygon) Scan(src interface{}) error {
-	b, ok := src.([]byte)
-	if !ok {
-		return ErrExpectedByteSlice{Value: src}
+// Value returns the WKB encoding of mls.
+func (mls MultiLineString) Value() (driver.Value, error) {
+	return value(&mls.MultiLineString)
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (n *NullMultiLineString) Scan(src interface{}) error {
+	if src == nil {
+		n.MultiLineString, n.Valid = MultiLineString{}, false
+		return nil
+	}
+	if err := n.MultiLineString.Scan(src); err != nil {
+		return err
 	}
-	got, err := Unmarshal(b)
+	n.Valid = true
+	return nil
+}
+
+// Value returns the WKB encoding of n, or NULL if n is not valid.
+func (n NullMultiLineString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.MultiLineString.Value()
+}
+
+// Scan scans from a []byte or a hex-encoded string.
+func (mp *MultiPolygon) Scan(src interface{}) error {
+	got, err := decode(src)
 	if err != nil {
 		return err
 	}
 	mp1, ok := got.(*geom.MultiPolygon)
 	if !ok {
-		return wkbcommon.ErrUnexpectedType{Got: mp1, Want: mp}
+		return wkbcommon.ErrUnexpectedType{Got: got, Want: mp}
 	}
 	mp.Swap(mp1)
 	return nil
 }
+
+// Value returns the WKB encoding of mp.
+func (mp MultiPolygon) Value() (driver.Value, error) {
+	return value(&mp.MultiPolygon)
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (n *NullMultiPolygon) Scan(src interface{}) error {
+	if src == nil {
+		n.MultiPolygon, n.Valid = MultiPolygon{}, false
+		return nil
+	}
+	if err := n.MultiPolygon.Scan(src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value returns the WKB encoding of n, or NULL if n is not valid.
+func (n NullMultiPolygon) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.MultiPolygon.Value()
+}
+
+// Scan scans from a []byte, a hex-encoded string, or NULL.
+func (g *Geom) Scan(src interface{}) error {
+	if src == nil {
+		g.T = nil
+		return nil
+	}
+	got, err := decode(src)
+	if err != nil {
+		return err
+	}
+	g.T = got
+	return nil
+}
+
+// Value returns the WKB encoding of g, or NULL if g is nil.
+func (g Geom) Value() (driver.Value, error) {
+	if g.T == nil {
+		return nil, nil
+	}
+	return value(g.T)
+}
@@ -0,0 +1,349 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// UnmarshalInto unmarshals data into g, which must be a *geom.Point,
+// *geom.LineString, *geom.Polygon, *geom.MultiPoint, *geom.MultiLineString, or
+// *geom.MultiPolygon of the same type as the encoded geometry.  The storage
+// for g's coordinates and ends is reused when it has sufficient capacity, so
+// repeatedly unmarshalling geometries into the same value does not allocate
+// once its storage has grown large enough.  Unlike Unmarshal, data is decoded
+// directly rather than through an io.Reader.  data is checked before g is
+// modified, so if an error is returned then g is unchanged.
+func UnmarshalInto(data []byte, g geom.T) error {
+	d := &decoder{data: data}
+	t, layout, err := d.header()
+	if err != nil {
+		return err
+	}
+	var want wkbcommon.Type
+	switch g.(type) {
+	case *geom.Point:
+		want = wkbcommon.PointID
+	case *geom.LineString:
+		want = wkbcommon.LineStringID
+	case *geom.Polygon:
+		want = wkbcommon.PolygonID
+	case *geom.MultiPoint:
+		want = wkbcommon.MultiPointID
+	case *geom.MultiLineString:
+		want = wkbcommon.MultiLineStringID
+	case *geom.MultiPolygon:
+		want = wkbcommon.MultiPolygonID
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	if t != want {
+		return unexpectedType(t, g)
+	}
+	// g's storage is reused as it is decoded, so check the whole of data
+	// first.
+	check := *d
+	if err := check.skip(t, layout); err != nil {
+		return err
+	}
+	stride := layout.Stride()
+	switch g := g.(type) {
+	case *geom.Point:
+		flatCoords, err := d.floats(g.FlatCoords()[:0], stride)
+		if err != nil {
+			return err
+		}
+		g.Swap(geom.NewPointFlat(layout, flatCoords))
+	case *geom.LineString:
+		flatCoords, err := d.flatCoords1(g.FlatCoords()[:0], stride)
+		if err != nil {
+			return err
+		}
+		g.Swap(geom.NewLineStringFlat(layout, flatCoords))
+	case *geom.Polygon:
+		flatCoords, ends, err := d.flatCoords2(g.FlatCoords()[:0], g.Ends()[:0], stride)
+		if err != nil {
+			return err
+		}
+		g.Swap(geom.NewPolygonFlat(layout, flatCoords, ends))
+	case *geom.MultiPoint:
+		n, err := d.count(1)
+		if err != nil {
+			return err
+		}
+		flatCoords := g.FlatCoords()[:0]
+		for i := 0; i < n; i++ {
+			if err := d.element(wkbcommon.PointID, layout, (*geom.Point)(nil)); err != nil {
+				return err
+			}
+			if flatCoords, err = d.floats(flatCoords, stride); err != nil {
+				return err
+			}
+		}
+		g.Swap(geom.NewMultiPointFlat(layout, flatCoords))
+	case *geom.MultiLineString:
+		n, err := d.count(2)
+		if err != nil {
+			return err
+		}
+		flatCoords, ends := g.FlatCoords()[:0], g.Ends()[:0]
+		for i := 0; i < n; i++ {
+			if err := d.element(wkbcommon.LineStringID, layout, (*geom.LineString)(nil)); err != nil {
+				return err
+			}
+			if flatCoords, err = d.flatCoords1(flatCoords, stride); err != nil {
+				return err
+			}
+			ends = append(ends, len(flatCoords))
+		}
+		g.Swap(geom.NewMultiLineStringFlat(layout, flatCoords, ends))
+	case *geom.MultiPolygon:
+		n, err := d.count(3)
+		if err != nil {
+			return err
+		}
+		flatCoords, endss := g.FlatCoords()[:0], g.Endss()
+		for i := 0; i < n; i++ {
+			if err := d.element(wkbcommon.PolygonID, layout, (*geom.Polygon)(nil)); err != nil {
+				return err
+			}
+			// reuse the existing ends of the i-th polygon, if any
+			var ends []int
+			if i < len(endss) {
+				ends = endss[i][:0]
+			}
+			if flatCoords, ends, err = d.flatCoords2(flatCoords, ends, stride); err != nil {
+				return err
+			}
+			if i < len(endss) {
+				endss[i] = ends
+			} else {
+				endss = append(endss, ends)
+			}
+		}
+		g.Swap(geom.NewMultiPolygonFlat(layout, flatCoords, endss[:n]))
+	}
+	return nil
+}
+
+// A decoder decodes WKB from a []byte.
+type decoder struct {
+	data         []byte
+	littleEndian bool
+}
+
+// header reads a byte order and a geometry type.
+func (d *decoder) header() (wkbcommon.Type, geom.Layout, error) {
+	if len(d.data) < 5 {
+		return 0, geom.NoLayout, io.ErrUnexpectedEOF
+	}
+	switch d.data[0] {
+	case wkbcommon.XDRID:
+		d.littleEndian = false
+	case wkbcommon.NDRID:
+		d.littleEndian = true
+	default:
+		return 0, geom.NoLayout, wkbcommon.ErrUnknownByteOrder(d.data[0])
+	}
+	d.data = d.data[1:]
+	u, _ := d.uint32()
+	t := wkbcommon.Type(u)
+	var layout geom.Layout
+	switch 1000 * (t / 1000) {
+	case wkbXYID:
+		layout = geom.XY
+	case wkbXYZID:
+		layout = geom.XYZ
+	case wkbXYMID:
+		layout = geom.XYM
+	case wkbXYZMID:
+		layout = geom.XYZM
+	default:
+		return 0, geom.NoLayout, wkbcommon.ErrUnknownType(t)
+	}
+	switch t % 1000 {
+	case wkbcommon.PointID, wkbcommon.LineStringID, wkbcommon.PolygonID, wkbcommon.MultiPointID, wkbcommon.MultiLineStringID, wkbcommon.MultiPolygonID:
+		return t % 1000, layout, nil
+	default:
+		return 0, geom.NoLayout, wkbcommon.ErrUnsupportedType(u)
+	}
+}
+
+// element reads the header of an element of a multi-geometry, which must have
+// type t and the given layout.  want is used only for error reporting.
+func (d *decoder) element(t wkbcommon.Type, layout geom.Layout, want geom.T) error {
+	et, elayout, err := d.header()
+	if err != nil {
+		return err
+	}
+	if et != t {
+		return unexpectedType(et, want)
+	}
+	if elayout != layout {
+		return geom.ErrLayoutMismatch{Got: elayout, Want: layout}
+	}
+	return nil
+}
+
+// skip reads a geometry of type t with the given layout, without its header,
+// and discards it.
+func (d *decoder) skip(t wkbcommon.Type, layout geom.Layout) error {
+	stride := layout.Stride()
+	switch t {
+	case wkbcommon.PointID:
+		return d.skipFloats(stride)
+	case wkbcommon.LineStringID:
+		n, err := d.count(1)
+		if err != nil {
+			return err
+		}
+		return d.skipFloats(n * stride)
+	case wkbcommon.PolygonID:
+		n, err := d.count(2)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := d.skip(wkbcommon.LineStringID, layout); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var level int
+	var et wkbcommon.Type
+	var want geom.T
+	switch t {
+	case wkbcommon.MultiPointID:
+		level, et, want = 1, wkbcommon.PointID, (*geom.Point)(nil)
+	case wkbcommon.MultiLineStringID:
+		level, et, want = 2, wkbcommon.LineStringID, (*geom.LineString)(nil)
+	case wkbcommon.MultiPolygonID:
+		level, et, want = 3, wkbcommon.PolygonID, (*geom.Polygon)(nil)
+	}
+	n, err := d.count(level)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := d.element(et, layout, want); err != nil {
+			return err
+		}
+		if err := d.skip(et, layout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipFloats discards n floats.
+func (d *decoder) skipFloats(n int) error {
+	if len(d.data) < 8*n {
+		return io.ErrUnexpectedEOF
+	}
+	d.data = d.data[8*n:]
+	return nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if len(d.data) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var u uint32
+	if d.littleEndian {
+		u = binary.LittleEndian.Uint32(d.data)
+	} else {
+		u = binary.BigEndian.Uint32(d.data)
+	}
+	d.data = d.data[4:]
+	return u, nil
+}
+
+// count reads a number of elements at level.
+func (d *decoder) count(level int) (int, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if n > wkbcommon.MaxGeometryElements[level] {
+		return 0, wkbcommon.ErrGeometryTooLarge{Level: level, N: n, Limit: wkbcommon.MaxGeometryElements[level]}
+	}
+	return int(n), nil
+}
+
+// floats appends n floats to dst.
+func (d *decoder) floats(dst []float64, n int) ([]float64, error) {
+	if len(d.data) < 8*n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if cap(dst)-len(dst) < n {
+		size := 2 * cap(dst)
+		if size < len(dst)+n {
+			size = len(dst) + n
+		}
+		newDst := make([]float64, len(dst), size)
+		copy(newDst, dst)
+		dst = newDst
+	}
+	data := d.data[:8*n]
+	if d.littleEndian {
+		for i := 0; i < len(data); i += 8 {
+			dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(data[i:])))
+		}
+	} else {
+		for i := 0; i < len(data); i += 8 {
+			dst = append(dst, math.Float64frombits(binary.BigEndian.Uint64(data[i:])))
+		}
+	}
+	d.data = d.data[8*n:]
+	return dst, nil
+}
+
+// flatCoords1 appends a count-prefixed sequence of coordinates to flatCoords.
+func (d *decoder) flatCoords1(flatCoords []float64, stride int) ([]float64, error) {
+	n, err := d.count(1)
+	if err != nil {
+		return nil, err
+	}
+	return d.floats(flatCoords, n*stride)
+}
+
+// flatCoords2 appends a count-prefixed sequence of sequences of coordinates to
+// flatCoords and their ends to ends.
+func (d *decoder) flatCoords2(flatCoords []float64, ends []int, stride int) ([]float64, []int, error) {
+	n, err := d.count(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 0; i < n; i++ {
+		if flatCoords, err = d.flatCoords1(flatCoords, stride); err != nil {
+			return nil, nil, err
+		}
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends, nil
+}
+
+// unexpectedType returns an ErrUnexpectedType for an encoded type t when want
+// was wanted.
+func unexpectedType(t wkbcommon.Type, want geom.T) error {
+	var got geom.T
+	switch t {
+	case wkbcommon.PointID:
+		got = (*geom.Point)(nil)
+	case wkbcommon.LineStringID:
+		got = (*geom.LineString)(nil)
+	case wkbcommon.PolygonID:
+		got = (*geom.Polygon)(nil)
+	case wkbcommon.MultiPointID:
+		got = (*geom.MultiPoint)(nil)
+	case wkbcommon.MultiLineStringID:
+		got = (*geom.MultiLineString)(nil)
+	case wkbcommon.MultiPolygonID:
+		got = (*geom.MultiPolygon)(nil)
+	}
+	return wkbcommon.ErrUnexpectedType{Got: got, Want: want}
+}
@@ -1,7 +1,9 @@
 package wkb
 
 import (
+	"encoding/binary"
 	"encoding/hex"
+	"io"
 	"reflect"
 	"testing"
 
@@ -246,6 +248,49 @@ func BenchmarkUnmarshal(b *testing.B) {
 	}
 }
 
+func mustMarshal(g geom.T, byteOrder binary.ByteOrder) []byte {
+	data, err := Marshal(g, byteOrder)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// newZero returns a new zero value of the same type as g.
+func newZero(g geom.T) geom.T {
+	switch g.(type) {
+	case *geom.Point:
+		return &geom.Point{}
+	case *geom.LineString:
+		return &geom.LineString{}
+	case *geom.Polygon:
+		return &geom.Polygon{}
+	case *geom.MultiPoint:
+		return &geom.MultiPoint{}
+	case *geom.MultiLineString:
+		return &geom.MultiLineString{}
+	case *geom.MultiPolygon:
+		return &geom.MultiPolygon{}
+	default:
+		return nil
+	}
+}
+
+func BenchmarkUnmarshalInto(b *testing.B) {
+	gs := make([]geom.T, len(testdata.Random))
+	for i, tc := range testdata.Random {
+		gs[i] = newZero(tc.G)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i, tc := range testdata.Random {
+			if err := UnmarshalInto(tc.WKB, gs[i]); err != nil {
+				b.Errorf("unmarshal error %v", err)
+			}
+		}
+	}
+}
+
 func BenchmarkMarshal(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		for _, tc := range testdata.Random {
@@ -288,3 +333,87 @@ func TestCrashes(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalInto(t *testing.T) {
+	for i, tc := range testdata.Random {
+		want, err := Unmarshal(tc.WKB)
+		if err != nil {
+			t.Fatalf("%d: Unmarshal(...) == _, %v, want _, <nil>", i, err)
+		}
+		for _, data := range [][]byte{tc.WKB, mustMarshal(tc.G, XDR)} {
+			// decode twice to check that reused storage is overwritten
+			g := newZero(tc.G)
+			for j := 0; j < 2; j++ {
+				if err := UnmarshalInto(data, g); err != nil {
+					t.Fatalf("%d: UnmarshalInto(...) == %v, want <nil>", i, err)
+				}
+				if g.Layout() != want.Layout() ||
+					!reflect.DeepEqual(mustMarshal(g, NDR), mustMarshal(want, NDR)) {
+					t.Errorf("%d: UnmarshalInto(...) got %v, want %v", i, g, want)
+				}
+			}
+			if allocs := testing.AllocsPerRun(10, func() {
+				if err := UnmarshalInto(data, g); err != nil {
+					t.Fatal(err)
+				}
+			}); allocs != 0 {
+				t.Errorf("%d: UnmarshalInto(...) allocated %v times, want 0", i, allocs)
+			}
+		}
+	}
+}
+
+func TestUnmarshalIntoErrors(t *testing.T) {
+	point := mustMarshal(geom.NewPointFlat(geom.XY, []float64{1, 2}), NDR)
+	multiPoint := mustMarshal(geom.NewMultiPointFlat(geom.XY, []float64{1, 2}), NDR)
+	for i, tc := range []struct {
+		data []byte
+		g    geom.T
+		want error
+	}{
+		{data: nil, g: &geom.Point{}, want: io.ErrUnexpectedEOF},
+		{data: point[:len(point)-1], g: &geom.Point{}, want: io.ErrUnexpectedEOF},
+		{data: point, g: &geom.LineString{}, want: wkbcommon.ErrUnexpectedType{Got: (*geom.Point)(nil), Want: &geom.LineString{}}},
+		{data: point, g: &geom.LinearRing{}, want: geom.ErrUnsupportedType{Value: &geom.LinearRing{}}},
+		{data: append([]byte{2}, point[1:]...), g: &geom.Point{}, want: wkbcommon.ErrUnknownByteOrder(2)},
+		{data: []byte("\x01\x07\x00\x00\x00\x00\x00\x00\x00"), g: &geom.Point{}, want: wkbcommon.ErrUnsupportedType(7)},
+		{data: multiPoint[:len(multiPoint)-1], g: &geom.MultiPoint{}, want: io.ErrUnexpectedEOF},
+		{
+			data: []byte("\x01\x02\x00\x00\x00\xff\xff\xff\xff"),
+			g:    &geom.LineString{},
+			want: wkbcommon.ErrGeometryTooLarge{Level: 1, N: 0xffffffff, Limit: wkbcommon.MaxGeometryElements[1]},
+		},
+	} {
+		if err := UnmarshalInto(tc.data, tc.g); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: UnmarshalInto(%v, %T) == %v, want %v", i, tc.data, tc.g, err, tc.want)
+		}
+	}
+}
+
+func TestUnmarshalIntoErrorUnchanged(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		data []byte
+	}{
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			data: mustMarshal(geom.NewLineStringFlat(geom.XY, []float64{5, 6, 7, 8}), NDR),
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			data: mustMarshal(geom.NewPolygonFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1}, []int{8, 16}), XDR),
+		},
+		{
+			g:    geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 2, 2, 3, 2, 3, 3, 2, 2}, [][]int{{8}, {16}}),
+			data: mustMarshal(geom.NewMultiPolygonFlat(geom.XY, []float64{4, 4, 5, 4, 5, 5, 4, 4, 6, 6, 7, 6, 7, 7, 6, 6}, [][]int{{8}, {16}}), NDR),
+		},
+	} {
+		want := mustMarshal(tc.g, NDR)
+		if err := UnmarshalInto(tc.data[:len(tc.data)-1], tc.g); err != io.ErrUnexpectedEOF {
+			t.Errorf("%d: UnmarshalInto(...) == %v, want %v", i, err, io.ErrUnexpectedEOF)
+		}
+		if got := mustMarshal(tc.g, NDR); !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: UnmarshalInto(...) modified g to %v", i, tc.g)
+		}
+	}
+}
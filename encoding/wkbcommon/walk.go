@@ -0,0 +1,306 @@
+package wkbcommon
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Layout and SRID flags used in WKB and EWKB geometry types.
+const (
+	wkbZ     = 1000
+	wkbM     = 2000
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// A Visitor receives callbacks from Walk.  Any of its fields may be nil.  If
+// a callback returns a non-nil error then Walk stops and returns that error.
+type Visitor struct {
+	// BeginGeometry is called at the start of each geometry, including each
+	// element of a multi-geometry, with its type (without any layout or SRID
+	// flags), layout, and SRID.  The SRID is zero if it is not present.
+	BeginGeometry func(t Type, layout geom.Layout, srid int) error
+	// EndGeometry is called at the end of each geometry.
+	EndGeometry func() error
+	// BeginCoords is called at the start of each sequence of coordinates, that
+	// is the coordinate of a Point, the coordinates of a LineString, or the
+	// coordinates of a ring of a Polygon, with the number of coordinates.
+	BeginCoords func(n int) error
+	// Coord is called with each coordinate.  coord is only valid until Coord
+	// returns.
+	Coord func(coord []float64) error
+}
+
+// A walker walks WKB or EWKB in a []byte.
+type walker struct {
+	data         []byte
+	littleEndian bool
+	v            *Visitor
+	coord        []float64
+	// bounds, if not nil, is extended with each coordinate instead of calling
+	// v.Coord.
+	bounds *boundsAccumulator
+}
+
+// A boundsAccumulator accumulates the bounds of coordinates.
+type boundsAccumulator struct {
+	min, max [4]float64
+	empty    bool
+}
+
+// Walk walks the WKB or EWKB-encoded geometry in data, calling v's callbacks,
+// without constructing any geometries.  Both ISO WKB layout types (e.g. 1001
+// for a Point Z) and EWKB layout and SRID flags are understood.  All elements
+// of a multi-geometry must have the same layout as the multi-geometry.  A nil
+// v is treated as a Visitor with no callbacks, which only checks data.
+func Walk(data []byte, v *Visitor) error {
+	if v == nil {
+		v = &Visitor{}
+	}
+	w := &walker{data: data, v: v}
+	_, err := w.walk(geom.NoLayout)
+	return err
+}
+
+// Bounds returns the bounds of the WKB or EWKB-encoded geometry in data,
+// without constructing the geometry.
+func Bounds(data []byte) (*geom.Bounds, error) {
+	b := &boundsAccumulator{empty: true}
+	w := &walker{data: data, v: &Visitor{}, bounds: b}
+	layout, err := w.walk(geom.NoLayout)
+	if err != nil {
+		return nil, err
+	}
+	bounds := geom.NewBounds(layout)
+	if b.empty {
+		return bounds, nil
+	}
+	stride := layout.Stride()
+	return bounds.SetCoords(b.min[:stride], b.max[:stride]), nil
+}
+
+// NumCoords returns the total number of coordinates in the WKB or
+// EWKB-encoded geometry in data, without constructing the geometry.
+func NumCoords(data []byte) (int, error) {
+	n := 0
+	v := &Visitor{
+		BeginCoords: func(m int) error {
+			n += m
+			return nil
+		},
+	}
+	if err := Walk(data, v); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// header reads a byte order, geometry type, and optional SRID.
+func (w *walker) header() (Type, geom.Layout, int, error) {
+	if len(w.data) < 5 {
+		return 0, geom.NoLayout, 0, io.ErrUnexpectedEOF
+	}
+	switch w.data[0] {
+	case XDRID:
+		w.littleEndian = false
+	case NDRID:
+		w.littleEndian = true
+	default:
+		return 0, geom.NoLayout, 0, ErrUnknownByteOrder(w.data[0])
+	}
+	w.data = w.data[1:]
+	u, _ := w.uint32()
+	t := Type(u)
+
+	var layout geom.Layout
+	srid := 0
+	if flags := t & (ewkbZ | ewkbM | ewkbSRID); flags != 0 {
+		switch flags & (ewkbZ | ewkbM) {
+		case 0:
+			layout = geom.XY
+		case ewkbZ:
+			layout = geom.XYZ
+		case ewkbM:
+			layout = geom.XYM
+		default:
+			layout = geom.XYZM
+		}
+		if flags&ewkbSRID != 0 {
+			s, err := w.uint32()
+			if err != nil {
+				return 0, geom.NoLayout, 0, err
+			}
+			srid = int(s)
+		}
+		t &^= ewkbZ | ewkbM | ewkbSRID
+	} else {
+		switch 1000 * (t / 1000) {
+		case 0:
+			layout = geom.XY
+		case wkbZ:
+			layout = geom.XYZ
+		case wkbM:
+			layout = geom.XYM
+		case wkbZ + wkbM:
+			layout = geom.XYZM
+		default:
+			return 0, geom.NoLayout, 0, ErrUnknownType(t)
+		}
+		t %= 1000
+	}
+	return t, layout, srid, nil
+}
+
+// walk walks a single geometry and returns its layout.  If parentLayout is
+// not geom.NoLayout then the geometry's layout must match it.
+func (w *walker) walk(parentLayout geom.Layout) (geom.Layout, error) {
+	t, layout, srid, err := w.header()
+	if err != nil {
+		return geom.NoLayout, err
+	}
+	if parentLayout != geom.NoLayout && layout != parentLayout {
+		return geom.NoLayout, geom.ErrLayoutMismatch{Got: layout, Want: parentLayout}
+	}
+	if w.v.BeginGeometry != nil {
+		if err := w.v.BeginGeometry(t, layout, srid); err != nil {
+			return geom.NoLayout, err
+		}
+	}
+	stride := layout.Stride()
+	switch t {
+	case PointID:
+		err = w.coords(1, stride)
+	case LineStringID:
+		var n int
+		if n, err = w.count(1); err == nil {
+			err = w.coords(n, stride)
+		}
+	case PolygonID:
+		err = w.rings(stride)
+	case MultiPointID, MultiLineStringID, MultiPolygonID:
+		level := 1
+		switch t {
+		case MultiLineStringID:
+			level = 2
+		case MultiPolygonID:
+			level = 3
+		}
+		var n int
+		if n, err = w.count(level); err == nil {
+			for i := 0; i < n && err == nil; i++ {
+				_, err = w.walk(layout)
+			}
+		}
+	default:
+		return geom.NoLayout, ErrUnsupportedType(t)
+	}
+	if err != nil {
+		return geom.NoLayout, err
+	}
+	if w.v.EndGeometry != nil {
+		if err := w.v.EndGeometry(); err != nil {
+			return geom.NoLayout, err
+		}
+	}
+	return layout, nil
+}
+
+func (w *walker) uint32() (uint32, error) {
+	if len(w.data) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var u uint32
+	if w.littleEndian {
+		u = binary.LittleEndian.Uint32(w.data)
+	} else {
+		u = binary.BigEndian.Uint32(w.data)
+	}
+	w.data = w.data[4:]
+	return u, nil
+}
+
+// count reads a number of elements at level.
+func (w *walker) count(level int) (int, error) {
+	n, err := w.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if n > MaxGeometryElements[level] {
+		return 0, ErrGeometryTooLarge{Level: level, N: n, Limit: MaxGeometryElements[level]}
+	}
+	return int(n), nil
+}
+
+// rings walks the rings of a Polygon.
+func (w *walker) rings(stride int) error {
+	n, err := w.count(2)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		m, err := w.count(1)
+		if err != nil {
+			return err
+		}
+		if err := w.coords(m, stride); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// coords walks n coordinates.
+func (w *walker) coords(n, stride int) error {
+	size := 8 * n * stride
+	if len(w.data) < size {
+		return io.ErrUnexpectedEOF
+	}
+	if w.v.BeginCoords != nil {
+		if err := w.v.BeginCoords(n); err != nil {
+			return err
+		}
+	}
+	data := w.data[:size]
+	w.data = w.data[size:]
+	switch {
+	case w.bounds != nil:
+		b := w.bounds
+		for i := 0; i < size; i += 8 * stride {
+			for j := 0; j < stride; j++ {
+				x := w.float64(data[i+8*j:])
+				if b.empty {
+					b.min[j], b.max[j] = x, x
+				} else {
+					b.min[j] = math.Min(b.min[j], x)
+					b.max[j] = math.Max(b.max[j], x)
+				}
+			}
+			b.empty = false
+		}
+	case w.v.Coord != nil:
+		if cap(w.coord) < stride {
+			w.coord = make([]float64, stride)
+		}
+		coord := w.coord[:stride]
+		for i := 0; i < size; i += 8 * stride {
+			for j := range coord {
+				coord[j] = w.float64(data[i+8*j:])
+			}
+			if err := w.v.Coord(coord); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *walker) float64(data []byte) float64 {
+	if w.littleEndian {
+		return math.Float64frombits(binary.LittleEndian.Uint64(data))
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(data))
+}
@@ -0,0 +1,130 @@
+package wkbcommon_test
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/testdata"
+)
+
+func TestBoundsAndNumCoords(t *testing.T) {
+	for i, tc := range testdata.Random {
+		if got, err := wkbcommon.Bounds(tc.WKB); err != nil || !reflect.DeepEqual(got, tc.G.Bounds()) {
+			t.Errorf("%d: Bounds(...) == %v, %v, want %v, <nil>", i, got, err, tc.G.Bounds())
+		}
+		want := len(tc.G.FlatCoords()) / tc.G.Stride()
+		if got, err := wkbcommon.NumCoords(tc.WKB); err != nil || got != want {
+			t.Errorf("%d: NumCoords(...) == %d, %v, want %d, <nil>", i, got, err, want)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	g := geom.NewMultiPolygonFlat(geom.XYZ, []float64{
+		0, 0, 1, 1, 0, 2, 1, 1, 3, 0, 0, 1,
+		5, 5, 4, 6, 5, 5, 6, 6, 6, 5, 5, 4,
+	}, [][]int{{12}, {24}}).SetSRID(4326)
+	for i, data := range [][]byte{
+		mustMarshal(ewkb.Marshal(g, ewkb.NDR)),
+		mustMarshal(ewkb.Marshal(g, ewkb.XDR)),
+		mustMarshal(wkb.Marshal(g, wkb.NDR)),
+	} {
+		var events []interface{}
+		v := &wkbcommon.Visitor{
+			BeginGeometry: func(t wkbcommon.Type, layout geom.Layout, srid int) error {
+				events = append(events, []interface{}{t, layout, srid})
+				return nil
+			},
+			EndGeometry: func() error {
+				events = append(events, "end")
+				return nil
+			},
+			BeginCoords: func(n int) error {
+				events = append(events, n)
+				return nil
+			},
+			Coord: func(coord []float64) error {
+				events = append(events, append([]float64(nil), coord...))
+				return nil
+			},
+		}
+		if err := wkbcommon.Walk(data, v); err != nil {
+			t.Errorf("%d: Walk(...) == %v, want <nil>", i, err)
+			continue
+		}
+		srid := 4326
+		if i == 2 {
+			srid = 0
+		}
+		want := []interface{}{
+			[]interface{}{wkbcommon.Type(wkbcommon.MultiPolygonID), geom.XYZ, srid},
+			[]interface{}{wkbcommon.Type(wkbcommon.PolygonID), geom.XYZ, 0},
+			4,
+			[]float64{0, 0, 1}, []float64{1, 0, 2}, []float64{1, 1, 3}, []float64{0, 0, 1},
+			"end",
+			[]interface{}{wkbcommon.Type(wkbcommon.PolygonID), geom.XYZ, 0},
+			4,
+			[]float64{5, 5, 4}, []float64{6, 5, 5}, []float64{6, 6, 6}, []float64{5, 5, 4},
+			"end",
+			"end",
+		}
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("%d: Walk(...) visited %v, want %v", i, events, want)
+		}
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	data := mustMarshal(wkb.Marshal(geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}), wkb.NDR))
+	for i, tc := range []struct {
+		data []byte
+		want error
+	}{
+		{data: nil, want: io.ErrUnexpectedEOF},
+		{data: data[:len(data)-1], want: io.ErrUnexpectedEOF},
+		{data: []byte("\x02\x01\x00\x00\x00"), want: wkbcommon.ErrUnknownByteOrder(2)},
+		{data: []byte("\x01\x07\x00\x00\x00\x00\x00\x00\x00"), want: wkbcommon.ErrUnsupportedType(7)},
+		{data: []byte("\x01\x01\x10\x00\x00"), want: wkbcommon.ErrUnknownType(0x1001)},
+		{
+			data: []byte("\x01\x04\x00\x00\x00\x01\x00\x00\x00\x01\xe9\x03\x00\x00"),
+			want: geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+	} {
+		if err := wkbcommon.Walk(tc.data, &wkbcommon.Visitor{}); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: Walk(%v, ...) == %v, want %v", i, tc.data, err, tc.want)
+		}
+	}
+}
+
+func TestWalkNilVisitor(t *testing.T) {
+	for i, tc := range testdata.Random {
+		if err := wkbcommon.Walk(tc.WKB, nil); err != nil {
+			t.Errorf("%d: Walk(..., nil) == %v, want <nil>", i, err)
+		}
+	}
+	if err := wkbcommon.Walk(nil, nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("Walk(nil, nil) == %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func mustMarshal(data []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func BenchmarkBounds(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		for _, tc := range testdata.Random {
+			if _, err := wkbcommon.Bounds(tc.WKB); err != nil {
+				b.Errorf("bounds error %v", err)
+			}
+		}
+	}
+}
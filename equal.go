@@ -0,0 +1,85 @@
+package geom
+
+import (
+	"math"
+	"reflect"
+)
+
+// Equal returns true if g1 and g2 are exactly equal, i.e. they have the same
+// type, layout, SRID, and structure, and identical coordinates.  NaN
+// ordinates are equal to each other.
+func Equal(g1, g2 T) bool {
+	return equal(g1, g2, func(x, y float64) bool {
+		return x == y || math.IsNaN(x) && math.IsNaN(y)
+	})
+}
+
+// EqualTolerance returns true if g1 and g2 have the same type, layout, SRID,
+// and structure, and each of their corresponding ordinates differ by at most
+// tolerance.
+func EqualTolerance(g1, g2 T, tolerance float64) bool {
+	return equal(g1, g2, func(x, y float64) bool {
+		return math.Abs(x-y) <= tolerance || math.IsNaN(x) && math.IsNaN(y)
+	})
+}
+
+// EqualNormalized returns true if the normalized forms of g1 and g2 are
+// exactly equal, see Normalize.  It returns false if either g1 or g2 cannot be
+// normalized.
+func EqualNormalized(g1, g2 T) bool {
+	n1, err := Normalize(g1)
+	if err != nil {
+		return false
+	}
+	n2, err := Normalize(g2)
+	if err != nil {
+		return false
+	}
+	return Equal(n1, n2)
+}
+
+func equal(g1, g2 T, ordinateEqual func(float64, float64) bool) bool {
+	if g1 == nil || g2 == nil {
+		return g1 == nil && g2 == nil
+	}
+	if reflect.TypeOf(g1) != reflect.TypeOf(g2) {
+		return false
+	}
+	if g1.Layout() != g2.Layout() || g1.SRID() != g2.SRID() {
+		return false
+	}
+	if !intsEqual(g1.Ends(), g2.Ends()) {
+		return false
+	}
+	endss1, endss2 := g1.Endss(), g2.Endss()
+	if len(endss1) != len(endss2) {
+		return false
+	}
+	for i := range endss1 {
+		if !intsEqual(endss1[i], endss2[i]) {
+			return false
+		}
+	}
+	flatCoords1, flatCoords2 := g1.FlatCoords(), g2.FlatCoords()
+	if len(flatCoords1) != len(flatCoords2) {
+		return false
+	}
+	for i := range flatCoords1 {
+		if !ordinateEqual(flatCoords1[i], flatCoords2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	for i, tc := range []struct {
+		g1, g2 T
+		want   bool
+	}{
+		{
+			g1:   NewPointFlat(XY, []float64{1, 2}),
+			g2:   NewPointFlat(XY, []float64{1, 2}),
+			want: true,
+		},
+		{
+			g1:   NewPointFlat(XY, []float64{1, 2}),
+			g2:   NewPointFlat(XY, []float64{1, 3}),
+			want: false,
+		},
+		{
+			g1:   NewPointFlat(XY, []float64{1, 2}),
+			g2:   NewPointFlat(XYM, []float64{1, 2, 0}),
+			want: false,
+		},
+		{
+			g1:   NewPointFlat(XY, []float64{1, 2}).SetSRID(4326),
+			g2:   NewPointFlat(XY, []float64{1, 2}),
+			want: false,
+		},
+		{
+			g1:   NewPointFlat(XY, []float64{math.NaN(), math.NaN()}),
+			g2:   NewPointFlat(XY, []float64{math.NaN(), math.NaN()}),
+			want: true,
+		},
+		{
+			g1:   NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			g2:   NewMultiPointFlat(XY, []float64{0, 0, 1, 1}),
+			want: false,
+		},
+		{
+			g1:   NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			g2:   NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2}),
+			want: false,
+		},
+		{
+			g1:   NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			g2:   NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			want: true,
+		},
+		{
+			g1:   NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			g2:   NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{2, 8}),
+			want: false,
+		},
+		{
+			g1:   NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+			g2:   NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}, {}}),
+			want: false,
+		},
+		{
+			g1:   nil,
+			g2:   NewPoint(XY),
+			want: false,
+		},
+		{
+			g1:   nil,
+			g2:   nil,
+			want: true,
+		},
+	} {
+		if got := Equal(tc.g1, tc.g2); got != tc.want {
+			t.Errorf("%d: Equal(%v, %v) == %v, want %v", i, tc.g1, tc.g2, got, tc.want)
+		}
+	}
+}
+
+func TestEqualTolerance(t *testing.T) {
+	for i, tc := range []struct {
+		g1, g2    T
+		tolerance float64
+		want      bool
+	}{
+		{
+			g1:        NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			g2:        NewLineStringFlat(XY, []float64{0.05, 0, 1, 0.95}),
+			tolerance: 0.1,
+			want:      true,
+		},
+		{
+			g1:        NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			g2:        NewLineStringFlat(XY, []float64{0.05, 0, 1, 0.85}),
+			tolerance: 0.1,
+			want:      false,
+		},
+		{
+			g1:        NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			g2:        NewLinearRingFlat(XY, []float64{0, 0, 1, 1}),
+			tolerance: 0.1,
+			want:      false,
+		},
+	} {
+		if got := EqualTolerance(tc.g1, tc.g2, tc.tolerance); got != tc.want {
+			t.Errorf("%d: EqualTolerance(%v, %v, %v) == %v, want %v", i, tc.g1, tc.g2, tc.tolerance, got, tc.want)
+		}
+	}
+}
+
+func TestEqualNormalized(t *testing.T) {
+	for i, tc := range []struct {
+		g1, g2 T
+		want   bool
+	}{
+		{
+			g1:   NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+			g2:   NewLineStringFlat(XY, []float64{2, 0, 1, 1, 0, 0}),
+			want: true,
+		},
+		{
+			g1:   NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, []int{10}),
+			g2:   NewPolygonFlat(XY, []float64{1, 1, 1, 0, 0, 0, 0, 1, 1, 1}, []int{10}),
+			want: true,
+		},
+		{
+			g1:   NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, []int{10}),
+			g2:   NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 2, 0, 1, 0, 0}, []int{10}),
+			want: false,
+		},
+		{
+			g1:   NewMultiPointFlat(XY, []float64{0, 0, 1, 1}),
+			g2:   NewMultiPointFlat(XY, []float64{1, 1, 0, 0}),
+			want: true,
+		},
+	} {
+		if got := EqualNormalized(tc.g1, tc.g2); got != tc.want {
+			t.Errorf("%d: EqualNormalized(%v, %v) == %v, want %v", i, tc.g1, tc.g2, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package cover contains the parts of covering geometries with cells that
+// are shared by the geohash and s2cell packages.
+package cover
+
+import (
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+	"github.com/twpayne/go-geom/xy"
+)
+
+// A Shape is the lines and polygons of a geometry that is covered with cells.
+type Shape struct {
+	Layout geom.Layout
+	Stride int
+	// Lines contains the flat coordinates of all line strings and rings.
+	Lines [][]float64
+	// Polygons contains the flat coordinates of the rings of each polygon.
+	Polygons [][][]float64
+}
+
+// NewShape returns the Shape of g, which must be a *geom.LineString,
+// *geom.LinearRing, *geom.MultiLineString, *geom.Polygon, or
+// *geom.MultiPolygon.
+func NewShape(g geom.T) (*Shape, error) {
+	s := &Shape{
+		Layout: g.Layout(),
+		Stride: g.Stride(),
+	}
+	switch g := g.(type) {
+	case *geom.LineString:
+		s.Lines = [][]float64{g.FlatCoords()}
+	case *geom.LinearRing:
+		s.Lines = [][]float64{g.FlatCoords()}
+	case *geom.MultiLineString:
+		s.Lines = flat.Split(g.FlatCoords(), 0, g.Ends())
+	case *geom.Polygon:
+		s.addPolygon(g.FlatCoords(), 0, g.Ends())
+	case *geom.MultiPolygon:
+		offset := 0
+		for _, ends := range g.Endss() {
+			s.addPolygon(g.FlatCoords(), offset, ends)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	return s, nil
+}
+
+// addPolygon adds the polygon in flatCoords starting at offset with the given
+// ends to s.
+func (s *Shape) addPolygon(flatCoords []float64, offset int, ends []int) {
+	if len(ends) == 0 {
+		return
+	}
+	rings := flat.Split(flatCoords, offset, ends)
+	s.Lines = append(s.Lines, rings...)
+	s.Polygons = append(s.Polygons, rings)
+}
+
+// InPolygon returns true if p, which must have s's stride, is inside
+// the exterior ring and outside the holes of any of s's polygons.
+func (s *Shape) InPolygon(p geom.Coord) bool {
+	for _, rings := range s.Polygons {
+		if !xy.IsPointInRing(s.Layout, p, rings[0]) {
+			continue
+		}
+		inHole := false
+		for _, ring := range rings[1:] {
+			if xy.IsPointInRing(s.Layout, p, ring) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package cover
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestNewShape(t *testing.T) {
+	for i, tc := range []struct {
+		g            geom.T
+		wantLines    [][]float64
+		wantPolygons [][][]float64
+	}{
+		{
+			g:         geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}),
+			wantLines: [][]float64{{0, 0, 1, 1}},
+		},
+		{
+			g:         geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			wantLines: [][]float64{{0, 0, 1, 1}, {2, 2, 3, 3}},
+		},
+		{
+			g:            geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			wantLines:    [][]float64{{0, 0, 1, 0, 1, 1, 0, 0}},
+			wantPolygons: [][][]float64{{{0, 0, 1, 0, 1, 1, 0, 0}}},
+		},
+		{
+			g:            geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 2, 2, 3, 2, 3, 3, 2, 2}, [][]int{{8}, {}, {16}}),
+			wantLines:    [][]float64{{0, 0, 1, 0, 1, 1, 0, 0}, {2, 2, 3, 2, 3, 3, 2, 2}},
+			wantPolygons: [][][]float64{{{0, 0, 1, 0, 1, 1, 0, 0}}, {{2, 2, 3, 2, 3, 3, 2, 2}}},
+		},
+	} {
+		s, err := NewShape(tc.g)
+		if err != nil {
+			t.Errorf("%d: NewShape(%v) == _, %v, want _, <nil>", i, tc.g, err)
+			continue
+		}
+		if !reflect.DeepEqual(s.Lines, tc.wantLines) || !reflect.DeepEqual(s.Polygons, tc.wantPolygons) {
+			t.Errorf("%d: NewShape(%v) == %v, %v, want %v, %v", i, tc.g, s.Lines, s.Polygons, tc.wantLines, tc.wantPolygons)
+		}
+	}
+	if _, err := NewShape(geom.NewPointFlat(geom.XY, []float64{0, 0})); err == nil {
+		t.Errorf("NewShape(point) == _, <nil>, want _, non-<nil>")
+	}
+}
+
+func TestInPolygon(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 3, 3, 3, 3, 1, 1, 1}, []int{10, 20})
+	s, err := NewShape(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tc := range []struct {
+		p    geom.Coord
+		want bool
+	}{
+		{p: geom.Coord{0.5, 0.5}, want: true},
+		{p: geom.Coord{2, 2}, want: false},
+		{p: geom.Coord{5, 5}, want: false},
+	} {
+		if got := s.InPolygon(tc.p); got != tc.want {
+			t.Errorf("%d: InPolygon(%v) == %v, want %v", i, tc.p, got, tc.want)
+		}
+	}
+}
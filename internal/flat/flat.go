@@ -0,0 +1,32 @@
+// Package flat contains helper functions for flat coordinates that are shared
+// by several packages.
+package flat
+
+// AppendUnrepeated appends the first stride ordinates of coord to flatCoords
+// unless it is equal in X and Y to the last coordinate of flatCoords.
+func AppendUnrepeated(flatCoords, coord []float64, stride int) []float64 {
+	if n := len(flatCoords); n >= stride && flatCoords[n-stride] == coord[0] && flatCoords[n-stride+1] == coord[1] {
+		return flatCoords
+	}
+	return append(flatCoords, coord[:stride]...)
+}
+
+// Reverse reverses the order of the coordinates in flatCoords in place.
+func Reverse(flatCoords []float64, stride int) {
+	for i, j := 0, len(flatCoords)-stride; i < j; i, j = i+stride, j-stride {
+		for k := 0; k < stride; k++ {
+			flatCoords[i+k], flatCoords[j+k] = flatCoords[j+k], flatCoords[i+k]
+		}
+	}
+}
+
+// Split splits flatCoords at ends, starting at offset.  The returned slices
+// share flatCoords' storage.
+func Split(flatCoords []float64, offset int, ends []int) [][]float64 {
+	result := make([][]float64, len(ends))
+	for i, end := range ends {
+		result[i] = flatCoords[offset:end]
+		offset = end
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package flat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendUnrepeated(t *testing.T) {
+	for i, tc := range []struct {
+		flatCoords []float64
+		coord      []float64
+		stride     int
+		want       []float64
+	}{
+		{flatCoords: nil, coord: []float64{1, 2}, stride: 2, want: []float64{1, 2}},
+		{flatCoords: []float64{1, 2}, coord: []float64{1, 2}, stride: 2, want: []float64{1, 2}},
+		{flatCoords: []float64{1, 2}, coord: []float64{1, 3}, stride: 2, want: []float64{1, 2, 1, 3}},
+		{flatCoords: []float64{1, 2, 3}, coord: []float64{1, 2, 4}, stride: 3, want: []float64{1, 2, 3}},
+		{flatCoords: []float64{1, 2, 3}, coord: []float64{2, 2, 4, 5}, stride: 3, want: []float64{1, 2, 3, 2, 2, 4}},
+	} {
+		if got := AppendUnrepeated(tc.flatCoords, tc.coord, tc.stride); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: AppendUnrepeated(%v, %v, %d) == %v, want %v", i, tc.flatCoords, tc.coord, tc.stride, got, tc.want)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	for i, tc := range []struct {
+		flatCoords []float64
+		stride     int
+		want       []float64
+	}{
+		{flatCoords: []float64{}, stride: 2, want: []float64{}},
+		{flatCoords: []float64{1, 2}, stride: 2, want: []float64{1, 2}},
+		{flatCoords: []float64{1, 2, 3, 4, 5, 6}, stride: 2, want: []float64{5, 6, 3, 4, 1, 2}},
+		{flatCoords: []float64{1, 2, 3, 4, 5, 6}, stride: 3, want: []float64{4, 5, 6, 1, 2, 3}},
+	} {
+		got := append([]float64{}, tc.flatCoords...)
+		Reverse(got, tc.stride)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Reverse(%v, %d) == %v, want %v", i, tc.flatCoords, tc.stride, got, tc.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for i, tc := range []struct {
+		flatCoords []float64
+		offset     int
+		ends       []int
+		want       [][]float64
+	}{
+		{flatCoords: nil, offset: 0, ends: nil, want: [][]float64{}},
+		{flatCoords: []float64{1, 2, 3, 4}, offset: 0, ends: []int{4}, want: [][]float64{{1, 2, 3, 4}}},
+		{flatCoords: []float64{1, 2, 3, 4, 5, 6}, offset: 0, ends: []int{2, 6}, want: [][]float64{{1, 2}, {3, 4, 5, 6}}},
+		{flatCoords: []float64{1, 2, 3, 4, 5, 6}, offset: 2, ends: []int{4, 6}, want: [][]float64{{3, 4}, {5, 6}}},
+	} {
+		if got := Split(tc.flatCoords, tc.offset, tc.ends); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Split(%v, %d, %v) == %v, want %v", i, tc.flatCoords, tc.offset, tc.ends, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Package geomutil contains helper functions for geometries that are shared
+// by several encodings.
+package geomutil
+
+import (
+	"github.com/twpayne/go-geom"
+)
+
+// SetSRID sets the SRID of g and returns g.
+func SetSRID(g geom.T, srid int) (geom.T, error) {
+	switch g := g.(type) {
+	case *geom.Point:
+		return g.SetSRID(srid), nil
+	case *geom.LineString:
+		return g.SetSRID(srid), nil
+	case *geom.LinearRing:
+		return g.SetSRID(srid), nil
+	case *geom.Polygon:
+		return g.SetSRID(srid), nil
+	case *geom.MultiPoint:
+		return g.SetSRID(srid), nil
+	case *geom.MultiLineString:
+		return g.SetSRID(srid), nil
+	case *geom.MultiPolygon:
+		return g.SetSRID(srid), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
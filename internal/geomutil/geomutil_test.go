@@ -0,0 +1,27 @@
+package geomutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestSetSRID(t *testing.T) {
+	for i, g := range []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+		geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+		geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+		geom.NewMultiPointFlat(geom.XY, []float64{1, 2}),
+		geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4}, []int{4}),
+		geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+	} {
+		if got, err := SetSRID(g, 4326); err != nil || got != g || g.SRID() != 4326 {
+			t.Errorf("%d: SetSRID(%v, 4326) == %v, %v, want %v with SRID 4326, <nil>", i, g, got, err, g)
+		}
+	}
+	if _, err := SetSRID(nil, 4326); !reflect.DeepEqual(err, geom.ErrUnsupportedType{Value: nil}) {
+		t.Errorf("SetSRID(nil, 4326) == _, %v, want _, %v", err, geom.ErrUnsupportedType{Value: nil})
+	}
+}
@@ -0,0 +1,16 @@
+// Package mathutil contains floating point helper functions that are shared
+// by several packages.
+package mathutil
+
+import "math"
+
+// Round returns the nearest integer to x, rounding half away from zero.  It
+// is equivalent to math.Round, which is not available in older versions of
+// Go.
+func Round(x float64) float64 {
+	t := math.Trunc(x)
+	if math.Abs(x-t) >= 0.5 {
+		t += math.Copysign(1, x)
+	}
+	return t
+}
@@ -0,0 +1,31 @@
+package mathutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRound(t *testing.T) {
+	for i, tc := range []struct {
+		x    float64
+		want float64
+	}{
+		{x: 0, want: 0},
+		{x: 0.49999999999999994, want: 0},
+		{x: 0.5, want: 1},
+		{x: 1.4, want: 1},
+		{x: 2.5, want: 3},
+		{x: -0.5, want: -1},
+		{x: -1.6, want: -2},
+		{x: 1 << 53, want: 1 << 53},
+		{x: math.Inf(1), want: math.Inf(1)},
+		{x: math.Inf(-1), want: math.Inf(-1)},
+	} {
+		if got := Round(tc.x); got != tc.want {
+			t.Errorf("%d: Round(%v) == %v, want %v", i, tc.x, got, tc.want)
+		}
+	}
+	if got := Round(math.NaN()); !math.IsNaN(got) {
+		t.Errorf("Round(NaN) == %v, want NaN", got)
+	}
+}
@@ -0,0 +1,29 @@
+package property
+
+import "testing"
+
+func TestToInt64(t *testing.T) {
+	for i, tc := range []struct {
+		v      interface{}
+		want   int64
+		wantOK bool
+	}{
+		{v: int(-1), want: -1, wantOK: true},
+		{v: int8(-2), want: -2, wantOK: true},
+		{v: int16(3), want: 3, wantOK: true},
+		{v: int32(4), want: 4, wantOK: true},
+		{v: int64(5), want: 5, wantOK: true},
+		{v: uint(6), want: 6, wantOK: true},
+		{v: uint8(7), want: 7, wantOK: true},
+		{v: uint16(8), want: 8, wantOK: true},
+		{v: uint32(9), want: 9, wantOK: true},
+		{v: uint64(10), want: 10, wantOK: true},
+		{v: 1.5},
+		{v: "1"},
+		{v: nil},
+	} {
+		if got, ok := ToInt64(tc.v); got != tc.want || ok != tc.wantOK {
+			t.Errorf("%d: ToInt64(%#v) == %d, %t, want %d, %t", i, tc.v, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package geom
+
+import (
+	"sort"
+
+	"github.com/twpayne/go-geom/internal/flat"
+)
+
+// Normalize returns a copy of g in a canonical form, so that geometries that
+// represent the same shape with the same vertices have identical coordinates.
+// In the canonical form:
+//
+//   - the points of MultiPoints are sorted;
+//   - LineStrings are directed so that their first coordinate is less than
+//     their last;
+//   - LinearRings and polygon rings start at their least vertex;
+//   - exterior rings are counter-clockwise and interior rings are clockwise;
+//   - the interior rings of Polygons and the components of MultiLineStrings
+//     and MultiPolygons are sorted.
+//
+// Coordinates are compared ordinate by ordinate.  The SRID is preserved.
+func Normalize(g T) (T, error) {
+	stride := g.Stride()
+	switch g := g.(type) {
+	case *Point:
+		return g.Clone().SetSRID(g.SRID()), nil
+	case *MultiPoint:
+		flatCoords, _ := sortComponents(g.flatCoords, evenEnds(len(g.flatCoords), stride))
+		return NewMultiPointFlat(g.layout, flatCoords).SetSRID(g.SRID()), nil
+	case *LineString:
+		flatCoords := cloneFlat(g.flatCoords)
+		normalizeLine(flatCoords, stride)
+		return NewLineStringFlat(g.layout, flatCoords).SetSRID(g.SRID()), nil
+	case *LinearRing:
+		flatCoords := cloneFlat(g.flatCoords)
+		normalizeRing(flatCoords, stride, true)
+		return NewLinearRingFlat(g.layout, flatCoords).SetSRID(g.SRID()), nil
+	case *MultiLineString:
+		flatCoords := cloneFlat(g.flatCoords)
+		offset := 0
+		for _, end := range g.ends {
+			normalizeLine(flatCoords[offset:end], stride)
+			offset = end
+		}
+		flatCoords, ends := sortComponents(flatCoords, g.ends)
+		return NewMultiLineStringFlat(g.layout, flatCoords, ends).SetSRID(g.SRID()), nil
+	case *Polygon:
+		flatCoords, ends := normalizePolygon(g.flatCoords, 0, g.ends, stride)
+		return NewPolygonFlat(g.layout, flatCoords, ends).SetSRID(g.SRID()), nil
+	case *MultiPolygon:
+		polygons := make(normalizedPolygons, 0, len(g.endss))
+		offset := 0
+		for _, ends := range g.endss {
+			flatCoords, normalizedEnds := normalizePolygon(g.flatCoords, offset, ends, stride)
+			polygons = append(polygons, normalizedPolygon{flatCoords: flatCoords, ends: normalizedEnds})
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		sort.Stable(polygons)
+		flatCoords := make([]float64, 0, len(g.flatCoords))
+		endss := make([][]int, 0, len(polygons))
+		for _, p := range polygons {
+			offset := len(flatCoords)
+			flatCoords = append(flatCoords, p.flatCoords...)
+			ends := make([]int, len(p.ends))
+			for i, end := range p.ends {
+				ends[i] = offset + end
+			}
+			endss = append(endss, ends)
+		}
+		return NewMultiPolygonFlat(g.layout, flatCoords, endss).SetSRID(g.SRID()), nil
+	default:
+		return nil, ErrUnsupportedType{Value: g}
+	}
+}
+
+// normalizePolygon returns a normalized copy of the polygon in flatCoords
+// starting at offset with the given ends.  The returned ends are relative to
+// the returned flat coordinates.
+func normalizePolygon(flatCoords []float64, offset int, ends []int, stride int) ([]float64, []int) {
+	if len(ends) == 0 {
+		return nil, nil
+	}
+	end := ends[len(ends)-1]
+	normalizedFlatCoords := cloneFlat(flatCoords[offset:end])
+	normalizedEnds := relativeEnds(ends, offset)
+	ringOffset := 0
+	for i, end := range normalizedEnds {
+		normalizeRing(normalizedFlatCoords[ringOffset:end], stride, i == 0)
+		ringOffset = end
+	}
+	exteriorEnd := normalizedEnds[0]
+	holesFlatCoords, holesEnds := sortComponents(normalizedFlatCoords[exteriorEnd:], relativeEnds(normalizedEnds[1:], exteriorEnd))
+	copy(normalizedFlatCoords[exteriorEnd:], holesFlatCoords)
+	for i, end := range holesEnds {
+		normalizedEnds[i+1] = exteriorEnd + end
+	}
+	return normalizedFlatCoords, normalizedEnds
+}
+
+// normalizeLine directs the line string in flatCoords in place so that its
+// first coordinate is less than its last.
+func normalizeLine(flatCoords []float64, stride int) {
+	for i, j := 0, len(flatCoords)-stride; i < j; i, j = i+stride, j-stride {
+		switch compareFlat(flatCoords[i:i+stride], flatCoords[j:j+stride]) {
+		case -1:
+			return
+		case 1:
+			flat.Reverse(flatCoords, stride)
+			return
+		}
+	}
+}
+
+// normalizeRing orients the ring in flatCoords in place, counter-clockwise if
+// ccw is true and clockwise otherwise, and, if the ring is closed, rotates it
+// to start at its least vertex.
+func normalizeRing(flatCoords []float64, stride int, ccw bool) {
+	if len(flatCoords) == 0 {
+		return
+	}
+	if doubleArea := doubleArea1(flatCoords, 0, len(flatCoords), stride); ccw && doubleArea < 0 || !ccw && doubleArea > 0 {
+		flat.Reverse(flatCoords, stride)
+	}
+	n := len(flatCoords) / stride
+	if n < 2 || compareFlat(flatCoords[:stride], flatCoords[(n-1)*stride:]) != 0 {
+		return
+	}
+	least := 0
+	for i := 1; i < n-1; i++ {
+		if compareFlat(flatCoords[i*stride:(i+1)*stride], flatCoords[least*stride:(least+1)*stride]) < 0 {
+			least = i
+		}
+	}
+	if least == 0 {
+		return
+	}
+	rotated := make([]float64, 0, len(flatCoords))
+	rotated = append(rotated, flatCoords[least*stride:(n-1)*stride]...)
+	rotated = append(rotated, flatCoords[:(least+1)*stride]...)
+	copy(flatCoords, rotated)
+}
+
+// sortComponents returns a copy of the components of flatCoords with the
+// given ends in sorted order, and their new ends.
+func sortComponents(flatCoords []float64, ends []int) ([]float64, []int) {
+	components := make([][]float64, 0, len(ends))
+	offset := 0
+	for _, end := range ends {
+		components = append(components, flatCoords[offset:end])
+		offset = end
+	}
+	sort.Stable(flatComponents(components))
+	sortedFlatCoords := make([]float64, 0, len(flatCoords))
+	sortedEnds := make([]int, 0, len(ends))
+	for _, component := range components {
+		sortedFlatCoords = append(sortedFlatCoords, component...)
+		sortedEnds = append(sortedEnds, len(sortedFlatCoords))
+	}
+	return sortedFlatCoords, sortedEnds
+}
+
+// flatComponents sorts components with compareFlat.
+type flatComponents [][]float64
+
+func (s flatComponents) Len() int           { return len(s) }
+func (s flatComponents) Less(i, j int) bool { return compareFlat(s[i], s[j]) < 0 }
+func (s flatComponents) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// A normalizedPolygon is the flat coordinates and ends of a normalized
+// polygon.
+type normalizedPolygon struct {
+	flatCoords []float64
+	ends       []int
+}
+
+// normalizedPolygons sorts polygons with comparePolygons.
+type normalizedPolygons []normalizedPolygon
+
+func (s normalizedPolygons) Len() int { return len(s) }
+func (s normalizedPolygons) Less(i, j int) bool {
+	return comparePolygons(s[i].flatCoords, s[i].ends, s[j].flatCoords, s[j].ends) < 0
+}
+func (s normalizedPolygons) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// compareFlat compares a and b ordinate by ordinate, returning -1, 0, or 1.
+// If a is a prefix of b then a is less than b.
+func compareFlat(a, b []float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePolygons compares two polygons ring by ring.
+func comparePolygons(flatCoords1 []float64, ends1 []int, flatCoords2 []float64, ends2 []int) int {
+	offset1, offset2 := 0, 0
+	for i := 0; i < len(ends1) && i < len(ends2); i++ {
+		if c := compareFlat(flatCoords1[offset1:ends1[i]], flatCoords2[offset2:ends2[i]]); c != 0 {
+			return c
+		}
+		offset1, offset2 = ends1[i], ends2[i]
+	}
+	switch {
+	case len(ends1) < len(ends2):
+		return -1
+	case len(ends1) > len(ends2):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func cloneFlat(flatCoords []float64) []float64 {
+	result := make([]float64, len(flatCoords))
+	copy(result, flatCoords)
+	return result
+}
+
+// evenEnds returns the ends of components of n ordinates with the given
+// stride, i.e. one component per coordinate.
+func evenEnds(n, stride int) []int {
+	if stride == 0 {
+		return nil
+	}
+	ends := make([]int, 0, n/stride)
+	for end := stride; end <= n; end += stride {
+		ends = append(ends, end)
+	}
+	return ends
+}
+
+// relativeEnds returns ends relative to offset.
+func relativeEnds(ends []int, offset int) []int {
+	result := make([]int, len(ends))
+	for i, end := range ends {
+		result[i] = end - offset
+	}
+	return result
+}
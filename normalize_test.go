@@ -0,0 +1,79 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for i, tc := range []struct {
+		g    T
+		want T
+	}{
+		{
+			g:    NewPointFlat(XY, []float64{1, 2}).SetSRID(4326),
+			want: NewPointFlat(XY, []float64{1, 2}).SetSRID(4326),
+		},
+		{
+			g:    NewMultiPointFlat(XYZ, []float64{1, 0, 2, 0, 1, 1, 0, 1, 0}),
+			want: NewMultiPointFlat(XYZ, []float64{0, 1, 0, 0, 1, 1, 1, 0, 2}),
+		},
+		{
+			g:    NewLineStringFlat(XY, []float64{2, 0, 1, 1, 0, 0}),
+			want: NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+		},
+		{
+			g:    NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 0, 0}),
+			want: NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 0, 0}),
+		},
+		{
+			g:    NewLineStringFlat(XY, []float64{0, 0, 2, 2, 1, 1, 0, 0}),
+			want: NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 0, 0}),
+		},
+		{
+			g:    NewLinearRingFlat(XY, []float64{1, 1, 0, 1, 0, 0, 1, 0, 1, 1}),
+			want: NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}),
+		},
+		{
+			g:    NewMultiLineStringFlat(XY, []float64{3, 3, 2, 2, 1, 1, 0, 0}, []int{4, 8}),
+			want: NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+		},
+		{
+			g: NewPolygonFlat(XY, []float64{
+				3, 3, 3, 0, 0, 0, 0, 3, 3, 3,
+				2, 2, 2, 3, 3, 2, 2, 2,
+				1, 1, 1, 2, 2, 1, 1, 1,
+			}, []int{10, 18, 26}),
+			want: NewPolygonFlat(XY, []float64{
+				0, 0, 3, 0, 3, 3, 0, 3, 0, 0,
+				1, 1, 1, 2, 2, 1, 1, 1,
+				2, 2, 2, 3, 3, 2, 2, 2,
+			}, []int{10, 18, 26}),
+		},
+		{
+			g: NewMultiPolygonFlat(XY, []float64{
+				2, 2, 3, 2, 3, 3, 2, 2,
+				1, 1, 0, 0, 1, 0, 1, 1,
+			}, [][]int{{8}, {16}}).SetSRID(3857),
+			want: NewMultiPolygonFlat(XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				2, 2, 3, 2, 3, 3, 2, 2,
+			}, [][]int{{8}, {16}}).SetSRID(3857),
+		},
+	} {
+		if got, err := Normalize(tc.g); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Normalize(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizeDoesNotAlias(t *testing.T) {
+	flatCoords := []float64{1, 1, 0, 1, 0, 0, 1, 0, 1, 1}
+	g := NewPolygonFlat(XY, flatCoords, []int{10})
+	if _, err := Normalize(g); err != nil {
+		t.Fatalf("Normalize(%v) == ..., %v, want ..., <nil>", g, err)
+	}
+	if want := []float64{1, 1, 0, 1, 0, 0, 1, 0, 1, 1}; !reflect.DeepEqual(flatCoords, want) {
+		t.Errorf("Normalize modified its input, got %v, want %v", flatCoords, want)
+	}
+}
@@ -0,0 +1,442 @@
+// Package precision implements precision models, which reduce the
+// coordinates of geometries to a fixed or floating point grid, and snapping
+// geometries to a grid.
+//
+// Reducing the precision of a geometry can make some of its components
+// degenerate.  Consecutive coordinates that become equal in X and Y are
+// merged, line strings that collapse to a single point are removed, and
+// polygon rings that collapse to zero area, including those that collapse to
+// spikes, are removed.  Polygons whose exterior ring collapses are removed
+// entirely.
+//
+// Reducing the precision of a polygon can also change its topology, for
+// example a hole can snap onto the exterior ring or two polygons of a
+// MultiPolygon can snap together.  Such polygons are repaired so that the
+// result is valid: their rings are noded and re-formed into polygons that
+// cover the same area as the snapped polygons, and polygons that overlap or
+// share edges are merged.  The rings of repaired polygons are oriented with
+// exterior rings counter-clockwise and holes clockwise, and a repaired Polygon
+// that splits into several polygons is returned as a MultiPolygon.
+package precision
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/mathutil"
+	"github.com/twpayne/go-geom/xy"
+)
+
+// A Type is a type of precision model.
+type Type int
+
+// Precision model types.
+const (
+	// Floating is full float64 precision.
+	Floating Type = iota
+	// FloatingSingle is float32 precision.
+	FloatingSingle
+	// Fixed is a fixed precision grid with a given scale.
+	Fixed
+)
+
+func (t Type) String() string {
+	switch t {
+	case Floating:
+		return "Floating"
+	case FloatingSingle:
+		return "FloatingSingle"
+	case Fixed:
+		return "Fixed"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// An ErrInvalidModel is returned when a Model is not valid.
+type ErrInvalidModel struct {
+	Model Model
+}
+
+func (e ErrInvalidModel) Error() string {
+	return fmt.Sprintf("precision: invalid model %v", e.Model)
+}
+
+// A Model is a precision model.  For Fixed models, coordinates are rounded to
+// the nearest multiple of 1/Scale, e.g. a Scale of 1000 rounds coordinates to
+// three decimal places and a Scale of 0.01 rounds coordinates to the nearest
+// hundred.  The zero Model is a Floating model.
+type Model struct {
+	Type  Type
+	Scale float64
+}
+
+// NewFixed returns a new Fixed Model with the given scale.
+func NewFixed(scale float64) Model {
+	return Model{
+		Type:  Fixed,
+		Scale: scale,
+	}
+}
+
+// GridSize returns the size of m's grid, or zero if m is not Fixed.
+func (m Model) GridSize() float64 {
+	if m.Type != Fixed {
+		return 0
+	}
+	return 1 / m.Scale
+}
+
+// MakePrecise returns x rounded to m's precision.
+func (m Model) MakePrecise(x float64) float64 {
+	switch m.Type {
+	case FloatingSingle:
+		return float64(float32(x))
+	case Fixed:
+		return mathutil.Round(x*m.Scale) / m.Scale
+	default:
+		return x
+	}
+}
+
+// Reduce returns a copy of g with its X and Y ordinates rounded to m's
+// precision and with degenerate components removed, see the package
+// documentation.  Other ordinates, including Z and M, are unchanged.
+func (m Model) Reduce(g geom.T) (geom.T, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return reduce(g, func(coord []float64) {
+		coord[0] = m.MakePrecise(coord[0])
+		coord[1] = m.MakePrecise(coord[1])
+	})
+}
+
+// String returns a human-readable string representing m.
+func (m Model) String() string {
+	if m.Type == Fixed {
+		return fmt.Sprintf("Fixed(%v)", m.Scale)
+	}
+	return m.Type.String()
+}
+
+func (m Model) validate() error {
+	switch m.Type {
+	case Floating, FloatingSingle:
+		return nil
+	case Fixed:
+		if m.Scale > 0 && !math.IsInf(m.Scale, 1) {
+			return nil
+		}
+	}
+	return ErrInvalidModel{Model: m}
+}
+
+// An ErrInvalidGridSize is returned when a grid size is not valid.
+type ErrInvalidGridSize float64
+
+func (e ErrInvalidGridSize) Error() string {
+	return fmt.Sprintf("precision: invalid grid size %v", float64(e))
+}
+
+// SnapToGrid returns a copy of g with each of its X, Y, Z, and M ordinates
+// rounded to the nearest multiple of sizeX, sizeY, sizeZ, and sizeM
+// respectively, and with degenerate components removed, see the package
+// documentation.  A size of zero leaves the corresponding ordinate unchanged,
+// and sizes of ordinates that g does not have are ignored.
+func SnapToGrid(g geom.T, sizeX, sizeY, sizeZ, sizeM float64) (geom.T, error) {
+	sizes := make([]float64, g.Stride())
+	for i, size := range []float64{sizeX, sizeY, sizeZ, sizeM} {
+		if !(size >= 0) || math.IsInf(size, 1) {
+			return nil, ErrInvalidGridSize(size)
+		}
+		var index int
+		switch i {
+		case 0, 1:
+			index = i
+		case 2:
+			index = g.Layout().ZIndex()
+		case 3:
+			index = g.Layout().MIndex()
+		}
+		if 0 <= index && index < len(sizes) {
+			sizes[index] = size
+		}
+	}
+	return reduce(g, func(coord []float64) {
+		for i, size := range sizes {
+			if size != 0 {
+				coord[i] = mathutil.Round(coord[i]/size) * size
+			}
+		}
+	})
+}
+
+// reduce returns a copy of g with snap applied to each coordinate and with
+// degenerate components removed.
+func reduce(g geom.T, snap func([]float64)) (geom.T, error) {
+	layout, stride := g.Layout(), g.Stride()
+	switch g := g.(type) {
+	case *geom.Point:
+		flatCoords := appendSnapped(nil, g.FlatCoords(), stride, snap)
+		return geom.NewPointFlat(layout, flatCoords).SetSRID(g.SRID()), nil
+	case *geom.MultiPoint:
+		flatCoords := appendSnapped(nil, g.FlatCoords(), stride, snap)
+		return geom.NewMultiPointFlat(layout, flatCoords).SetSRID(g.SRID()), nil
+	case *geom.LineString:
+		flatCoords := appendLine(nil, g.FlatCoords(), stride, snap)
+		return geom.NewLineStringFlat(layout, flatCoords).SetSRID(g.SRID()), nil
+	case *geom.LinearRing:
+		flatCoords := appendRing(nil, g.FlatCoords(), layout, snap)
+		return geom.NewLinearRingFlat(layout, flatCoords).SetSRID(g.SRID()), nil
+	case *geom.MultiLineString:
+		var flatCoords []float64
+		var ends []int
+		offset := 0
+		for _, end := range g.Ends() {
+			n := len(flatCoords)
+			if flatCoords = appendLine(flatCoords, g.FlatCoords()[offset:end], stride, snap); len(flatCoords) > n {
+				ends = append(ends, len(flatCoords))
+			}
+			offset = end
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends).SetSRID(g.SRID()), nil
+	case *geom.Polygon:
+		flatCoords, ends := appendPolygon(nil, g.FlatCoords(), 0, g.Ends(), layout, snap)
+		return repair(geom.NewPolygonFlat(layout, flatCoords, ends).SetSRID(g.SRID()), [][]int{ends})
+	case *geom.MultiPolygon:
+		var flatCoords []float64
+		var endss [][]int
+		offset := 0
+		for _, ends := range g.Endss() {
+			var polygonEnds []int
+			if flatCoords, polygonEnds = appendPolygon(flatCoords, g.FlatCoords(), offset, ends, layout, snap); len(polygonEnds) > 0 {
+				endss = append(endss, polygonEnds)
+			}
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return repair(geom.NewMultiPolygonFlat(layout, flatCoords, endss).SetSRID(g.SRID()), endss)
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// repair returns g, a Polygon or MultiPolygon whose polygons have the given
+// endss, unchanged if none of its rings intersect, and otherwise the valid
+// polygons that cover the same area as its polygons.
+func repair(g geom.T, endss [][]int) (geom.T, error) {
+	layout, stride := g.Layout(), g.Stride()
+	flatCoords := g.FlatCoords()
+	var ends []int
+	for _, polygonEnds := range endss {
+		ends = append(ends, polygonEnds...)
+	}
+	rings := geom.NewMultiLineStringFlat(layout, flatCoords, ends)
+	if selfIntersections, err := xy.SelfIntersections(rings); err != nil || len(selfIntersections) == 0 {
+		return g, err
+	}
+	noded, err := xy.Node(rings)
+	if err != nil {
+		return nil, err
+	}
+	faces, err := coveredFaces(noded, flatCoords, endss)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the edges shared by two faces, so that adjacent faces are
+	// merged, and form polygons from the remaining edges.
+	counts := make(map[[4]float64]int)
+	var segments [][]float64
+	for _, face := range faces {
+		faceFlatCoords := face.FlatCoords()
+		offset := 0
+		for _, end := range face.Ends() {
+			for i := offset + stride; i < end; i += stride {
+				segment := faceFlatCoords[i-stride : i+stride]
+				key := segmentKey(segment, stride)
+				if counts[key]++; counts[key] == 1 {
+					segments = append(segments, segment)
+				}
+			}
+			offset = end
+		}
+	}
+	var segmentFlatCoords []float64
+	var segmentEnds []int
+	for _, segment := range segments {
+		if counts[segmentKey(segment, stride)] == 1 {
+			segmentFlatCoords = append(segmentFlatCoords, segment...)
+			segmentEnds = append(segmentEnds, len(segmentFlatCoords))
+		}
+	}
+	polygons, err := coveredFaces(geom.NewMultiLineStringFlat(layout, segmentFlatCoords, segmentEnds), flatCoords, endss)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := g.(*geom.Polygon); ok && len(polygons) <= 1 {
+		if len(polygons) == 0 {
+			return geom.NewPolygon(layout).SetSRID(g.SRID()), nil
+		}
+		return polygons[0].SetSRID(g.SRID()), nil
+	}
+	multiPolygon := geom.NewMultiPolygon(layout).SetSRID(g.SRID())
+	for _, polygon := range polygons {
+		if err := multiPolygon.Push(polygon); err != nil {
+			return nil, err
+		}
+	}
+	return multiPolygon, nil
+}
+
+// coveredFaces returns the polygons formed by lines whose interiors are
+// covered by the polygons in flatCoords with the given endss.
+func coveredFaces(lines *geom.MultiLineString, flatCoords []float64, endss [][]int) ([]*geom.Polygon, error) {
+	polygonized, err := xy.Polygonize(lines)
+	if err != nil {
+		return nil, err
+	}
+	var faces []*geom.Polygon
+	for _, polygon := range polygonized.Polygons {
+		p, err := xy.InteriorPoint(polygon)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil && xy.IsPointInPolygons(lines.Layout(), p, flatCoords, endss) {
+			faces = append(faces, polygon)
+		}
+	}
+	return faces, nil
+}
+
+// segmentKey returns a key that identifies the X and Y ordinates of the
+// segment in flatCoords independently of its direction.
+func segmentKey(flatCoords []float64, stride int) [4]float64 {
+	x0, y0, x1, y1 := flatCoords[0], flatCoords[1], flatCoords[stride], flatCoords[stride+1]
+	if x1 < x0 || x1 == x0 && y1 < y0 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	return [4]float64{x0, y0, x1, y1}
+}
+
+// appendSnapped appends the coordinates of flatCoords to dst and applies snap
+// to them.
+func appendSnapped(dst, flatCoords []float64, stride int, snap func([]float64)) []float64 {
+	for i := 0; i < len(flatCoords); i += stride {
+		dst = append(dst, flatCoords[i:i+stride]...)
+		snap(dst[len(dst)-stride:])
+	}
+	return dst
+}
+
+// appendLine appends the snapped coordinates of the line string in flatCoords
+// to dst, omitting repeated points.  If the line string collapses to a single
+// point then dst is returned unchanged.
+func appendLine(dst, flatCoords []float64, stride int, snap func([]float64)) []float64 {
+	n := len(dst)
+	dst = appendUnrepeated(dst, flatCoords, stride, snap)
+	if len(dst)-n < 2*stride {
+		return dst[:n]
+	}
+	return dst
+}
+
+// appendRing appends the snapped coordinates of the ring in flatCoords to dst,
+// omitting repeated points and spikes.  If the ring collapses to zero area
+// then dst is returned unchanged.
+func appendRing(dst, flatCoords []float64, layout geom.Layout, snap func([]float64)) []float64 {
+	stride := layout.Stride()
+	n := len(dst)
+	dst = appendUnrepeated(dst, flatCoords, stride, snap)
+	ring := removeSpikes(dst[n:], stride)
+	dst = dst[:n+len(ring)]
+	if len(ring) < 4*stride || xy.SignedArea(layout, ring) == 0 {
+		return dst[:n]
+	}
+	return dst
+}
+
+// appendPolygon appends the snapped coordinates of the polygon in flatCoords
+// starting at offset with the given ends to dst, and returns the ends of its
+// remaining rings.  Collapsed interior rings are removed.  If the exterior
+// ring collapses then the polygon is removed.
+func appendPolygon(dst, flatCoords []float64, offset int, ends []int, layout geom.Layout, snap func([]float64)) ([]float64, []int) {
+	n := len(dst)
+	var newEnds []int
+	for i, end := range ends {
+		m := len(dst)
+		dst = appendRing(dst, flatCoords[offset:end], layout, snap)
+		switch {
+		case len(dst) > m:
+			newEnds = append(newEnds, len(dst))
+		case i == 0:
+			return dst[:n], nil
+		}
+		offset = end
+	}
+	return dst, newEnds
+}
+
+// appendUnrepeated appends the snapped coordinates of flatCoords to dst,
+// omitting coordinates that are equal in X and Y to their predecessor.
+func appendUnrepeated(dst, flatCoords []float64, stride int, snap func([]float64)) []float64 {
+	n := len(dst)
+	for i := 0; i < len(flatCoords); i += stride {
+		dst = append(dst, flatCoords[i:i+stride]...)
+		snap(dst[len(dst)-stride:])
+		if m := len(dst); m-n >= 2*stride && equal2D(dst[m-2*stride:], dst[m-stride:]) {
+			dst = dst[:m-stride]
+		}
+	}
+	return dst
+}
+
+// removeSpikes removes spikes, i.e. vertices whose neighbours are equal in X
+// and Y, from the closed ring in flatCoords in place, and returns the
+// resulting ring.
+func removeSpikes(flatCoords []float64, stride int) []float64 {
+	if len(flatCoords) < 4*stride {
+		return flatCoords
+	}
+	// work on the open ring, i.e. without the closing coordinate
+	n := len(flatCoords)/stride - 1
+	coords := make([][]float64, n)
+	for i := range coords {
+		coords[i] = append([]float64(nil), flatCoords[i*stride:(i+1)*stride]...)
+	}
+	for changed := true; changed && len(coords) >= 3; {
+		changed = false
+		for i := 0; i < len(coords) && len(coords) >= 3; i++ {
+			prev := coords[(i+len(coords)-1)%len(coords)]
+			next := coords[(i+1)%len(coords)]
+			if !equal2D(prev, next) {
+				continue
+			}
+			// remove the spike at i and the duplicate at i+1
+			j := (i + 1) % len(coords)
+			if j > i {
+				coords = append(coords[:i], coords[j+1:]...)
+			} else {
+				coords = coords[1:i]
+			}
+			changed = true
+			break
+		}
+	}
+	if len(coords) < 3 {
+		return flatCoords[:0]
+	}
+	result := flatCoords[:0]
+	for _, coord := range coords {
+		result = append(result, coord...)
+	}
+	return append(result, coords[0]...)
+}
+
+func equal2D(c1, c2 []float64) bool {
+	return c1[0] == c2[0] && c1[1] == c2[1]
+}
@@ -0,0 +1,193 @@
+package precision
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestModelMakePrecise(t *testing.T) {
+	for _, tc := range []struct {
+		m    Model
+		x    float64
+		want float64
+	}{
+		{m: Model{}, x: 1.23456789, want: 1.23456789},
+		{m: Model{Type: FloatingSingle}, x: 0.1, want: float64(float32(0.1))},
+		{m: NewFixed(1000), x: 1.23456789, want: 1.235},
+		{m: NewFixed(1000), x: -1.2344, want: -1.234},
+		{m: NewFixed(0.01), x: 1234, want: 1200},
+		{m: NewFixed(1), x: 2.5, want: 3},
+	} {
+		if got := tc.m.MakePrecise(tc.x); got != tc.want {
+			t.Errorf("%v.MakePrecise(%v) == %v, want %v", tc.m, tc.x, got, tc.want)
+		}
+	}
+}
+
+func TestModelGridSize(t *testing.T) {
+	for _, tc := range []struct {
+		m    Model
+		want float64
+	}{
+		{m: Model{}, want: 0},
+		{m: Model{Type: FloatingSingle}, want: 0},
+		{m: NewFixed(100), want: 0.01},
+	} {
+		if got := tc.m.GridSize(); got != tc.want {
+			t.Errorf("%v.GridSize() == %v, want %v", tc.m, got, tc.want)
+		}
+	}
+}
+
+func TestModelReduce(t *testing.T) {
+	for i, tc := range []struct {
+		m    Model
+		g    geom.T
+		want geom.T
+	}{
+		{
+			m:    NewFixed(1),
+			g:    geom.NewPointFlat(geom.XYZ, []float64{1.4, 1.6, 1.5}).SetSRID(4326),
+			want: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 1.5}).SetSRID(4326),
+		},
+		{
+			m:    NewFixed(1),
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{0.1, 0.1, 0.2, 0.2}),
+			want: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 0, 0}),
+		},
+		{
+			m:    NewFixed(1),
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.2, 0.1, 1.1, 0.9, 2, 1}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 1}),
+		},
+		{
+			m:    NewFixed(1),
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.2, 0.1}),
+			want: geom.NewLineStringFlat(geom.XY, nil),
+		},
+		{
+			m:    NewFixed(1),
+			g:    geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 1, 0.2, 0.1, 2, 0, 0, 3, 3.1, 0, 4}, []int{6, 12}),
+			want: geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 3, 3, 0, 4}, []int{6}),
+		},
+		{
+			m: NewFixed(1),
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				2, 2, 2.2, 2.4, 2.3, 2.1, 2, 2,
+				5.1, 5.2, 5.1, 6.9, 6.8, 6.9, 6.8, 5.1, 5.1, 5.2,
+			}, []int{10, 18, 28}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				5, 5, 5, 7, 7, 7, 7, 5, 5, 5,
+			}, []int{10, 20}),
+		},
+		{
+			m:    NewFixed(1),
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 0.4, 0, 0.4, 0.4, 0, 0}, []int{8}),
+			want: geom.NewPolygonFlat(geom.XY, nil, nil),
+		},
+		{
+			m: NewFixed(1),
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 2, 0, 2, 2, 2.1, 4, 1.9, 2.1, 0, 2, 0, 0,
+			}, []int{14}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 2, 0, 2, 2, 0, 2, 0, 0,
+			}, []int{10}),
+		},
+		{
+			m: NewFixed(1),
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 0.4, 0, 0.4, 0.4, 0, 0,
+				0, 0, 1, 0, 1, 1, 0, 0,
+			}, [][]int{{8}, {16}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+			}, [][]int{{8}}),
+		},
+		{
+			m: NewFixed(1),
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				0.4, 4, 0.4, 6, 6, 6, 6, 4, 0.4, 4,
+			}, []int{10, 20}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 6, 6, 6, 6, 4, 0, 4, 0, 0,
+			}, []int{18}),
+		},
+		{
+			m: NewFixed(1),
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 2.6, 0, 2.6, 2, 0, 2, 0, 0,
+				2.8, 0, 5, 0, 5, 2, 2.8, 2, 2.8, 0,
+			}, [][]int{{10}, {20}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 3, 0, 5, 0, 5, 2, 3, 2, 0, 2, 0, 0,
+			}, [][]int{{14}}),
+		},
+		{
+			m:    Model{Type: FloatingSingle},
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0.1, 0.2, 0.3, 0.4}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{float64(float32(0.1)), float64(float32(0.2)), float64(float32(0.3)), float64(float32(0.4))}),
+		},
+	} {
+		if got, err := tc.m.Reduce(tc.g); err != nil || !geom.Equal(got, tc.want) {
+			t.Errorf("%d: %v.Reduce(%v) == %v, %v, want %v, <nil>", i, tc.m, tc.g, got, err, tc.want)
+		}
+	}
+}
+
+func TestSnapToGrid(t *testing.T) {
+	for i, tc := range []struct {
+		g                          geom.T
+		sizeX, sizeY, sizeZ, sizeM float64
+		want                       geom.T
+	}{
+		{
+			g:     geom.NewPointFlat(geom.XYZM, []float64{1.26, 1.26, 1.26, 1.26}),
+			sizeX: 0.5,
+			sizeY: 0.1,
+			sizeZ: 1,
+			sizeM: 0,
+			want:  geom.NewPointFlat(geom.XYZM, []float64{1.5, 1.3, 1, 1.26}),
+		},
+		{
+			g:     geom.NewPointFlat(geom.XYM, []float64{1.26, 1.26, 1.26}),
+			sizeX: 1,
+			sizeY: 1,
+			sizeZ: 0.1,
+			sizeM: 0.5,
+			want:  geom.NewPointFlat(geom.XYM, []float64{1, 1, 1.5}),
+		},
+		{
+			g:     geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0.4, 10, 0, 0.6, 10.4, 0.4, 0.7, 20, 0, 0}),
+			sizeX: 1,
+			sizeY: 1,
+			sizeZ: 1,
+			want:  geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 10, 0, 1, 20, 0, 0}),
+		},
+	} {
+		got, err := SnapToGrid(tc.g, tc.sizeX, tc.sizeY, tc.sizeZ, tc.sizeM)
+		if err != nil || !geom.EqualTolerance(got, tc.want, 1e-12) {
+			t.Errorf("%d: SnapToGrid(%v, %v, %v, %v, %v) == %v, %v, want %v, <nil>", i, tc.g, tc.sizeX, tc.sizeY, tc.sizeZ, tc.sizeM, got, err, tc.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	g := geom.NewPointFlat(geom.XY, []float64{0, 0})
+	for _, m := range []Model{NewFixed(0), NewFixed(-1), NewFixed(math.Inf(1)), {Type: Type(3)}} {
+		if _, err := m.Reduce(g); !reflect.DeepEqual(err, ErrInvalidModel{Model: m}) {
+			t.Errorf("%v.Reduce(%v) == ..., %v, want ..., %v", m, g, err, ErrInvalidModel{Model: m})
+		}
+	}
+	for _, size := range []float64{-1, math.Inf(1)} {
+		if _, err := SnapToGrid(g, size, 1, 1, 1); !reflect.DeepEqual(err, ErrInvalidGridSize(size)) {
+			t.Errorf("SnapToGrid(%v, %v, 1, 1, 1) == ..., %v, want ..., %v", g, size, err, ErrInvalidGridSize(size))
+		}
+	}
+}
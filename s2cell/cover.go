@@ -0,0 +1,250 @@
+package s2cell
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/cover"
+	"github.com/twpayne/go-geom/xy"
+)
+
+// Relationships between a cell and a geometry.
+const (
+	disjoint = iota
+	intersects
+	within
+)
+
+// A coverer tests cells against the lines and polygons of a geometry.
+type coverer struct {
+	*cover.Shape
+}
+
+// A candidate is a cell that intersects the geometry being covered.
+type candidate struct {
+	id        CellID
+	contained bool
+}
+
+// Cover returns the sorted cells, with levels between minLevel and maxLevel
+// inclusive, that cover g.  Cells are subdivided breadth first, so larger
+// cells are replaced by their children before smaller cells, until the
+// cells are within g, are at maxLevel, or until subdividing a cell would
+// result in more than maxCells cells.  maxCells must be positive, and may be
+// exceeded if more than maxCells cells at minLevel are needed to cover g.
+// Points and multipoints are covered by their cells at maxLevel.
+//
+// The edges of g are straight lines in longitude and latitude, and the edges
+// of cells are approximated by straight lines between points along them.
+func Cover(g geom.T, minLevel, maxLevel, maxCells int) ([]CellID, error) {
+	if minLevel < 0 || minLevel > MaxLevel {
+		return nil, ErrInvalidLevel(minLevel)
+	}
+	if maxLevel < minLevel || maxLevel > MaxLevel {
+		return nil, ErrInvalidLevel(maxLevel)
+	}
+	if maxCells <= 0 {
+		return nil, ErrInvalidMaxCells(maxCells)
+	}
+	switch g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return coverPoints(g.FlatCoords(), g.Stride(), maxLevel)
+	}
+	shape, err := cover.NewShape(g)
+	if err != nil {
+		return nil, err
+	}
+	c := &coverer{Shape: shape}
+	if len(c.Lines) == 0 {
+		return nil, nil
+	}
+
+	var queue []candidate
+	for face := 0; face < numFaces; face++ {
+		if cand, ok := c.candidate(FromFace(face), false); ok {
+			queue = append(queue, cand)
+		}
+	}
+	var cells []CellID
+	for len(queue) > 0 {
+		cand := queue[0]
+		queue = queue[1:]
+		level := cand.id.Level()
+		if level >= minLevel && (cand.contained || level == maxLevel) {
+			cells = append(cells, cand.id)
+			continue
+		}
+		var children []candidate
+		for _, child := range cand.id.Children() {
+			if childCand, ok := c.candidate(child, cand.contained); ok {
+				children = append(children, childCand)
+			}
+		}
+		if level >= minLevel && len(cells)+len(queue)+len(children) > maxCells {
+			cells = append(cells, cand.id)
+			continue
+		}
+		queue = append(queue, children...)
+	}
+	sort.Sort(cellIDs(cells))
+	return cells, nil
+}
+
+// cellIDs sorts a slice of CellIDs in increasing order.
+type cellIDs []CellID
+
+func (s cellIDs) Len() int           { return len(s) }
+func (s cellIDs) Less(i, j int) bool { return s[i] < s[j] }
+func (s cellIDs) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// coverPoints returns the sorted, distinct cells at level containing the
+// points in flatCoords.
+func coverPoints(flatCoords []float64, stride, level int) ([]CellID, error) {
+	set := make(map[CellID]struct{})
+	for i := 0; i < len(flatCoords); i += stride {
+		id, err := FromCoord(geom.Coord(flatCoords[i:i+stride]), level)
+		if err != nil {
+			return nil, err
+		}
+		set[id] = struct{}{}
+	}
+	var cells []CellID
+	for id := range set {
+		cells = append(cells, id)
+	}
+	sort.Sort(cellIDs(cells))
+	return cells, nil
+}
+
+// candidate returns id as a candidate and true if it intersects c's
+// geometry.  The descendants of cells within the geometry are also within
+// it, so they are not tested.
+func (c *coverer) candidate(id CellID, parentContained bool) (candidate, bool) {
+	if parentContained {
+		return candidate{id: id, contained: true}, true
+	}
+	ring := region(id)
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for i := 0; i < len(ring); i += 2 {
+		minX, maxX = math.Min(minX, ring[i]), math.Max(maxX, ring[i])
+	}
+	// regions that extend beyond the antimeridian are also tested shifted by
+	// 360 degrees
+	if minX >= -180 && maxX <= 180 {
+		switch c.relate(ring) {
+		case intersects:
+			return candidate{id: id}, true
+		case within:
+			return candidate{id: id, contained: true}, true
+		}
+		return candidate{}, false
+	}
+	for _, shift := range []float64{-360, 0, 360} {
+		if maxX+shift < -180 || minX+shift > 180 {
+			continue
+		}
+		shifted := make([]float64, len(ring))
+		for i := 0; i < len(ring); i += 2 {
+			shifted[i], shifted[i+1] = ring[i]+shift, ring[i+1]
+		}
+		if c.relate(shifted) != disjoint {
+			return candidate{id: id}, true
+		}
+	}
+	return candidate{}, false
+}
+
+// relate returns the relationship between the ring, with layout geom.XY, and
+// c's geometry.
+func (c *coverer) relate(ring []float64) int {
+	for _, line := range c.Lines {
+		if len(line) == 0 {
+			continue
+		}
+		if xy.IsPointInRing(geom.XY, geom.Coord(line[:2]), ring) {
+			return intersects
+		}
+		for i := c.Stride; i < len(line); i += c.Stride {
+			for j := 2; j < len(ring); j += 2 {
+				if segmentsIntersect(line[i-c.Stride], line[i-c.Stride+1], line[i], line[i+1], ring[j-2], ring[j-1], ring[j], ring[j+1]) {
+					return intersects
+				}
+			}
+		}
+	}
+	// the ring does not intersect any boundary, so it is either entirely
+	// inside or entirely outside each polygon
+	p := make(geom.Coord, c.Stride)
+	copy(p, ring[:2])
+	if c.InPolygon(p) {
+		return within
+	}
+	return disjoint
+}
+
+// region returns the boundary of id as a closed ring of longitudes and
+// latitudes with layout geom.XY.  Edges are sampled at several points for
+// large cells, where straight lines are poor approximations.  The rings of
+// the cells that contain the poles are closed along the pole.
+func region(id CellID) []float64 {
+	face, i, j, size := id.faceIJ()
+	n := 1
+	if level := id.Level(); level < 6 {
+		n = 1 << (6 - level)
+	}
+	s0, t0 := float64(i)/maxSize, float64(j)/maxSize
+	d := float64(size) / maxSize / float64(n)
+	coords := make([]geom.Coord, 0, 4*n+3)
+	for k := 0; k < n; k++ {
+		coords = append(coords, faceSTToCoord(face, s0+float64(k)*d, t0))
+	}
+	for k := 0; k < n; k++ {
+		coords = append(coords, faceSTToCoord(face, s0+float64(n)*d, t0+float64(k)*d))
+	}
+	for k := n; k > 0; k-- {
+		coords = append(coords, faceSTToCoord(face, s0+float64(k)*d, t0+float64(n)*d))
+	}
+	for k := n; k > 0; k-- {
+		coords = append(coords, faceSTToCoord(face, s0, t0+float64(k)*d))
+	}
+	coords = append(coords, faceSTToCoord(face, s0, t0))
+	unwrap(coords)
+	first, last := coords[0], coords[len(coords)-1]
+	if delta := last[0] - first[0]; math.Abs(delta) > 180 {
+		pole := math.Copysign(90, delta)
+		coords = append(coords, geom.Coord{last[0], pole}, geom.Coord{first[0], pole}, first)
+	}
+	ring := make([]float64, 0, 2*len(coords))
+	for _, coord := range coords {
+		ring = append(ring, coord[0], coord[1])
+	}
+	return ring
+}
+
+// segmentsIntersect returns true if the segments from (x0, y0) to (x1, y1)
+// and from (x2, y2) to (x3, y3) intersect.
+func segmentsIntersect(x0, y0, x1, y1, x2, y2, x3, y3 float64) bool {
+	d0 := cross(x2, y2, x3, y3, x0, y0)
+	d1 := cross(x2, y2, x3, y3, x1, y1)
+	d2 := cross(x0, y0, x1, y1, x2, y2)
+	d3 := cross(x0, y0, x1, y1, x3, y3)
+	if ((d0 > 0 && d1 < 0) || (d0 < 0 && d1 > 0)) && ((d2 > 0 && d3 < 0) || (d2 < 0 && d3 > 0)) {
+		return true
+	}
+	return d0 == 0 && onSegment(x2, y2, x3, y3, x0, y0) ||
+		d1 == 0 && onSegment(x2, y2, x3, y3, x1, y1) ||
+		d2 == 0 && onSegment(x0, y0, x1, y1, x2, y2) ||
+		d3 == 0 && onSegment(x0, y0, x1, y1, x3, y3)
+}
+
+// cross returns the cross product of (x1-x0, y1-y0) and (x2-x0, y2-y0).
+func cross(x0, y0, x1, y1, x2, y2 float64) float64 {
+	return (x1-x0)*(y2-y0) - (y1-y0)*(x2-x0)
+}
+
+// onSegment returns true if (x2, y2), which is collinear with the segment
+// from (x0, y0) to (x1, y1), lies within the segment's bounds.
+func onSegment(x0, y0, x1, y1, x2, y2 float64) bool {
+	return math.Min(x0, x1) <= x2 && x2 <= math.Max(x0, x1) && math.Min(y0, y1) <= y2 && y2 <= math.Max(y0, y1)
+}
@@ -0,0 +1,142 @@
+package s2cell
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Hilbert curve orientations.
+const (
+	swapMask   = 1
+	invertMask = 2
+)
+
+var (
+	// ijToPos maps an orientation and the (i, j) bits of a child, as i<<1|j,
+	// to the child's position along the Hilbert curve.
+	ijToPos = [4][4]int{
+		{0, 1, 3, 2}, // canonical
+		{0, 3, 1, 2}, // swapped
+		{2, 3, 1, 0}, // inverted
+		{2, 1, 3, 0}, // swapped and inverted
+	}
+	// posToIJ is the inverse of ijToPos.
+	posToIJ = [4][4]int{
+		{0, 1, 3, 2}, // canonical
+		{0, 2, 3, 1}, // swapped
+		{3, 2, 0, 1}, // inverted
+		{3, 1, 0, 2}, // swapped and inverted
+	}
+	// posToOrientation maps a child's position to the change in
+	// orientation of its own children.
+	posToOrientation = [4]int{swapMask, 0, 0, invertMask | swapMask}
+)
+
+// xyzToFaceUV returns the face containing the unit vector (x, y, z) and its
+// (u, v) coordinates on that face.
+func xyzToFaceUV(x, y, z float64) (face int, u, v float64) {
+	switch ax, ay, az := math.Abs(x), math.Abs(y), math.Abs(z); {
+	case ax >= ay && ax >= az:
+		face = 0
+		if x < 0 {
+			face = 3
+		}
+	case ay >= az:
+		face = 1
+		if y < 0 {
+			face = 4
+		}
+	default:
+		face = 2
+		if z < 0 {
+			face = 5
+		}
+	}
+	switch face {
+	case 0:
+		u, v = y/x, z/x
+	case 1:
+		u, v = -x/y, z/y
+	case 2:
+		u, v = -x/z, -y/z
+	case 3:
+		u, v = z/x, y/x
+	case 4:
+		u, v = z/y, -x/y
+	default:
+		u, v = -y/z, -x/z
+	}
+	return
+}
+
+// faceUVToXYZ returns the (not necessarily unit length) vector with the
+// coordinates (u, v) on face.
+func faceUVToXYZ(face int, u, v float64) (x, y, z float64) {
+	switch face {
+	case 0:
+		return 1, u, v
+	case 1:
+		return -u, 1, v
+	case 2:
+		return -u, -v, 1
+	case 3:
+		return -1, -v, -u
+	case 4:
+		return v, -1, -u
+	default:
+		return v, u, -1
+	}
+}
+
+// stToUV converts s or t to u or v with the quadratic projection, which
+// makes cells at the same level closer in area.
+func stToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (4*s*s - 1) / 3
+	}
+	return (1 - 4*(1-s)*(1-s)) / 3
+}
+
+// uvToST is the inverse of stToUV.
+func uvToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+// stToIJ converts s or t to the i or j coordinate of the leaf cell
+// containing it.
+func stToIJ(s float64) int {
+	return clamp(int(math.Floor(maxSize*s)), 0, maxSize-1)
+}
+
+// coordToXYZ converts a longitude and latitude in degrees to a unit vector.
+func coordToXYZ(coord geom.Coord) (x, y, z float64) {
+	lng, lat := coord[0]*math.Pi/180, coord[1]*math.Pi/180
+	return math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)
+}
+
+// xyzToCoord converts a vector to a longitude and latitude in degrees.
+func xyzToCoord(x, y, z float64) geom.Coord {
+	lng := math.Atan2(y, x) * 180 / math.Pi
+	lat := math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi
+	return geom.Coord{lng, lat}
+}
+
+// faceSTToCoord returns the longitude and latitude of (s, t) on face.
+func faceSTToCoord(face int, s, t float64) geom.Coord {
+	return xyzToCoord(faceUVToXYZ(face, stToUV(s), stToUV(t)))
+}
+
+func clamp(x, min, max int) int {
+	switch {
+	case x < min:
+		return min
+	case x > max:
+		return max
+	default:
+		return x
+	}
+}
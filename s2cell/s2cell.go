@@ -0,0 +1,313 @@
+// Package s2cell implements a hierarchical discrete global grid.
+//
+// The grid is that of the S2 geometry library: the sphere is projected onto
+// the six faces of a cube, and each face is recursively divided into four
+// quadrilateral cells, down to level 30.  Cells are identified by 64-bit
+// CellIDs that order cells along a Hilbert curve, and are interchangeable
+// with S2 cell IDs and tokens.
+//
+// Coordinates are longitudes and latitudes in degrees, as the X and Y
+// ordinates of a geom.Coord.
+//
+// See https://s2geometry.io/devguide/s2cell_hierarchy.
+package s2cell
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+)
+
+// MaxLevel is the level of the smallest cells.
+const MaxLevel = 30
+
+const (
+	numFaces = 6
+	faceBits = 3
+	posBits  = 2*MaxLevel + 1
+	maxSize  = 1 << MaxLevel
+)
+
+// A CellID identifies a cell.
+type CellID uint64
+
+// An ErrInvalidLevel is returned when a level is out of range.
+type ErrInvalidLevel int
+
+func (e ErrInvalidLevel) Error() string {
+	return fmt.Sprintf("s2cell: invalid level %d", int(e))
+}
+
+// An ErrInvalidMaxCells is returned when a maximum number of cells is not
+// positive.
+type ErrInvalidMaxCells int
+
+func (e ErrInvalidMaxCells) Error() string {
+	return fmt.Sprintf("s2cell: invalid maximum number of cells %d", int(e))
+}
+
+// An ErrInvalidCoord is returned when a coordinate is not a valid longitude
+// and latitude.
+type ErrInvalidCoord struct {
+	Coord geom.Coord
+}
+
+func (e ErrInvalidCoord) Error() string {
+	return fmt.Sprintf("s2cell: invalid coordinate %v", e.Coord)
+}
+
+// An ErrInvalidToken is returned when a token cannot be parsed.
+type ErrInvalidToken string
+
+func (e ErrInvalidToken) Error() string {
+	return fmt.Sprintf("s2cell: invalid token %q", string(e))
+}
+
+// FromCoord returns the cell at level containing coord.
+func FromCoord(coord geom.Coord, level int) (CellID, error) {
+	if level < 0 || level > MaxLevel {
+		return 0, ErrInvalidLevel(level)
+	}
+	if len(coord) < 2 || !(-180 <= coord.X() && coord.X() <= 180) || !(-90 <= coord.Y() && coord.Y() <= 90) {
+		return 0, ErrInvalidCoord{Coord: coord}
+	}
+	face, u, v := xyzToFaceUV(coordToXYZ(coord))
+	return fromFaceIJ(face, stToIJ(uvToST(u)), stToIJ(uvToST(v))).Parent(level), nil
+}
+
+// FromPoint returns the cell at level containing p.
+func FromPoint(p *geom.Point, level int) (CellID, error) {
+	return FromCoord(p.Coords(), level)
+}
+
+// FromFace returns the level 0 cell of face.
+func FromFace(face int) CellID {
+	return CellID(uint64(face)<<posBits + lsbForLevel(0))
+}
+
+// FromToken returns the cell identified by token, as returned by
+// CellID.Token.
+func FromToken(token string) (CellID, error) {
+	if token == "X" {
+		return 0, nil
+	}
+	if len(token) < 1 || len(token) > 16 {
+		return 0, ErrInvalidToken(token)
+	}
+	n, err := strconv.ParseUint(token, 16, 64)
+	if err != nil {
+		return 0, ErrInvalidToken(token)
+	}
+	return CellID(n << (4 * (16 - len(token)))), nil
+}
+
+// fromFaceIJ returns the leaf cell with coordinates (i, j) on face.
+func fromFaceIJ(face, i, j int) CellID {
+	n := uint64(face) << posBits
+	orientation := face & swapMask
+	for k := MaxLevel - 1; k >= 0; k-- {
+		pos := ijToPos[orientation][(i>>k&1)<<1|j>>k&1]
+		n |= uint64(pos) << (2*k + 1)
+		orientation ^= posToOrientation[pos]
+	}
+	return CellID(n | 1)
+}
+
+// fromFaceIJWrap returns the leaf cell with coordinates (i, j) on face,
+// where (i, j) may be just beyond the edges of face, in which case the leaf
+// cell on the adjacent face is returned.
+func fromFaceIJWrap(face, i, j int) CellID {
+	if 0 <= i && i < maxSize && 0 <= j && j < maxSize {
+		return fromFaceIJ(face, i, j)
+	}
+	// the projection is linear near the edges of faces, so the quadratic
+	// projection is not needed to find the adjacent leaf cell
+	i, j = clamp(i, -1, maxSize), clamp(j, -1, maxSize)
+	u := (2*float64(i) + 1 - maxSize) / maxSize
+	v := (2*float64(j) + 1 - maxSize) / maxSize
+	face, u, v = xyzToFaceUV(faceUVToXYZ(face, u, v))
+	return fromFaceIJ(face, stToIJ(0.5*(u+1)), stToIJ(0.5*(v+1)))
+}
+
+// lsbForLevel returns the lowest set bit of cells at level.
+func lsbForLevel(level int) uint64 {
+	return 1 << (2 * (MaxLevel - level))
+}
+
+// IsValid returns true if c identifies a cell.
+func (c CellID) IsValid() bool {
+	return c.Face() < numFaces && c.lsb()&0x1555555555555555 != 0
+}
+
+// Face returns the face of c, from 0 to 5.
+func (c CellID) Face() int {
+	return int(uint64(c) >> posBits)
+}
+
+// Level returns the level of c, from 0 for a face to MaxLevel for a leaf
+// cell.
+func (c CellID) Level() int {
+	return MaxLevel - trailingZeros(uint64(c))/2
+}
+
+// IsLeaf returns true if c is a leaf cell.
+func (c CellID) IsLeaf() bool {
+	return c&1 != 0
+}
+
+// Parent returns the ancestor of c at level, which must not be greater than
+// c's level.
+func (c CellID) Parent(level int) CellID {
+	lsb := lsbForLevel(level)
+	return CellID(uint64(c)&-lsb | lsb)
+}
+
+// Children returns the four children of c, in Hilbert curve order.  c must
+// not be a leaf cell.
+func (c CellID) Children() [4]CellID {
+	lsb := c.lsb()
+	child := uint64(c) - lsb + lsb>>2
+	var children [4]CellID
+	for i := range children {
+		children[i] = CellID(child)
+		child += lsb >> 1
+	}
+	return children
+}
+
+// Contains returns true if other is c or a descendant of c.
+func (c CellID) Contains(other CellID) bool {
+	return c.RangeMin() <= other && other <= c.RangeMax()
+}
+
+// RangeMin returns the first leaf cell contained by c.
+func (c CellID) RangeMin() CellID {
+	return CellID(uint64(c) - (c.lsb() - 1))
+}
+
+// RangeMax returns the last leaf cell contained by c.
+func (c CellID) RangeMax() CellID {
+	return CellID(uint64(c) + (c.lsb() - 1))
+}
+
+// Neighbours returns the four cells at the same level that share an edge
+// with c, in the order below, right, above, and left in c's face
+// coordinates.
+func (c CellID) Neighbours() [4]CellID {
+	level := c.Level()
+	face, i, j, size := c.faceIJ()
+	return [4]CellID{
+		fromFaceIJWrap(face, i, j-size).Parent(level),
+		fromFaceIJWrap(face, i+size, j).Parent(level),
+		fromFaceIJWrap(face, i, j+size).Parent(level),
+		fromFaceIJWrap(face, i-size, j).Parent(level),
+	}
+}
+
+// Center returns the center of c.
+func (c CellID) Center() geom.Coord {
+	face, i, j, size := c.faceIJ()
+	return faceSTToCoord(face, (float64(i)+float64(size)/2)/maxSize, (float64(j)+float64(size)/2)/maxSize)
+}
+
+// Vertices returns the four vertices of c, counter-clockwise.  The longitudes
+// of cells that cross the antimeridian are unwrapped so that edges do not
+// jump across it, so some may be less than -180 or greater than 180.
+func (c CellID) Vertices() []geom.Coord {
+	face, i, j, size := c.faceIJ()
+	s0, t0 := float64(i)/maxSize, float64(j)/maxSize
+	s1, t1 := float64(i+size)/maxSize, float64(j+size)/maxSize
+	vertices := []geom.Coord{
+		faceSTToCoord(face, s0, t0),
+		faceSTToCoord(face, s1, t0),
+		faceSTToCoord(face, s1, t1),
+		faceSTToCoord(face, s0, t1),
+	}
+	unwrap(vertices)
+	return vertices
+}
+
+// Polygon returns the boundary of c as a polygon with straight edges between
+// its vertices, as returned by Vertices.  The polygons of the cells that
+// contain the poles are not valid in longitude and latitude.
+func (c CellID) Polygon() *geom.Polygon {
+	vertices := c.Vertices()
+	flatCoords := make([]float64, 0, 10)
+	for _, vertex := range append(vertices, vertices[0]) {
+		flatCoords = append(flatCoords, vertex...)
+	}
+	return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+}
+
+// Token returns a compact string representation of c, with trailing zeros
+// removed.
+func (c CellID) Token() string {
+	if c == 0 {
+		return "X"
+	}
+	return strings.TrimRight(fmt.Sprintf("%016x", uint64(c)), "0")
+}
+
+// String returns a human-readable representation of c: its face followed by
+// the positions of its ancestors within their parents.
+func (c CellID) String() string {
+	if !c.IsValid() {
+		return fmt.Sprintf("Invalid: %016x", uint64(c))
+	}
+	var buf bytes.Buffer
+	buf.WriteString(strconv.Itoa(c.Face()))
+	buf.WriteByte('/')
+	for level := 1; level <= c.Level(); level++ {
+		buf.WriteByte(byte('0' + uint64(c)>>(posBits-2*level)&3))
+	}
+	return buf.String()
+}
+
+// lsb returns the lowest set bit of c.
+func (c CellID) lsb() uint64 {
+	return uint64(c) & -uint64(c)
+}
+
+// trailingZeros returns the number of trailing zero bits in x, or 64 if x is
+// zero.
+func trailingZeros(x uint64) int {
+	if x == 0 {
+		return 64
+	}
+	n := 0
+	for x&1 == 0 {
+		x >>= 1
+		n++
+	}
+	return n
+}
+
+// faceIJ returns the face of c, the (i, j) coordinates of the leaf cell in
+// its lower left corner, and its size in leaf cells.
+func (c CellID) faceIJ() (face, i, j, size int) {
+	face = c.Face()
+	orientation := face & swapMask
+	for k := MaxLevel - 1; k >= 0; k-- {
+		pos := int(uint64(c) >> (2*k + 1) & 3)
+		ij := posToIJ[orientation][pos]
+		i |= ij >> 1 << k
+		j |= ij & 1 << k
+		orientation ^= posToOrientation[pos]
+	}
+	size = 1 << (MaxLevel - c.Level())
+	i &^= size - 1
+	j &^= size - 1
+	return
+}
+
+// unwrap adjusts the longitudes of coords so that consecutive longitudes
+// differ by no more than 180 degrees.
+func unwrap(coords []geom.Coord) {
+	for k := 1; k < len(coords); k++ {
+		coords[k][0] = coords[k-1][0] + math.Remainder(coords[k][0]-coords[k-1][0], 360)
+	}
+}
@@ -0,0 +1,333 @@
+package s2cell
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestFromCoord(t *testing.T) {
+	for i, tc := range []struct {
+		coord geom.Coord
+		level int
+		want  string
+	}{
+		{coord: geom.Coord{0, 0}, level: 30, want: "1000000000000001"},
+		{coord: geom.Coord{0, 0}, level: 1, want: "14"},
+		{coord: geom.Coord{0, 0}, level: 0, want: "1"},
+		{coord: geom.Coord{0, 90}, level: 0, want: "5"},
+		{coord: geom.Coord{0, -90}, level: 0, want: "b"},
+		{coord: geom.Coord{-122.4194, 37.7749}, level: 30, want: "8085809e8e8d8c61"},
+		{coord: geom.Coord{-74.0060, 40.7128}, level: 30, want: "89c25a220cf80969"},
+		{coord: geom.Coord{-0.1275, 51.507222}, level: 30, want: "487604ce4baa6f7d"},
+		{coord: geom.Coord{151.2093, -33.8688}, level: 30, want: "6b12ae3ff6290055"},
+		{coord: geom.Coord{-122.4194, 37.7749}, level: 10, want: "808581"},
+	} {
+		got, err := FromCoord(tc.coord, tc.level)
+		if err != nil || got.Token() != tc.want {
+			t.Errorf("%d: FromCoord(%v, %d) == %s, %v, want %s, <nil>", i, tc.coord, tc.level, got.Token(), err, tc.want)
+		}
+		if got.Level() != tc.level {
+			t.Errorf("%d: FromCoord(%v, %d).Level() == %d, want %d", i, tc.coord, tc.level, got.Level(), tc.level)
+		}
+		if !got.IsValid() {
+			t.Errorf("%d: FromCoord(%v, %d).IsValid() == false, want true", i, tc.coord, tc.level)
+		}
+		if id, err := FromToken(tc.want); err != nil || id != got {
+			t.Errorf("%d: FromToken(%q) == %s, %v, want %s, <nil>", i, tc.want, id, err, got)
+		}
+	}
+}
+
+func TestFromPoint(t *testing.T) {
+	p := geom.NewPointFlat(geom.XYZ, []float64{0, 0, 100})
+	if got, err := FromPoint(p, 1); err != nil || got.Token() != "14" {
+		t.Errorf("FromPoint(%v, 1) == %s, %v, want 14, <nil>", p, got.Token(), err)
+	}
+}
+
+func TestFaces(t *testing.T) {
+	for face, want := range []struct {
+		token      string
+		neighbours [4]int
+	}{
+		{token: "1", neighbours: [4]int{5, 1, 2, 4}},
+		{token: "3", neighbours: [4]int{5, 3, 2, 0}},
+		{token: "5", neighbours: [4]int{1, 3, 4, 0}},
+		{token: "7", neighbours: [4]int{1, 5, 4, 2}},
+		{token: "9", neighbours: [4]int{3, 5, 0, 2}},
+		{token: "b", neighbours: [4]int{3, 1, 0, 4}},
+	} {
+		id := FromFace(face)
+		if id.Token() != want.token || id.Face() != face || id.Level() != 0 {
+			t.Errorf("FromFace(%d) == %s, want %s", face, id.Token(), want.token)
+		}
+		for i, neighbour := range id.Neighbours() {
+			if neighbour != FromFace(want.neighbours[i]) {
+				t.Errorf("FromFace(%d).Neighbours()[%d] == %s, want %s", face, i, neighbour, FromFace(want.neighbours[i]))
+			}
+		}
+	}
+}
+
+func TestHierarchy(t *testing.T) {
+	for _, coord := range []geom.Coord{
+		{0, 0},
+		{-122.4194, 37.7749},
+		{179.999, -0.001},
+		{45, 35.26},
+		{0, 90},
+	} {
+		leaf, err := FromCoord(coord, MaxLevel)
+		if err != nil {
+			t.Fatalf("FromCoord(%v, %d) == ..., %v, want ..., <nil>", coord, MaxLevel, err)
+		}
+		if !leaf.IsLeaf() {
+			t.Errorf("FromCoord(%v, %d).IsLeaf() == false, want true", coord, MaxLevel)
+		}
+		for level := 0; level < MaxLevel; level++ {
+			c := leaf.Parent(level)
+			if c.Level() != level || !c.Contains(leaf) || c.IsLeaf() {
+				t.Errorf("%s.Parent(%d) == %s, want a non-leaf cell at level %d containing it", leaf, level, c, level)
+			}
+			if got, err := FromCoord(c.Center(), level); err != nil || got != c {
+				t.Errorf("FromCoord(%s.Center(), %d) == %s, %v, want %s, <nil>", c, level, got, err, c)
+			}
+			found := false
+			for _, child := range c.Children() {
+				if child.Parent(level) != c || child.Level() != level+1 || !c.Contains(child) || child.Contains(c) {
+					t.Errorf("%s.Children() contains %s, want children", c, child)
+				}
+				if child.Contains(leaf) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("%s.Children() == %v, want one containing %s", c, c.Children(), leaf)
+			}
+		}
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	for _, coord := range []geom.Coord{
+		{0, 0},
+		{44.99, 0},
+		{-45, 35.26},
+		{179.99, 0.01},
+		{-179.99, -60},
+		{90, 89.99},
+		{-30, -89.99},
+	} {
+		for _, level := range []int{1, 2, 5, 12, 30} {
+			c, err := FromCoord(coord, level)
+			if err != nil {
+				t.Fatalf("FromCoord(%v, %d) == ..., %v, want ..., <nil>", coord, level, err)
+			}
+			neighbours := c.Neighbours()
+			seen := map[CellID]bool{c: true}
+			for _, neighbour := range neighbours {
+				if neighbour.Level() != level || seen[neighbour] {
+					t.Errorf("%s.Neighbours() == %v, want distinct cells at level %d", c, neighbours, level)
+				}
+				seen[neighbour] = true
+				found := false
+				for _, n := range neighbour.Neighbours() {
+					if n == c {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("%s.Neighbours() == %v, want it to contain %s", neighbour, neighbour.Neighbours(), c)
+				}
+			}
+		}
+	}
+}
+
+func TestToken(t *testing.T) {
+	for _, id := range []CellID{0, FromFace(0), FromFace(5).Children()[3], 0x1000000000000001} {
+		if got, err := FromToken(id.Token()); err != nil || got != id {
+			t.Errorf("FromToken(%q) == %s, %v, want %s, <nil>", id.Token(), got, err, id)
+		}
+	}
+	for _, token := range []string{"", "x", "12345678901234567", "-1"} {
+		if _, err := FromToken(token); err != ErrInvalidToken(token) {
+			t.Errorf("FromToken(%q) == ..., %v, want ..., %v", token, err, ErrInvalidToken(token))
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, tc := range []struct {
+		id   CellID
+		want string
+	}{
+		{id: FromFace(4), want: "4/"},
+		{id: FromFace(4).Children()[1], want: "4/1"},
+		{id: FromFace(0).Children()[2].Children()[3], want: "0/23"},
+		{id: 0, want: "Invalid: 0000000000000000"},
+	} {
+		if got := tc.id.String(); got != tc.want {
+			t.Errorf("%016x.String() == %q, want %q", uint64(tc.id), got, tc.want)
+		}
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	for _, coord := range []geom.Coord{{0, 0}, {-122.4194, 37.7749}, {179.99, 0}, {-179.99, 0}} {
+		for _, level := range []int{0, 3, 10, 20} {
+			c, err := FromCoord(coord, level)
+			if err != nil {
+				t.Fatalf("FromCoord(%v, %d) == ..., %v, want ..., <nil>", coord, level, err)
+			}
+			p := c.Polygon()
+			if p.NumCoords() != 5 || p.Area() <= 0 {
+				t.Errorf("%s.Polygon() == %v, want a counter-clockwise quadrilateral", c, p.FlatCoords())
+			}
+		}
+	}
+}
+
+func TestCover(t *testing.T) {
+	for i, tc := range []struct {
+		g        geom.T
+		minLevel int
+		maxLevel int
+		maxCells int
+		samples  []geom.Coord
+	}{
+		{
+			g:        geom.NewPolygonFlat(geom.XY, []float64{-0.1, -0.1, 0.1, -0.1, 0.1, 0.1, -0.1, 0.1, -0.1, -0.1}, []int{10}),
+			maxLevel: 16,
+			maxCells: 8,
+			samples:  []geom.Coord{{0, 0}, {-0.1, -0.1}, {0.1, 0.1}, {0.05, -0.07}},
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, []float64{-0.1, -0.1, 0.1, -0.1, 0.1, 0.1, -0.1, 0.1, -0.1, -0.1}, []int{10}),
+			minLevel: 8,
+			maxLevel: 12,
+			maxCells: 1000,
+			samples:  []geom.Coord{{0, 0}, {-0.1, -0.1}, {0.1, 0.1}, {0.05, -0.07}},
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XY, []float64{170, 10, 180, 0, 179, -10}),
+			maxLevel: 10,
+			maxCells: 20,
+			samples:  []geom.Coord{{170, 10}, {175, 5}, {180, 0}, {179.5, -5}, {179, -10}},
+		},
+		{
+			g:        geom.NewMultiLineStringFlat(geom.XY, []float64{-1, -1, 1, 1, 10, 10, 11, 10}, []int{4, 8}),
+			maxLevel: 8,
+			maxCells: 10,
+			samples:  []geom.Coord{{-1, -1}, {0, 0}, {10, 10}, {11, 10}},
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, []float64{-180, 80, 180, 80, 180, 90, -180, 90, -180, 80}, []int{10}),
+			maxLevel: 6,
+			maxCells: 50,
+			samples:  []geom.Coord{{0, 90}, {0, 80}, {-180, 85}, {135, 80.5}, {-45, 89}},
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				4, 4, 4, 6, 6, 6, 6, 4, 4, 4,
+				-100, -50, -90, -50, -90, -40, -100, -50,
+			}, [][]int{{10, 20}, {28}}),
+			minLevel: 2,
+			maxLevel: 10,
+			maxCells: 30,
+			samples:  []geom.Coord{{1, 1}, {9, 9}, {5, 3.9}, {-95, -48}},
+		},
+		{
+			g:        geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1e-9, 1e-9, 10, 10}),
+			maxLevel: 20,
+			maxCells: 3,
+			samples:  []geom.Coord{{0, 0}, {10, 10}},
+		},
+	} {
+		cells, err := Cover(tc.g, tc.minLevel, tc.maxLevel, tc.maxCells)
+		if err != nil {
+			t.Errorf("%d: Cover(...) == ..., %v, want ..., <nil>", i, err)
+			continue
+		}
+		if len(cells) > tc.maxCells {
+			t.Errorf("%d: Cover(...) returned %d cells, want at most %d", i, len(cells), tc.maxCells)
+		}
+		for j, c := range cells {
+			if level := c.Level(); level < tc.minLevel || level > tc.maxLevel {
+				t.Errorf("%d: Cover(...) contains %s at level %d, want level between %d and %d", i, c, level, tc.minLevel, tc.maxLevel)
+			}
+			if j > 0 && cells[j-1].RangeMax() >= c.RangeMin() {
+				t.Errorf("%d: Cover(...) contains %s and %s, want sorted disjoint cells", i, cells[j-1], c)
+			}
+		}
+		for _, sample := range tc.samples {
+			leaf, err := FromCoord(sample, MaxLevel)
+			if err != nil {
+				t.Fatalf("FromCoord(%v, %d) == ..., %v, want ..., <nil>", sample, MaxLevel, err)
+			}
+			covered := false
+			for _, c := range cells {
+				if c.Contains(leaf) {
+					covered = true
+				}
+			}
+			if !covered {
+				t.Errorf("%d: Cover(...) == %v, want it to cover %v", i, cells, sample)
+			}
+		}
+	}
+}
+
+func TestCoverDisjoint(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, []int{10})
+	cells, err := Cover(g, 10, 10, 1000)
+	if err != nil {
+		t.Fatalf("Cover(%v, 10, 10, 1000) == ..., %v, want ..., <nil>", g, err)
+	}
+	for _, coord := range []geom.Coord{{-1, 0.5}, {2, 0.5}, {0.5, -1}, {0.5, 2}, {180, 0}} {
+		leaf, err := FromCoord(coord, MaxLevel)
+		if err != nil {
+			t.Fatalf("FromCoord(%v, %d) == ..., %v, want ..., <nil>", coord, MaxLevel, err)
+		}
+		for _, c := range cells {
+			if c.Contains(leaf) {
+				t.Errorf("Cover(%v, 10, 10, 1000) contains %s, which contains %v", g, c, coord)
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for i, tc := range []struct {
+		coord geom.Coord
+		level int
+		want  error
+	}{
+		{coord: geom.Coord{0, 0}, level: -1, want: ErrInvalidLevel(-1)},
+		{coord: geom.Coord{0, 0}, level: 31, want: ErrInvalidLevel(31)},
+		{coord: geom.Coord{180.5, 0}, level: 0, want: ErrInvalidCoord{Coord: geom.Coord{180.5, 0}}},
+		{coord: geom.Coord{0, 90.5}, level: 0, want: ErrInvalidCoord{Coord: geom.Coord{0, 90.5}}},
+	} {
+		if _, err := FromCoord(tc.coord, tc.level); !reflect.DeepEqual(err, tc.want) {
+			t.Errorf("%d: FromCoord(%v, %d) == ..., %v, want ..., %v", i, tc.coord, tc.level, err, tc.want)
+		}
+	}
+	g := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1})
+	for _, tc := range []struct {
+		minLevel, maxLevel, maxCells int
+		want                         error
+	}{
+		{minLevel: -1, maxLevel: 10, maxCells: 8, want: ErrInvalidLevel(-1)},
+		{minLevel: 5, maxLevel: 4, maxCells: 8, want: ErrInvalidLevel(4)},
+		{minLevel: 0, maxLevel: 31, maxCells: 8, want: ErrInvalidLevel(31)},
+		{minLevel: 0, maxLevel: 10, maxCells: 0, want: ErrInvalidMaxCells(0)},
+		{minLevel: 0, maxLevel: 10, maxCells: -1, want: ErrInvalidMaxCells(-1)},
+	} {
+		if _, err := Cover(g, tc.minLevel, tc.maxLevel, tc.maxCells); err != tc.want {
+			t.Errorf("Cover(%v, %d, %d, %d) == ..., %v, want ..., %v", g, tc.minLevel, tc.maxLevel, tc.maxCells, err, tc.want)
+		}
+	}
+}
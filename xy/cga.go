@@ -9,8 +9,8 @@ import (
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/bigxy"
 	"github.com/twpayne/go-geom/xy/internal"
-	"github.com/twpayne/go-geom/xy/internal/lineintersector"
 	"github.com/twpayne/go-geom/xy/internal/raycrossing"
+	"github.com/twpayne/go-geom/xy/lineintersector"
 	"github.com/twpayne/go-geom/xy/location"
 	"github.com/twpayne/go-geom/xy/orientation"
 )
@@ -55,6 +55,30 @@ func LocatePointInRing(layout geom.Layout, p geom.Coord, ring []float64) locatio
 	return raycrossing.LocatePointInRing(layout, p, ring)
 }
 
+// IsPointInPolygons returns true if p is in the interior of any of the
+// polygons in flatCoords with the given endss, i.e. p is in the interior of
+// the polygon's exterior ring and in the exterior of all of its holes.  Points
+// on the boundary of a polygon are not in its interior.
+func IsPointInPolygons(layout geom.Layout, p geom.Coord, flatCoords []float64, endss [][]int) bool {
+	offset := 0
+	for _, ends := range endss {
+		if len(ends) == 0 {
+			continue
+		}
+		contains := LocatePointInRing(layout, p, flatCoords[offset:ends[0]]) == location.Interior
+		for i := 1; contains && i < len(ends); i++ {
+			if LocatePointInRing(layout, p, flatCoords[ends[i-1]:ends[i]]) != location.Exterior {
+				contains = false
+			}
+		}
+		if contains {
+			return true
+		}
+		offset = ends[len(ends)-1]
+	}
+	return false
+}
+
 // IsOnLine tests whether a point lies on the line segments defined by a list of
 // coordinates.
 //
@@ -484,3 +484,27 @@ func TestIsPointInRing(t *testing.T) {
 		}
 	}
 }
+
+func TestIsPointInPolygons(t *testing.T) {
+	flatCoords := []float64{
+		0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+		1, 1, 1, 3, 3, 3, 3, 1, 1, 1,
+		10, 0, 12, 0, 12, 2, 10, 2, 10, 0,
+	}
+	endss := [][]int{{10, 20}, {}, {30}}
+	for i, tc := range []struct {
+		p    geom.Coord
+		want bool
+	}{
+		{p: geom.Coord{0.5, 0.5}, want: true},
+		{p: geom.Coord{2, 2}, want: false},
+		{p: geom.Coord{1, 2}, want: false},
+		{p: geom.Coord{0, 2}, want: false},
+		{p: geom.Coord{11, 1}, want: true},
+		{p: geom.Coord{6, 1}, want: false},
+	} {
+		if got := xy.IsPointInPolygons(geom.XY, tc.p, flatCoords, endss); got != tc.want {
+			t.Errorf("%d: IsPointInPolygons(geom.XY, %v, ...) == %v, want %v", i, tc.p, got, tc.want)
+		}
+	}
+}
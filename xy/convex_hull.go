@@ -251,7 +251,7 @@ func (calc *convexHullCalculator) computeOctRing(inputPts []float64) []float64 {
 
 func (calc *convexHullCalculator) computeOctPts(inputPts []float64) []float64 {
 	stride := calc.stride
-	pts := make([]float64, 8*stride)
+	pts := make([]float64, 8*stride, 9*stride) // extra capacity to close the ring
 	for j := 0; j < len(pts); j += stride {
 		for k := 0; k < stride; k++ {
 			pts[j+k] = inputPts[k]
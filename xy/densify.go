@@ -0,0 +1,171 @@
+package xy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Densify returns a copy of geometry with extra coordinates inserted so that
+// no segment is longer than maxSegmentLength.  Each segment that is too long
+// is divided into the smallest number of equal length segments that are no
+// longer than maxSegmentLength.  Lengths are measured in the XY plane and all
+// other ordinates, including Z and M, are interpolated linearly.  Points and
+// MultiPoints are copied unchanged.
+func Densify(geometry geom.T, maxSegmentLength float64) (geom.T, error) {
+	if !(maxSegmentLength > 0) {
+		return nil, fmt.Errorf("maxSegmentLength must be greater than 0, was %v", maxSegmentLength)
+	}
+	return densify(geometry, func(dst, c0, c1 []float64) []float64 {
+		n := math.Ceil(math.Hypot(c1[0]-c0[0], c1[1]-c0[1]) / maxSegmentLength)
+		for i := 1.0; i < n; i++ {
+			dst = appendInterpolated(dst, c0, c1, i/n)
+		}
+		return dst
+	})
+}
+
+// DensifyGreatCircle returns a copy of geometry, whose coordinates are
+// longitudes and latitudes in degrees, with extra coordinates inserted along
+// great circles so that no segment spans an angle of more than maxAngle
+// degrees at the center of the sphere.  On the Earth, one degree corresponds
+// to approximately 111km.  All ordinates other than longitude and latitude,
+// including Z and M, are interpolated linearly.  Each inserted longitude is
+// chosen to be the closest to the longitude of the preceding coordinate, so
+// a segment that crosses the antimeridian may have inserted longitudes
+// outside the range [-180, 180].  No great circle is defined between
+// antipodal coordinates, so their longitudes and latitudes are interpolated
+// linearly.  Points and MultiPoints are copied unchanged.
+func DensifyGreatCircle(geometry geom.T, maxAngle float64) (geom.T, error) {
+	if !(maxAngle > 0) {
+		return nil, fmt.Errorf("maxAngle must be greater than 0, was %v", maxAngle)
+	}
+	maxAngleRadians := maxAngle * math.Pi / 180
+	return densify(geometry, func(dst, c0, c1 []float64) []float64 {
+		v0, v1 := lonLatToVector(c0), lonLatToVector(c1)
+		angle := math.Atan2(norm(cross(v0, v1)), dot(v0, v1))
+		n := math.Ceil(angle / maxAngleRadians)
+		sinAngle := math.Sin(angle)
+		prevLon := c0[0]
+		for i := 1.0; i < n; i++ {
+			f := i / n
+			dst = appendInterpolated(dst, c0, c1, f)
+			c := dst[len(dst)-len(c0):]
+			if sinAngle < 1e-12 {
+				// the coordinates are antipodal, or as near as makes no
+				// difference, so the great circle is undefined and linear
+				// interpolation is used
+				continue
+			}
+			a := math.Sin((1-f)*angle) / sinAngle
+			b := math.Sin(f*angle) / sinAngle
+			var v [3]float64
+			for j := range v {
+				v[j] = a*v0[j] + b*v1[j]
+			}
+			lon := math.Atan2(v[1], v[0]) * 180 / math.Pi
+			c[0] = prevLon + math.Remainder(lon-prevLon, 360)
+			c[1] = math.Atan2(v[2], math.Hypot(v[0], v[1])) * 180 / math.Pi
+			prevLon = c[0]
+		}
+		return dst
+	})
+}
+
+// densify returns a copy of geometry with the coordinates returned by
+// densifySegment inserted between each pair of consecutive coordinates.
+func densify(geometry geom.T, densifySegment func(dst, c0, c1 []float64) []float64) (geom.T, error) {
+	stride := geometry.Stride()
+	switch t := geometry.(type) {
+	case *geom.Point:
+		return t.Clone().SetSRID(t.SRID()), nil
+	case *geom.MultiPoint:
+		return t.Clone().SetSRID(t.SRID()), nil
+	case *geom.LineString:
+		flatCoords := densifyFlat(nil, t.FlatCoords(), stride, densifySegment)
+		return geom.NewLineStringFlat(t.Layout(), flatCoords).SetSRID(t.SRID()), nil
+	case *geom.LinearRing:
+		flatCoords := densifyFlat(nil, t.FlatCoords(), stride, densifySegment)
+		return geom.NewLinearRingFlat(t.Layout(), flatCoords).SetSRID(t.SRID()), nil
+	case *geom.MultiLineString:
+		flatCoords, ends := densifyFlat2(nil, t.FlatCoords(), 0, t.Ends(), stride, densifySegment)
+		return geom.NewMultiLineStringFlat(t.Layout(), flatCoords, ends).SetSRID(t.SRID()), nil
+	case *geom.Polygon:
+		flatCoords, ends := densifyFlat2(nil, t.FlatCoords(), 0, t.Ends(), stride, densifySegment)
+		return geom.NewPolygonFlat(t.Layout(), flatCoords, ends).SetSRID(t.SRID()), nil
+	case *geom.MultiPolygon:
+		var flatCoords []float64
+		endss := make([][]int, len(t.Endss()))
+		offset := 0
+		for i, ends := range t.Endss() {
+			flatCoords, endss[i] = densifyFlat2(flatCoords, t.FlatCoords(), offset, ends, stride, densifySegment)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return geom.NewMultiPolygonFlat(t.Layout(), flatCoords, endss).SetSRID(t.SRID()), nil
+	default:
+		return nil, fmt.Errorf("%v is not a supported type for densification", t)
+	}
+}
+
+// densifyFlat appends the densified coordinates of the line string in
+// flatCoords to dst.
+func densifyFlat(dst, flatCoords []float64, stride int, densifySegment func(dst, c0, c1 []float64) []float64) []float64 {
+	for i := 0; i < len(flatCoords); i += stride {
+		if i > 0 {
+			dst = densifySegment(dst, flatCoords[i-stride:i], flatCoords[i:i+stride])
+		}
+		dst = append(dst, flatCoords[i:i+stride]...)
+	}
+	return dst
+}
+
+// densifyFlat2 appends the densified coordinates of the line strings in
+// flatCoords starting at offset with the given ends to dst, and returns the
+// new ends.
+func densifyFlat2(dst, flatCoords []float64, offset int, ends []int, stride int, densifySegment func(dst, c0, c1 []float64) []float64) ([]float64, []int) {
+	newEnds := make([]int, len(ends))
+	for i, end := range ends {
+		dst = densifyFlat(dst, flatCoords[offset:end], stride, densifySegment)
+		newEnds[i] = len(dst)
+		offset = end
+	}
+	return dst, newEnds
+}
+
+// appendInterpolated appends the coordinate at fraction f along the segment
+// from c0 to c1 to dst.
+func appendInterpolated(dst, c0, c1 []float64, f float64) []float64 {
+	for i := range c0 {
+		dst = append(dst, c0[i]+f*(c1[i]-c0[i]))
+	}
+	return dst
+}
+
+// lonLatToVector returns the unit vector of the longitude and latitude in c.
+func lonLatToVector(c []float64) [3]float64 {
+	lambda, phi := c[0]*math.Pi/180, c[1]*math.Pi/180
+	return [3]float64{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+func cross(u, v [3]float64) [3]float64 {
+	return [3]float64{
+		u[1]*v[2] - u[2]*v[1],
+		u[2]*v[0] - u[0]*v[2],
+		u[0]*v[1] - u[1]*v[0],
+	}
+}
+
+func dot(u, v [3]float64) float64 {
+	return u[0]*v[0] + u[1]*v[1] + u[2]*v[2]
+}
+
+func norm(v [3]float64) float64 {
+	return math.Sqrt(dot(v, v))
+}
@@ -0,0 +1,126 @@
+package xy_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func TestDensify(t *testing.T) {
+	for i, tc := range []struct {
+		g                geom.T
+		maxSegmentLength float64
+		want             geom.T
+	}{
+		{
+			g:                geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			maxSegmentLength: 1,
+			want:             geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+		},
+		{
+			g:                geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 0}),
+			maxSegmentLength: 3,
+			want:             geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2.5, 0, 5, 0, 7.5, 0, 10, 0}),
+		},
+		{
+			g:                geom.NewLineStringFlat(geom.XY, []float64{0, 0, 3, 4, 3, 5}),
+			maxSegmentLength: 5,
+			want:             geom.NewLineStringFlat(geom.XY, []float64{0, 0, 3, 4, 3, 5}),
+		},
+		{
+			g:                geom.NewLineStringFlat(geom.XYZM, []float64{0, 0, 0, 0, 4, 0, 8, 4}).SetSRID(3857),
+			maxSegmentLength: 2,
+			want:             geom.NewLineStringFlat(geom.XYZM, []float64{0, 0, 0, 0, 2, 0, 4, 2, 4, 0, 8, 4}).SetSRID(3857),
+		},
+		{
+			g:                geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 0, 0, 2, 2, 5, 5, 5, 5, 5, 7}, []int{6, 12}),
+			maxSegmentLength: 1,
+			want:             geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 0, 0, 1, 1, 0, 2, 2, 5, 5, 5, 5, 5, 7}, []int{9, 15}),
+		},
+		{
+			g:                geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 0}, []int{8}),
+			maxSegmentLength: 2,
+			want:             geom.NewPolygonFlat(geom.XY, []float64{0, 0, 2, 0, 4, 0, 4, 2, 4, 4, 8.0 / 3, 8.0 / 3, 4.0 / 3, 4.0 / 3, 0, 0}, []int{16}),
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				10, 10, 12, 10, 12, 11, 10, 10,
+			}, [][]int{{8}, {16}}),
+			maxSegmentLength: 1.5,
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				10, 10, 11, 10, 12, 10, 12, 11, 11, 10.5, 10, 10,
+			}, [][]int{{8}, {20}}),
+		},
+	} {
+		got, err := xy.Densify(tc.g, tc.maxSegmentLength)
+		if err != nil || !geom.EqualTolerance(got, tc.want, 1e-12) {
+			t.Errorf("%d: Densify(%v, %v) == %v, %v, want %v, <nil>", i, tc.g, tc.maxSegmentLength, got, err, tc.want)
+		}
+	}
+}
+
+func TestDensifyGreatCircle(t *testing.T) {
+	for i, tc := range []struct {
+		g        geom.T
+		maxAngle float64
+		want     geom.T
+	}{
+		{
+			g:        geom.NewLineStringFlat(geom.XY, []float64{0, 0, 90, 0}),
+			maxAngle: 30,
+			want:     geom.NewLineStringFlat(geom.XY, []float64{0, 0, 30, 0, 60, 0, 90, 0}),
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 0, 90, 100}),
+			maxAngle: 45,
+			want:     geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 0, 45, 50, 0, 90, 100}),
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XY, []float64{-45, 45, 45, 45}),
+			maxAngle: 45,
+			want:     geom.NewLineStringFlat(geom.XY, []float64{-45, 45, 0, math.Atan(math.Sqrt2) * 180 / math.Pi, 45, 45}),
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XY, []float64{170, 0, -170, 0}),
+			maxAngle: 5,
+			want:     geom.NewLineStringFlat(geom.XY, []float64{170, 0, 175, 0, 180, 0, 185, 0, -170, 0}),
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, []float64{0, 0, 90, 0, 0, 90, 0, 0}, []int{8}),
+			maxAngle: 45,
+			want:     geom.NewPolygonFlat(geom.XY, []float64{0, 0, 45, 0, 90, 0, 90, 45, 0, 90, 0, 45, 0, 0}, []int{14}),
+		},
+		{
+			// antipodal coordinates are interpolated linearly
+			g:        geom.NewLineStringFlat(geom.XY, []float64{0, 0, 180, 0}),
+			maxAngle: 45,
+			want:     geom.NewLineStringFlat(geom.XY, []float64{0, 0, 45, 0, 90, 0, 135, 0, 180, 0}),
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XY, []float64{-90, -30, 90, 30}),
+			maxAngle: 90,
+			want:     geom.NewLineStringFlat(geom.XY, []float64{-90, -30, 0, 0, 90, 30}),
+		},
+	} {
+		got, err := xy.DensifyGreatCircle(tc.g, tc.maxAngle)
+		if err != nil || !geom.EqualTolerance(got, tc.want, 1e-9) {
+			t.Errorf("%d: DensifyGreatCircle(%v, %v) == %v, %v, want %v, <nil>", i, tc.g, tc.maxAngle, got, err, tc.want)
+		}
+	}
+}
+
+func TestDensifyErrors(t *testing.T) {
+	g := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1})
+	for _, maxSegmentLength := range []float64{0, -1, math.NaN()} {
+		if _, err := xy.Densify(g, maxSegmentLength); err == nil {
+			t.Errorf("Densify(%v, %v) == ..., <nil>, want ..., non-<nil>", g, maxSegmentLength)
+		}
+		if _, err := xy.DensifyGreatCircle(g, maxSegmentLength); err == nil {
+			t.Errorf("DensifyGreatCircle(%v, %v) == ..., <nil>, want ..., non-<nil>", g, maxSegmentLength)
+		}
+	}
+}
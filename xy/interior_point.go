@@ -0,0 +1,202 @@
+package xy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/internal"
+)
+
+// InteriorPoint computes a point that is guaranteed to lie in the interior of
+// the geometry (or on the geometry if it has no interior, e.g. for lines and
+// points).  Unlike the centroid, the interior point of a concave polygon never
+// falls outside of it, which makes it suitable for placing labels.
+//
+// The returned coordinate only contains the x and y ordinates.  If the
+// geometry is empty a nil coordinate is returned.
+//
+// Algorithm
+//
+// Points: the point closest to the centroid.
+//
+// Lines: the vertex, excluding the end points, that is closest to the
+// centroid.  If there are no such vertices the end point closest to the
+// centroid is used.
+//
+// Polygons: a horizontal scan line is placed near the middle of each polygon
+// at a y ordinate that does not pass through any vertex.  The scan line is
+// intersected with the polygon's rings and the midpoint of the widest interior
+// section is chosen.  Polygons with zero area are treated as lines.
+func InteriorPoint(geometry geom.T) (interiorPoint geom.Coord, err error) {
+	switch t := geometry.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		interiorPoint = interiorPointPoints(t)
+	case *geom.LineString, *geom.LinearRing:
+		interiorPoint = interiorPointLines(t, []int{len(t.FlatCoords())})
+	case *geom.MultiLineString:
+		interiorPoint = interiorPointLines(t, t.Ends())
+	case *geom.Polygon:
+		interiorPoint = interiorPointArea(t, [][]int{t.Ends()})
+	case *geom.MultiPolygon:
+		interiorPoint = interiorPointArea(t, t.Endss())
+	default:
+		err = fmt.Errorf("%v is not a supported type for interior point calculation", t)
+	}
+
+	return interiorPoint, err
+}
+
+// closestCoord returns the coordinate from the flat coordinates between
+// offset and end (exclusive) that is closest to target, or nil if there are no
+// coordinates.
+func closestCoord(flatCoords []float64, offset, end, stride int, target geom.Coord) (geom.Coord, float64) {
+	var closest geom.Coord
+	minDistance := math.Inf(1)
+	for i := offset; i < end; i += stride {
+		c := geom.Coord(flatCoords[i : i+2])
+		if d := internal.Distance2D(c, target); d < minDistance {
+			closest, minDistance = c, d
+		}
+	}
+	return closest, minDistance
+}
+
+func interiorPointPoints(geometry geom.T) geom.Coord {
+	flatCoords := geometry.FlatCoords()
+	if len(flatCoords) == 0 {
+		return nil
+	}
+	centroid := PointsCentroidFlat(geometry.Layout(), flatCoords)
+	closest, _ := closestCoord(flatCoords, 0, len(flatCoords), geometry.Stride(), centroid)
+	return geom.Coord{closest[0], closest[1]}
+}
+
+func interiorPointLines(geometry geom.T, ends []int) geom.Coord {
+	flatCoords := geometry.FlatCoords()
+	if len(flatCoords) == 0 {
+		return nil
+	}
+	stride := geometry.Stride()
+	calc := NewLineCentroidCalculator(geometry.Layout())
+	offset := 0
+	for _, end := range ends {
+		calc.addLine(flatCoords, offset, end)
+		offset = end
+	}
+	centroid := calc.GetCentroid()
+
+	// first try the interior vertices, then fall back to the end points
+	var interiorPoint geom.Coord
+	minDistance := math.Inf(1)
+	offset = 0
+	for _, end := range ends {
+		if c, d := closestCoord(flatCoords, offset+stride, end-stride, stride, centroid); d < minDistance {
+			interiorPoint, minDistance = c, d
+		}
+		offset = end
+	}
+	if interiorPoint == nil {
+		offset = 0
+		for _, end := range ends {
+			if end == offset {
+				continue
+			}
+			for _, i := range []int{offset, end - stride} {
+				if c, d := closestCoord(flatCoords, i, i+stride, stride, centroid); d < minDistance {
+					interiorPoint, minDistance = c, d
+				}
+			}
+			offset = end
+		}
+	}
+	if interiorPoint == nil {
+		return nil
+	}
+	return geom.Coord{interiorPoint[0], interiorPoint[1]}
+}
+
+func interiorPointArea(geometry geom.T, endss [][]int) geom.Coord {
+	flatCoords := geometry.FlatCoords()
+	if len(flatCoords) == 0 {
+		return nil
+	}
+	stride := geometry.Stride()
+
+	var interiorPoint geom.Coord
+	maxWidth := -1.0
+	offset := 0
+	for _, ends := range endss {
+		if len(ends) == 0 {
+			continue
+		}
+		if x, y, width, ok := scanLineInteriorPoint(flatCoords, offset, ends, stride); ok && width > maxWidth {
+			interiorPoint, maxWidth = geom.Coord{x, y}, width
+		}
+		offset = ends[len(ends)-1]
+	}
+	if interiorPoint != nil {
+		return interiorPoint
+	}
+
+	// all polygons are degenerate so treat their rings as lines
+	var lineEnds []int
+	for _, ends := range endss {
+		lineEnds = append(lineEnds, ends...)
+	}
+	return interiorPointLines(geometry, lineEnds)
+}
+
+// scanLineInteriorPoint computes the midpoint of the widest section of a
+// horizontal scan line through the polygon described by the flat coordinates
+// starting at offset and ends.  ok is false if the polygon has no interior.
+func scanLineInteriorPoint(flatCoords []float64, offset int, ends []int, stride int) (x, y, width float64, ok bool) {
+	shell := flatCoords[offset:ends[0]]
+	if len(shell) == 0 {
+		return 0, 0, 0, false
+	}
+
+	// find the y ordinate between the two vertices closest to the center
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for i := 1; i < len(shell); i += stride {
+		minY, maxY = math.Min(minY, shell[i]), math.Max(maxY, shell[i])
+	}
+	centerY := (minY + maxY) / 2
+	loY, hiY := minY, maxY
+	for i := offset + 1; i < ends[len(ends)-1]; i += stride {
+		if v := flatCoords[i]; v <= centerY && v > loY {
+			loY = v
+		} else if v > centerY && v < hiY {
+			hiY = v
+		}
+	}
+	y = (loY + hiY) / 2
+	if loY == hiY {
+		return 0, 0, 0, false
+	}
+
+	var crossings []float64
+	for _, end := range ends {
+		for i := offset; i < end-stride; i += stride {
+			x0, y0 := flatCoords[i], flatCoords[i+1]
+			x1, y1 := flatCoords[i+stride], flatCoords[i+stride+1]
+			if (y0 < y && y < y1) || (y1 < y && y < y0) {
+				crossings = append(crossings, x0+(y-y0)*(x1-x0)/(y1-y0))
+			}
+		}
+		offset = end
+	}
+	sort.Float64s(crossings)
+
+	width = -1
+	for i := 0; i+1 < len(crossings); i += 2 {
+		if w := crossings[i+1] - crossings[i]; w > width {
+			x, width = (crossings[i]+crossings[i+1])/2, w
+		}
+	}
+	if width <= 0 {
+		return 0, 0, 0, false
+	}
+	return x, y, width, true
+}
@@ -0,0 +1,18 @@
+package xy_test
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func ExampleInteriorPoint() {
+	// a U shaped polygon whose centroid lies outside of it
+	polygon := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 8, 10, 8, 1, 2, 1, 2, 10, 0, 10, 0, 0}, []int{18})
+
+	interiorPoint, _ := xy.InteriorPoint(polygon)
+
+	fmt.Println(interiorPoint)
+	// Output: [1 5.5]
+}
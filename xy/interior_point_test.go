@@ -0,0 +1,96 @@
+package xy_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/internal"
+	"github.com/twpayne/go-geom/xy/location"
+)
+
+func TestInteriorPoint(t *testing.T) {
+	for i, tc := range []struct {
+		geometry      geom.T
+		interiorPoint geom.Coord
+	}{
+		{
+			geometry:      geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			interiorPoint: geom.Coord{1, 2},
+		},
+		{
+			geometry:      geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1, 4, 4}),
+			interiorPoint: geom.Coord{1, 1},
+		},
+		{
+			geometry:      geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0, 10, 0}),
+			interiorPoint: geom.Coord{2, 0},
+		},
+		{
+			geometry:      geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 0}),
+			interiorPoint: geom.Coord{0, 0},
+		},
+		{
+			geometry:      geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 5, 5, 6, 6, 7, 7}, []int{4, 10}),
+			interiorPoint: geom.Coord{6, 6},
+		},
+		{
+			geometry:      geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 2, 0, 2, 0, 0}, []int{10}),
+			interiorPoint: geom.Coord{2, 1},
+		},
+		{
+			// U shape: the centroid lies outside the polygon
+			geometry:      geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 8, 10, 8, 1, 2, 1, 2, 10, 0, 10, 0, 0}, []int{18}),
+			interiorPoint: geom.Coord{1, 5.5},
+		},
+		{
+			geometry: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 1, 0, 0,
+				10, 10, 16, 10, 16, 16, 10, 16, 10, 10,
+			}, [][]int{{10}, {20}}),
+			interiorPoint: geom.Coord{13, 13},
+		},
+		{
+			// zero area polygon
+			geometry:      geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2, 0, 0}, []int{8}),
+			interiorPoint: geom.Coord{1, 1},
+		},
+		{
+			geometry:      geom.NewPolygon(geom.XY),
+			interiorPoint: nil,
+		},
+	} {
+		interiorPoint, err := xy.InteriorPoint(tc.geometry)
+		if err != nil {
+			t.Errorf("Test %v failed, unexpected error: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(interiorPoint, tc.interiorPoint) {
+			t.Errorf("Test %v failed, expected:\n\t%v\nbut was:\n\t%v", i+1, tc.interiorPoint, interiorPoint)
+		}
+	}
+}
+
+func TestInteriorPoint_InsidePolygon(t *testing.T) {
+	for i, polygon := range []*geom.Polygon{
+		geom.NewPolygonFlat(internal.RING.Layout(), internal.RING.FlatCoords(), []int{len(internal.RING.FlatCoords())}),
+		geom.NewPolygonFlat(geom.XY, []float64{
+			0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+			1, 1, 9, 1, 9, 9, 1, 9, 1, 1,
+		}, []int{10, 20}),
+	} {
+		interiorPoint, err := xy.InteriorPoint(polygon)
+		if err != nil {
+			t.Errorf("Test %v failed, unexpected error: %v", i+1, err)
+			continue
+		}
+		if loc := xy.LocatePointInRing(polygon.Layout(), interiorPoint, polygon.LinearRing(0).FlatCoords()); loc != location.Interior {
+			t.Errorf("Test %v failed, %v is not inside the shell", i+1, interiorPoint)
+		}
+		for j := 1; j < polygon.NumLinearRings(); j++ {
+			if xy.IsPointInRing(polygon.Layout(), interiorPoint, polygon.LinearRing(j).FlatCoords()) {
+				t.Errorf("Test %v failed, %v is inside hole %d", i+1, interiorPoint, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package xy
+
+import (
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+)
+
+// LineMerge returns the line strings of geometry, which must be a LineString,
+// LinearRing, or MultiLineString, sewn together into maximal line strings.
+// Line strings are joined where exactly two of them meet at an endpoint, and
+// may be reversed to do so.  Line strings that form closed loops with no other
+// line strings meeting them are returned as closed line strings.  The input is
+// not noded, so line strings that cross are not split, see Node.  Line strings
+// of zero length are removed.
+func LineMerge(geometry geom.T) (*geom.MultiLineString, error) {
+	lines, err := flatLineStrings(geometry, "line merging")
+	if err != nil {
+		return nil, err
+	}
+	layout, stride := geometry.Layout(), geometry.Stride()
+
+	var edges [][]float64
+	for _, line := range lines {
+		if edge := appendUnrepeatedFlat(nil, line, stride); len(edge) >= 2*stride {
+			edges = append(edges, edge)
+		}
+	}
+
+	// the degree of a node is the number of edge ends at it
+	degree := make(map[[2]float64]int)
+	byNode := make(map[[2]float64][]int)
+	for i, edge := range edges {
+		start, end := nodeKey(edge[:stride]), nodeKey(edge[len(edge)-stride:])
+		degree[start]++
+		degree[end]++
+		byNode[start] = append(byNode[start], i)
+		if end != start {
+			byNode[end] = append(byNode[end], i)
+		}
+	}
+
+	visited := make([]bool, len(edges))
+	var flatCoords []float64
+	var ends []int
+	// walk appends the line starting with edge i in the given direction and
+	// continuing through nodes of degree two.
+	walk := func(i int, reversed bool) {
+		var merged []float64
+		for {
+			visited[i] = true
+			edge := edges[i]
+			if reversed {
+				edge = append([]float64(nil), edge...)
+				flat.Reverse(edge, stride)
+			}
+			if len(merged) == 0 {
+				merged = append(merged, edge...)
+			} else {
+				merged = append(merged, edge[stride:]...)
+			}
+			node := nodeKey(edge[len(edge)-stride:])
+			if degree[node] != 2 {
+				break
+			}
+			next := -1
+			for _, j := range byNode[node] {
+				if !visited[j] {
+					next = j
+					break
+				}
+			}
+			if next == -1 {
+				break
+			}
+			i, reversed = next, nodeKey(edges[next][:stride]) != node
+		}
+		flatCoords = append(flatCoords, merged...)
+		ends = append(ends, len(flatCoords))
+	}
+	for i, edge := range edges {
+		if visited[i] {
+			continue
+		}
+		if degree[nodeKey(edge[:stride])] != 2 {
+			walk(i, false)
+		} else if degree[nodeKey(edge[len(edge)-stride:])] != 2 {
+			walk(i, true)
+		}
+	}
+	// the remaining edges form loops whose nodes all have degree two
+	for i := range edges {
+		if !visited[i] {
+			walk(i, false)
+		}
+	}
+	return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+}
+
+// nodeKey returns a key that identifies the X and Y ordinates of coord.
+func nodeKey(coord []float64) [2]float64 {
+	return [2]float64{coord[0], coord[1]}
+}
+
+// appendUnrepeatedFlat appends the coordinates of flatCoords to dst, omitting
+// coordinates that are equal in X and Y to their predecessor.
+func appendUnrepeatedFlat(dst, flatCoords []float64, stride int) []float64 {
+	for i := 0; i < len(flatCoords); i += stride {
+		dst = flat.AppendUnrepeated(dst, flatCoords[i:i+stride], stride)
+	}
+	return dst
+}
@@ -0,0 +1,47 @@
+package xy_test
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func TestLineMerge(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want *geom.MultiLineString
+	}{
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1}, []int{6}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{1, 0, 2, 0, 1, 0, 0, 0, 2, 0, 3, 0}, []int{4, 8, 12}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0, 3, 0}, []int{8}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 1, 1, 0, 2, 2, 0, 3, 1, 0, 2}, []int{6, 12}),
+			want: geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 1, 1, 0, 2, 2, 0, 3}, []int{9}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 1, 1, 1, 0, 2, 0}, []int{4, 8, 12}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 1, 1, 1, 0, 2, 0}, []int{4, 8, 12}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 1, 1, 1, 1, 0, 0}, []int{4, 8, 12}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 0, 0, 5, 5, 6, 6}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{5, 5, 6, 6}, []int{4}),
+		},
+	} {
+		if got, err := xy.LineMerge(tc.g); err != nil || !geom.Equal(got, tc.want) {
+			t.Errorf("%d: LineMerge(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+	if _, err := xy.LineMerge(geom.NewPointFlat(geom.XY, []float64{0, 0})); err == nil {
+		t.Errorf("LineMerge(Point) == ..., <nil>, want ..., non-<nil>")
+	}
+}
@@ -1,6 +1,12 @@
+// Package lineintersection contains the results of line intersection
+// calculations, see the lineintersector package.
 package lineintersection
 
-import "github.com/twpayne/go-geom"
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
 
 // Type enumerates the types of intersection two lines can have
 type Type int
@@ -26,6 +32,7 @@ func (t Type) String() string {
 type Result struct {
 	intersectionType Type
 	intersection     []geom.Coord
+	lines            [2][2]geom.Coord
 }
 
 // NewResult create a new result object
@@ -35,6 +42,17 @@ func NewResult(intersectionType Type, intersection []geom.Coord) Result {
 		intersection:     intersection}
 }
 
+// NewResultWithLines creates a new result object for the intersection of
+// line1 and line2, where each line is given by its start and end points.  The
+// lines are required by EdgeDistance and Fraction.
+func NewResultWithLines(intersectionType Type, intersection []geom.Coord, line1, line2 [2]geom.Coord) Result {
+	return Result{
+		intersectionType: intersectionType,
+		intersection:     intersection,
+		lines:            [2][2]geom.Coord{line1, line2},
+	}
+}
+
 // HasIntersection returns true if the lines have an intersection
 func (i *Result) HasIntersection() bool {
 	return i.intersectionType != NoIntersection
@@ -52,3 +70,59 @@ func (i *Result) Type() Type {
 func (i *Result) Intersection() []geom.Coord {
 	return i.intersection
 }
+
+// EdgeDistance returns the edge distance of the intersectionIndex-th
+// intersection point along line lineIndex, which is 0 for the first line and
+// 1 for the second.  The edge distance is a cheap, robust measure of the
+// distance from the start of the line that increases monotonically along the
+// line, and is suitable for sorting intersection points along it.  It is zero
+// at the start of the line.  ok is false if the result was not created with
+// NewResultWithLines, in which case the lines are not known.
+func (i *Result) EdgeDistance(lineIndex, intersectionIndex int) (dist float64, ok bool) {
+	if !i.hasLines() {
+		return 0, false
+	}
+	p := i.intersection[intersectionIndex]
+	start, end := i.lines[lineIndex][0], i.lines[lineIndex][1]
+	dx, dy := math.Abs(end[0]-start[0]), math.Abs(end[1]-start[1])
+	switch {
+	case p[0] == start[0] && p[1] == start[1]:
+		return 0, true
+	case p[0] == end[0] && p[1] == end[1]:
+		return math.Max(dx, dy), true
+	}
+	pdx, pdy := math.Abs(p[0]-start[0]), math.Abs(p[1]-start[1])
+	dist = pdy
+	if dx > dy {
+		dist = pdx
+	}
+	// ensure that points that are not the start have a non-zero distance
+	if dist == 0 {
+		dist = math.Max(pdx, pdy)
+	}
+	return dist, true
+}
+
+// Fraction returns the fraction of the distance along line lineIndex, which
+// is 0 for the first line and 1 for the second, of the intersectionIndex-th
+// intersection point.  It is 0 at the start of the line and 1 at the end.  ok
+// is false if the result was not created with NewResultWithLines, in which
+// case the lines are not known.
+func (i *Result) Fraction(lineIndex, intersectionIndex int) (fraction float64, ok bool) {
+	if !i.hasLines() {
+		return 0, false
+	}
+	p := i.intersection[intersectionIndex]
+	start, end := i.lines[lineIndex][0], i.lines[lineIndex][1]
+	dx, dy := end[0]-start[0], end[1]-start[1]
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return 0, true
+	}
+	return ((p[0]-start[0])*dx + (p[1]-start[1])*dy) / lengthSquared, true
+}
+
+// hasLines returns true if i was created with the lines that intersect.
+func (i *Result) hasLines() bool {
+	return i.lines[0][0] != nil
+}
@@ -0,0 +1,36 @@
+package lineintersection
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestResultWithoutLines(t *testing.T) {
+	result := NewResult(PointIntersection, []geom.Coord{{1, 1}})
+	if got, ok := result.EdgeDistance(0, 0); ok {
+		t.Errorf("EdgeDistance(0, 0) == %v, true, want _, false", got)
+	}
+	if got, ok := result.Fraction(0, 0); ok {
+		t.Errorf("Fraction(0, 0) == %v, true, want _, false", got)
+	}
+}
+
+func TestResultWithLines(t *testing.T) {
+	result := NewResultWithLines(PointIntersection, []geom.Coord{{1, 1}}, [2]geom.Coord{{0, 0}, {4, 4}}, [2]geom.Coord{{1, 0}, {1, 2}})
+	for i, tc := range []struct {
+		lineIndex        int
+		wantEdgeDistance float64
+		wantFraction     float64
+	}{
+		{lineIndex: 0, wantEdgeDistance: 1, wantFraction: 0.25},
+		{lineIndex: 1, wantEdgeDistance: 1, wantFraction: 0.5},
+	} {
+		if got, ok := result.EdgeDistance(tc.lineIndex, 0); !ok || got != tc.wantEdgeDistance {
+			t.Errorf("%d: EdgeDistance(%d, 0) == %v, %v, want %v, true", i, tc.lineIndex, got, ok, tc.wantEdgeDistance)
+		}
+		if got, ok := result.Fraction(tc.lineIndex, 0); !ok || got != tc.wantFraction {
+			t.Errorf("%d: Fraction(%d, 0) == %v, %v, want %v, true", i, tc.lineIndex, got, ok, tc.wantFraction)
+		}
+	}
+}
@@ -1,3 +1,5 @@
+// Package lineintersector computes the intersections of line segments, using
+// either a robust or a faster non-robust Strategy.
 package lineintersector
 
 import (
@@ -54,7 +56,7 @@ func LineIntersectsLine(strategy Strategy, line1Start, line1End, line2Start, lin
 	case lineintersection.CollinearIntersection:
 		intersections = intersectorData.intersectionPoints[:2]
 	}
-	return lineintersection.NewResult(intersectorData.intersectionType, intersections)
+	return lineintersection.NewResultWithLines(intersectorData.intersectionType, intersections, [2]geom.Coord{line1Start, line1End}, [2]geom.Coord{line2Start, line2End})
 }
 
 // An internal data structure for containing the data during calculations
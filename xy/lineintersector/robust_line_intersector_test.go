@@ -4,6 +4,9 @@ import (
 	"reflect"
 	"runtime/debug"
 	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/lineintersection"
 )
 
 func TestRobustLineIntersectionPointOnLine(t *testing.T) {
@@ -43,8 +46,9 @@ func doLineIntersectsLineTest(t *testing.T, intersectionStrategy Strategy, i int
 	}()
 
 	calculatedResult := LineIntersectsLine(intersectionStrategy, tc.P1, tc.P2, tc.P3, tc.P4)
+	expectedResult := lineintersection.NewResultWithLines(tc.Result.Type(), tc.Result.Intersection(), [2]geom.Coord{tc.P1, tc.P2}, [2]geom.Coord{tc.P3, tc.P4})
 
-	if !reflect.DeepEqual(calculatedResult, tc.Result) {
-		t.Errorf("%T - Test '%v' (%v) failed: expected \n%v but was \n%v", intersectionStrategy, i+1, tc.Desc, tc.Result, calculatedResult)
+	if !reflect.DeepEqual(calculatedResult, expectedResult) {
+		t.Errorf("%T - Test '%v' (%v) failed: expected \n%v but was \n%v", intersectionStrategy, i+1, tc.Desc, expectedResult, calculatedResult)
 	}
 }
@@ -0,0 +1,124 @@
+package lineintersector
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/lineintersection"
+)
+
+// A Segment is a line segment of a line string.
+type Segment struct {
+	Start, End geom.Coord
+	// LineIndex is the index of the line string that contains the segment.
+	LineIndex int
+	// SegmentIndex is the index of the segment in its line string, i.e. the
+	// segment starts at the SegmentIndex-th coordinate.
+	SegmentIndex int
+}
+
+// An Intersection is an intersection between two Segments.
+type Intersection struct {
+	// I and J are the indexes of the intersecting segments, with I < J.
+	I, J int
+	// Result is the intersection of segment I, the first line, with segment
+	// J, the second line.
+	Result lineintersection.Result
+}
+
+// Segments returns the segments of lineStrings.  Segments of zero length are
+// included.
+func Segments(lineStrings ...*geom.LineString) []Segment {
+	var segments []Segment
+	for lineIndex, lineString := range lineStrings {
+		for i, n := 0, lineString.NumCoords(); i+1 < n; i++ {
+			segments = append(segments, Segment{
+				Start:        lineString.Coord(i),
+				End:          lineString.Coord(i + 1),
+				LineIndex:    lineIndex,
+				SegmentIndex: i,
+			})
+		}
+	}
+	return segments
+}
+
+// An event is a segment's extent in the X direction.
+type event struct {
+	minX, maxX float64
+	index      int
+}
+
+// eventsByMinX sorts events by their minimum X ordinate.
+type eventsByMinX []event
+
+func (s eventsByMinX) Len() int           { return len(s) }
+func (s eventsByMinX) Less(i, j int) bool { return s[i].minX < s[j].minX }
+func (s eventsByMinX) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// intersectionsByIJ sorts intersections by I and then by J.
+type intersectionsByIJ []Intersection
+
+func (s intersectionsByIJ) Len() int { return len(s) }
+func (s intersectionsByIJ) Less(i, j int) bool {
+	if s[i].I != s[j].I {
+		return s[i].I < s[j].I
+	}
+	return s[i].J < s[j].J
+}
+func (s intersectionsByIJ) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// FindIntersections returns the intersections between all pairs of segments,
+// computed with strategy.  Consecutive segments of the same line string
+// intersect at their shared coordinate and are included.  Candidate pairs
+// are found with a sweep line in the X direction, so only segments whose
+// bounds overlap are tested.  The intersections are sorted by I and then by J.
+func FindIntersections(strategy Strategy, segments []Segment) []Intersection {
+	events := make([]event, len(segments))
+	for i, segment := range segments {
+		events[i] = event{
+			minX:  math.Min(segment.Start[0], segment.End[0]),
+			maxX:  math.Max(segment.Start[0], segment.End[0]),
+			index: i,
+		}
+	}
+	sort.Sort(eventsByMinX(events))
+
+	var intersections []Intersection
+	var active []event
+	for _, e := range events {
+		// remove the segments that end before this segment starts
+		n := 0
+		for _, a := range active {
+			if a.maxX >= e.minX {
+				active[n] = a
+				n++
+			}
+		}
+		active = active[:n]
+
+		for _, a := range active {
+			i, j := a.index, e.index
+			if i > j {
+				i, j = j, i
+			}
+			s1, s2 := segments[i], segments[j]
+			if math.Max(s1.Start[1], s1.End[1]) < math.Min(s2.Start[1], s2.End[1]) ||
+				math.Max(s2.Start[1], s2.End[1]) < math.Min(s1.Start[1], s1.End[1]) {
+				continue
+			}
+			if result := LineIntersectsLine(strategy, s1.Start, s1.End, s2.Start, s2.End); result.HasIntersection() {
+				intersections = append(intersections, Intersection{
+					I:      i,
+					J:      j,
+					Result: result,
+				})
+			}
+		}
+		active = append(active, e)
+	}
+
+	sort.Sort(intersectionsByIJ(intersections))
+	return intersections
+}
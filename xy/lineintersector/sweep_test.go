@@ -0,0 +1,139 @@
+package lineintersector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/lineintersection"
+)
+
+func TestResultFractionAndEdgeDistance(t *testing.T) {
+	for i, tc := range []struct {
+		p1, p2, p3, p4    geom.Coord
+		wantFractions     [2][]float64
+		wantEdgeDistances [2][]float64
+	}{
+		{
+			p1: geom.Coord{0, 0}, p2: geom.Coord{10, 10}, p3: geom.Coord{0, 10}, p4: geom.Coord{10, 0},
+			wantFractions:     [2][]float64{{0.5}, {0.5}},
+			wantEdgeDistances: [2][]float64{{5}, {5}},
+		},
+		{
+			p1: geom.Coord{0, 0}, p2: geom.Coord{10, 0}, p3: geom.Coord{5, 0}, p4: geom.Coord{20, 0},
+			wantFractions:     [2][]float64{{0.5, 1}, {0, 1.0 / 3}},
+			wantEdgeDistances: [2][]float64{{5, 10}, {0, 5}},
+		},
+		{
+			p1: geom.Coord{0, 0}, p2: geom.Coord{4, 2}, p3: geom.Coord{4, 2}, p4: geom.Coord{4, 10},
+			wantFractions:     [2][]float64{{1}, {0}},
+			wantEdgeDistances: [2][]float64{{4}, {0}},
+		},
+	} {
+		for _, strategy := range []Strategy{RobustLineIntersector{}, NonRobustLineIntersector{}} {
+			result := LineIntersectsLine(strategy, tc.p1, tc.p2, tc.p3, tc.p4)
+			for lineIndex := 0; lineIndex < 2; lineIndex++ {
+				var gotFractions, gotEdgeDistances []float64
+				for j := range result.Intersection() {
+					fraction, ok := result.Fraction(lineIndex, j)
+					if !ok {
+						t.Errorf("%d: %T: Fraction(%d, %d) == _, false, want _, true", i, strategy, lineIndex, j)
+					}
+					edgeDistance, ok := result.EdgeDistance(lineIndex, j)
+					if !ok {
+						t.Errorf("%d: %T: EdgeDistance(%d, %d) == _, false, want _, true", i, strategy, lineIndex, j)
+					}
+					gotFractions = append(gotFractions, fraction)
+					gotEdgeDistances = append(gotEdgeDistances, edgeDistance)
+				}
+				if !reflect.DeepEqual(gotFractions, tc.wantFractions[lineIndex]) {
+					t.Errorf("%d: %T: fractions along line %d == %v, want %v", i, strategy, lineIndex, gotFractions, tc.wantFractions[lineIndex])
+				}
+				if !reflect.DeepEqual(gotEdgeDistances, tc.wantEdgeDistances[lineIndex]) {
+					t.Errorf("%d: %T: edge distances along line %d == %v, want %v", i, strategy, lineIndex, gotEdgeDistances, tc.wantEdgeDistances[lineIndex])
+				}
+			}
+		}
+	}
+}
+
+func TestSegments(t *testing.T) {
+	lineStrings := []*geom.LineString{
+		geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 0}),
+		geom.NewLineStringFlat(geom.XY, []float64{5, 5}),
+		geom.NewLineStringFlat(geom.XYZ, []float64{3, 3, 3, 4, 4, 4}),
+	}
+	want := []Segment{
+		{Start: geom.Coord{0, 0}, End: geom.Coord{1, 1}, LineIndex: 0, SegmentIndex: 0},
+		{Start: geom.Coord{1, 1}, End: geom.Coord{2, 0}, LineIndex: 0, SegmentIndex: 1},
+		{Start: geom.Coord{3, 3, 3}, End: geom.Coord{4, 4, 4}, LineIndex: 2, SegmentIndex: 0},
+	}
+	if got := Segments(lineStrings...); !reflect.DeepEqual(got, want) {
+		t.Errorf("Segments(...) == %v, want %v", got, want)
+	}
+}
+
+func TestFindIntersections(t *testing.T) {
+	type intersection struct {
+		I, J             int
+		intersectionType lineintersection.Type
+		intersection     []geom.Coord
+	}
+	for i, tc := range []struct {
+		lineStrings []*geom.LineString
+		want        []intersection
+	}{
+		{
+			lineStrings: []*geom.LineString{
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 10}),
+				geom.NewLineStringFlat(geom.XY, []float64{0, 10, 10, 0}),
+			},
+			want: []intersection{
+				{I: 0, J: 1, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{5, 5}}},
+			},
+		},
+		{
+			lineStrings: []*geom.LineString{
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 4, -2}),
+			},
+			want: []intersection{
+				{I: 0, J: 1, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{10, 0}}},
+				{I: 0, J: 2, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{5, 0}}},
+				{I: 1, J: 2, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{10, 10}}},
+			},
+		},
+		{
+			lineStrings: []*geom.LineString{
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 10, 0}),
+				geom.NewLineStringFlat(geom.XY, []float64{5, 0, 20, 0}),
+				geom.NewLineStringFlat(geom.XY, []float64{0, 1, 20, 1}),
+				geom.NewLineStringFlat(geom.XY, []float64{30, -10, 30, 10}),
+			},
+			want: []intersection{
+				{I: 0, J: 1, intersectionType: lineintersection.CollinearIntersection, intersection: []geom.Coord{{5, 0}, {10, 0}}},
+			},
+		},
+		{
+			lineStrings: []*geom.LineString{
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0}),
+				geom.NewLineStringFlat(geom.XY, []float64{0, 1, 1, 1}),
+			},
+			want: nil,
+		},
+	} {
+		for _, strategy := range []Strategy{RobustLineIntersector{}, NonRobustLineIntersector{}} {
+			var got []intersection
+			for _, in := range FindIntersections(strategy, Segments(tc.lineStrings...)) {
+				got = append(got, intersection{
+					I:                in.I,
+					J:                in.J,
+					intersectionType: in.Result.Type(),
+					intersection:     in.Result.Intersection(),
+				})
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%d: %T: FindIntersections(...) == %v, want %v", i, strategy, got, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package xy
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/location"
+)
+
+// MaximumInscribedCircle computes the largest circle that is contained within
+// a Polygon or MultiPolygon.  The center of the circle is the pole of
+// inaccessibility: the interior point that is furthest from the boundary,
+// which makes it a good place for a label.
+//
+// The center is found to within tolerance; tolerance must be greater than 0.
+// Only the x and y ordinates are considered, so the returned center is always
+// a 2 dimensional coordinate.
+//
+// Algorithm: the grid subdivision of the polylabel algorithm
+// (https://github.com/mapbox/polylabel), using a priority queue of cells
+// ordered by the maximum distance that they could contain.
+func MaximumInscribedCircle(geometry geom.T, tolerance float64) (center geom.Coord, radius float64, err error) {
+	var polygons []*geom.Polygon
+	switch t := geometry.(type) {
+	case *geom.Polygon:
+		polygons = []*geom.Polygon{t}
+	case *geom.MultiPolygon:
+		// MultiPolygon.Polygon does not support empty polygons, so split the
+		// flat coordinates directly
+		offset := 0
+		for _, ends := range t.Endss() {
+			if len(ends) == 0 {
+				continue
+			}
+			polygonEnds := make([]int, len(ends))
+			for j, end := range ends {
+				polygonEnds[j] = end - offset
+			}
+			polygons = append(polygons, geom.NewPolygonFlat(t.Layout(), t.FlatCoords()[offset:ends[len(ends)-1]], polygonEnds))
+			offset = ends[len(ends)-1]
+		}
+	default:
+		return nil, 0, fmt.Errorf("%v is not a supported type for maximum inscribed circle calculation", t)
+	}
+	if tolerance <= 0 {
+		return nil, 0, fmt.Errorf("tolerance must be greater than 0, was %v", tolerance)
+	}
+	// skip empty polygons, which have no centroid
+	nonEmpty := polygons[:0:0]
+	for _, polygon := range polygons {
+		if len(polygon.FlatCoords()) != 0 {
+			nonEmpty = append(nonEmpty, polygon)
+		}
+	}
+	polygons = nonEmpty
+	if len(polygons) == 0 {
+		return nil, 0, nil
+	}
+	centroid := PolygonsCentroid(polygons[0], polygons[1:]...)
+
+	bounds := geometry.Bounds()
+	minX, minY := bounds.Min(0), bounds.Min(1)
+	width, height := bounds.Max(0)-minX, bounds.Max(1)-minY
+	cellSize := math.Min(width, height)
+	if cellSize == 0 {
+		return geom.Coord{minX, minY}, 0, nil
+	}
+
+	calc := &inscribedCircleCalculator{polygons: polygons}
+	cells := &inscribedCellQueue{}
+	for x := minX; x < minX+width; x += cellSize {
+		for y := minY; y < minY+height; y += cellSize {
+			heap.Push(cells, calc.newCell(x+cellSize/2, y+cellSize/2, cellSize/2))
+		}
+	}
+
+	best := calc.newCell(centroid[0], centroid[1], 0)
+	if c := calc.newCell(minX+width/2, minY+height/2, 0); c.d > best.d {
+		best = c
+	}
+
+	for cells.Len() > 0 {
+		cell := heap.Pop(cells).(inscribedCell)
+		if cell.d > best.d {
+			best = cell
+		}
+		if cell.max-best.d <= tolerance {
+			continue
+		}
+		h := cell.h / 2
+		heap.Push(cells, calc.newCell(cell.x-h, cell.y-h, h))
+		heap.Push(cells, calc.newCell(cell.x+h, cell.y-h, h))
+		heap.Push(cells, calc.newCell(cell.x-h, cell.y+h, h))
+		heap.Push(cells, calc.newCell(cell.x+h, cell.y+h, h))
+	}
+
+	return geom.Coord{best.x, best.y}, math.Max(best.d, 0), nil
+}
+
+type inscribedCircleCalculator struct {
+	polygons []*geom.Polygon
+}
+
+// newCell creates a square cell centered on x, y with half size h.
+func (calc *inscribedCircleCalculator) newCell(x, y, h float64) inscribedCell {
+	d := calc.signedDistance(geom.Coord{x, y})
+	return inscribedCell{x: x, y: y, h: h, d: d, max: d + h*math.Sqrt2}
+}
+
+// signedDistance returns the distance from p to the nearest boundary of the
+// polygons, positive if p is inside a polygon and negative otherwise.
+func (calc *inscribedCircleCalculator) signedDistance(p geom.Coord) float64 {
+	inside := false
+	minDistance := math.Inf(1)
+	for _, polygon := range calc.polygons {
+		layout := polygon.Layout()
+		insidePolygon := true
+		for i := 0; i < polygon.NumLinearRings(); i++ {
+			ring := polygon.LinearRing(i).FlatCoords()
+			if len(ring) == 0 {
+				continue
+			}
+			loc := LocatePointInRing(layout, p, ring)
+			if i == 0 && loc == location.Exterior || i > 0 && loc == location.Interior {
+				insidePolygon = false
+			}
+			if d := DistanceFromPointToLineString(layout, p, ring); d < minDistance {
+				minDistance = d
+			}
+		}
+		if insidePolygon {
+			inside = true
+		}
+	}
+	if !inside {
+		return -minDistance
+	}
+	return minDistance
+}
+
+// An inscribedCell is a square cell of the polylabel search grid.
+type inscribedCell struct {
+	x, y float64 // center of the cell
+	h    float64 // half the cell size
+	d    float64 // signed distance from the cell center to the polygon boundary
+	max  float64 // maximum distance to the polygon boundary within the cell
+}
+
+// inscribedCellQueue is a priority queue of cells with the greatest potential
+// distance first.
+type inscribedCellQueue []inscribedCell
+
+func (q inscribedCellQueue) Len() int           { return len(q) }
+func (q inscribedCellQueue) Less(i, j int) bool { return q[i].max > q[j].max }
+func (q inscribedCellQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *inscribedCellQueue) Push(x interface{}) {
+	*q = append(*q, x.(inscribedCell))
+}
+
+func (q *inscribedCellQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	cell := old[n-1]
+	*q = old[:n-1]
+	return cell
+}
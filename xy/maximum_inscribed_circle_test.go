@@ -0,0 +1,88 @@
+package xy_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func TestMaximumInscribedCircle(t *testing.T) {
+	for i, tc := range []struct {
+		geometry geom.T
+		center   geom.Coord
+		radius   float64
+	}{
+		{
+			geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 2, 0, 2, 0, 0}, []int{10}),
+			center:   geom.Coord{2, 1},
+			radius:   1,
+		},
+		{
+			// U shape: the centroid lies outside the polygon and the largest
+			// circle touches both walls of a corner and the opposite inner
+			// vertex
+			geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 8, 10, 8, 2, 2, 2, 2, 10, 0, 10, 0, 0}, []int{18}),
+			radius:   4 - 2*math.Sqrt2,
+		},
+		{
+			// square with a hole in the middle
+			geometry: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				2, 2, 8, 2, 8, 8, 2, 8, 2, 2,
+			}, []int{10, 20}),
+			radius: 4 - 2*math.Sqrt2,
+		},
+		{
+			geometry: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 1, 0, 0,
+				10, 10, 16, 10, 16, 16, 10, 16, 10, 10,
+			}, [][]int{{10}, {20}}),
+			center: geom.Coord{13, 13},
+			radius: 3,
+		},
+		{
+			// empty components are ignored
+			geometry: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				10, 10, 16, 10, 16, 16, 10, 16, 10, 10,
+			}, [][]int{{}, {10}}),
+			center: geom.Coord{13, 13},
+			radius: 3,
+		},
+	} {
+		center, radius, err := xy.MaximumInscribedCircle(tc.geometry, 1e-4)
+		if err != nil {
+			t.Errorf("Test %v failed, unexpected error %v", i+1, err)
+			continue
+		}
+		if math.Abs(radius-tc.radius) > 1e-4 {
+			t.Errorf("Test %v failed, expected radius %v but was %v", i+1, tc.radius, radius)
+		}
+		if tc.center != nil && xy.Distance(center, tc.center) > 1e-3 {
+			t.Errorf("Test %v failed, expected center %v but was %v", i+1, tc.center, center)
+		}
+	}
+}
+
+func TestMaximumInscribedCircle_Errors(t *testing.T) {
+	if _, _, err := xy.MaximumInscribedCircle(geom.NewPointFlat(geom.XY, []float64{0, 0}), 1); err == nil {
+		t.Errorf("expected an error for a Point")
+	}
+	polygon := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})
+	if _, _, err := xy.MaximumInscribedCircle(polygon, 0); err == nil {
+		t.Errorf("expected an error for a tolerance of 0")
+	}
+}
+
+func TestMaximumInscribedCircle_Empty(t *testing.T) {
+	for i, g := range []geom.T{
+		geom.NewPolygon(geom.XY),
+		geom.NewMultiPolygon(geom.XY),
+		geom.NewMultiPolygonFlat(geom.XY, nil, [][]int{{}}),
+	} {
+		if center, radius, err := xy.MaximumInscribedCircle(g, 1e-4); err != nil || center != nil || radius != 0 {
+			t.Errorf("%d: MaximumInscribedCircle(%v, 1e-4) == %v, %v, %v, want <nil>, 0, <nil>", i, g, center, radius, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package xy
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/internal"
+)
+
+// MinimumBoundingCircle computes the smallest circle that contains all the
+// points of the geometry.  Only the x and y ordinates are considered, so the
+// returned center is always a 2 dimensional coordinate.
+//
+// If the geometry is empty a nil center and a radius of 0 are returned.
+//
+// Algorithm: Welzl's incremental algorithm applied to the vertices of the
+// convex hull of the geometry.  The vertices are shuffled using a fixed seed
+// so that the result is deterministic.
+func MinimumBoundingCircle(geometry geom.T) (center geom.Coord, radius float64) {
+	hull := ConvexHull(geometry)
+	if hull == nil {
+		return nil, 0
+	}
+	return MinimumBoundingCircleFlat(hull.Layout(), hull.FlatCoords())
+}
+
+// MinimumBoundingCircleFlat computes the smallest circle that contains all the
+// points in the coordinate array.  layout is only used to determine how to
+// find each coordinate.  X-Y are assumed to be the first two elements in each
+// coordinate.
+func MinimumBoundingCircleFlat(layout geom.Layout, coords []float64) (center geom.Coord, radius float64) {
+	stride := layout.Stride()
+	numPts := len(coords) / stride
+	if numPts == 0 {
+		return nil, 0
+	}
+
+	pts := make([]geom.Coord, numPts)
+	for i, j := range rand.New(rand.NewSource(1)).Perm(numPts) {
+		pts[i] = geom.Coord{coords[j*stride], coords[j*stride+1]}
+	}
+
+	center = geom.Coord{pts[0][0], pts[0][1]}
+	for i := 1; i < len(pts); i++ {
+		if isInCircle(center, radius, pts[i]) {
+			continue
+		}
+		center, radius = geom.Coord{pts[i][0], pts[i][1]}, 0
+		for j := 0; j < i; j++ {
+			if isInCircle(center, radius, pts[j]) {
+				continue
+			}
+			center, radius = diameterCircle(pts[i], pts[j])
+			for k := 0; k < j; k++ {
+				if isInCircle(center, radius, pts[k]) {
+					continue
+				}
+				center, radius = circumCircle(pts[i], pts[j], pts[k])
+			}
+		}
+	}
+	return center, radius
+}
+
+// isInCircle returns true if p lies inside or on the circle.  A small relative
+// tolerance is used to absorb floating point rounding error.
+func isInCircle(center geom.Coord, radius float64, p geom.Coord) bool {
+	return internal.Distance2D(center, p) <= radius*(1+1e-12)
+}
+
+// diameterCircle returns the circle that has the segment p1-p2 as its diameter.
+func diameterCircle(p1, p2 geom.Coord) (geom.Coord, float64) {
+	center := geom.Coord{(p1[0] + p2[0]) / 2, (p1[1] + p2[1]) / 2}
+	return center, internal.Distance2D(center, p1)
+}
+
+// circumCircle returns the circle passing through p1, p2 and p3.  If the
+// points are collinear the circle with the two most distant points as diameter
+// is returned instead.
+func circumCircle(p1, p2, p3 geom.Coord) (geom.Coord, float64) {
+	bx, by := p2[0]-p1[0], p2[1]-p1[1]
+	cx, cy := p3[0]-p1[0], p3[1]-p1[1]
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		d12, d13, d23 := internal.Distance2D(p1, p2), internal.Distance2D(p1, p3), internal.Distance2D(p2, p3)
+		switch math.Max(d12, math.Max(d13, d23)) {
+		case d12:
+			return diameterCircle(p1, p2)
+		case d13:
+			return diameterCircle(p1, p3)
+		default:
+			return diameterCircle(p2, p3)
+		}
+	}
+	b2 := bx*bx + by*by
+	c2 := cx*cx + cy*cy
+	ux := (cy*b2 - by*c2) / d
+	uy := (bx*c2 - cx*b2) / d
+	return geom.Coord{p1[0] + ux, p1[1] + uy}, math.Sqrt(ux*ux + uy*uy)
+}
@@ -0,0 +1,17 @@
+package xy_test
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func ExampleMinimumBoundingCircle() {
+	polygon := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 2, 0, 2, 0, 0}, []int{10})
+
+	center, radius := xy.MinimumBoundingCircle(polygon)
+
+	fmt.Printf("%v %.4f\n", center, radius)
+	// Output: [2 1] 2.2361
+}
@@ -0,0 +1,74 @@
+package xy_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/internal"
+)
+
+func TestMinimumBoundingCircle(t *testing.T) {
+	for i, tc := range []struct {
+		geometry geom.T
+		center   geom.Coord
+		radius   float64
+	}{
+		{
+			geometry: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			center:   geom.Coord{1, 2},
+			radius:   0,
+		},
+		{
+			geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 4, 4}),
+			center:   geom.Coord{2, 2},
+			radius:   math.Sqrt(8),
+		},
+		{
+			geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0}, []int{10}),
+			center:   geom.Coord{1, 1},
+			radius:   math.Sqrt2,
+		},
+		{
+			geometry: geom.NewMultiPointFlat(geom.XY, []float64{-1, 0, 1, 0, 0, 0.5}),
+			center:   geom.Coord{0, 0},
+			radius:   1,
+		},
+		{
+			geometry: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 6, 0, 3, 3}),
+			center:   geom.Coord{3, 0},
+			radius:   3,
+		},
+	} {
+		center, radius := xy.MinimumBoundingCircle(tc.geometry)
+		if !reflect.DeepEqual(center, tc.center) || math.Abs(radius-tc.radius) > 1e-12 {
+			t.Errorf("Test %v failed, expected center %v radius %v but was center %v radius %v", i+1, tc.center, tc.radius, center, radius)
+		}
+	}
+}
+
+func TestMinimumBoundingCircle_Empty(t *testing.T) {
+	center, radius := xy.MinimumBoundingCircle(geom.NewLineString(geom.XY))
+	if center != nil || radius != 0 {
+		t.Errorf("expected nil center and 0 radius but was center %v radius %v", center, radius)
+	}
+}
+
+func TestMinimumBoundingCircle_ContainsAllPoints(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	flatCoords := make([]float64, 2000)
+	for i := range flatCoords {
+		flatCoords[i] = r.NormFloat64() * 100
+	}
+	for _, g := range []geom.T{internal.RING, geom.NewMultiPointFlat(geom.XY, flatCoords)} {
+		center, radius := xy.MinimumBoundingCircle(g)
+		for i := 0; i < len(g.FlatCoords()); i += g.Stride() {
+			if d := xy.Distance(center, g.FlatCoords()[i:i+2]); d > radius*(1+1e-9) {
+				t.Errorf("point %v is outside circle %v, %v", g.FlatCoords()[i:i+2], center, radius)
+			}
+		}
+	}
+}
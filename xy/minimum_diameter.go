@@ -0,0 +1,191 @@
+package xy
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// MinimumDiameter computes the minimum diameter (the minimum width) of the
+// geometry.  The minimum diameter is the smallest distance between two
+// parallel lines that enclose the geometry.
+//
+// The returned LineString runs from the supporting vertex to the supporting
+// edge of the convex hull, so its length is the minimum width.  The LineString
+// always has the XY layout.  If the geometry is a single point or all its
+// points are collinear then the minimum width is 0 and a zero length
+// LineString is returned.  If the geometry is empty then nil is returned.
+//
+// Algorithm: rotating calipers over the convex hull of the geometry
+func MinimumDiameter(geometry geom.T) *geom.LineString {
+	pts := convexHullRing(geometry)
+	switch len(pts) {
+	case 0:
+		return nil
+	case 1, 2:
+		return geom.NewLineStringFlat(geom.XY, []float64{pts[0][0], pts[0][1], pts[0][0], pts[0][1]})
+	}
+
+	minWidth := math.Inf(1)
+	var line []float64
+	rotatingCalipers(pts, func(c caliper) {
+		if c.width < minWidth {
+			minWidth = c.width
+			far := pts[c.far]
+			proj := c.at(c.project(far), 0)
+			line = []float64{far[0], far[1], proj[0], proj[1]}
+		}
+	})
+	return geom.NewLineStringFlat(geom.XY, line)
+}
+
+// MinimumAreaRectangle computes the minimum area rectangle enclosing the
+// geometry.  The rectangle may be rotated with respect to the axes.
+//
+// The result is a Polygon with the XY layout.  If all the points of the
+// geometry are collinear then a LineString spanning the points is returned
+// instead, and if the geometry consists of a single point then that Point is
+// returned.  If the geometry is empty then nil is returned.
+//
+// Algorithm: rotating calipers over the convex hull of the geometry.  One edge
+// of the minimum area rectangle is always collinear with an edge of the convex
+// hull.
+func MinimumAreaRectangle(geometry geom.T) geom.T {
+	pts := convexHullRing(geometry)
+	switch len(pts) {
+	case 0:
+		return nil
+	case 1:
+		return geom.NewPointFlat(geom.XY, []float64{pts[0][0], pts[0][1]})
+	case 2:
+		return geom.NewLineStringFlat(geom.XY, []float64{pts[0][0], pts[0][1], pts[1][0], pts[1][1]})
+	}
+
+	minArea := math.Inf(1)
+	var rectangle []float64
+	rotatingCalipers(pts, func(c caliper) {
+		minU, maxU := c.project(pts[c.left]), c.project(pts[c.right])
+		if area := (maxU - minU) * c.width; area < minArea {
+			minArea = area
+			c0, c1 := c.at(minU, 0), c.at(maxU, 0)
+			c2, c3 := c.at(maxU, c.width), c.at(minU, c.width)
+			rectangle = []float64{c0[0], c0[1], c1[0], c1[1], c2[0], c2[1], c3[0], c3[1], c0[0], c0[1]}
+		}
+	})
+	return geom.NewPolygonFlat(geom.XY, rectangle, []int{len(rectangle)})
+}
+
+// convexHullRing returns the distinct vertices of the convex hull of the
+// geometry in counter-clockwise order.  Degenerate hulls are returned as one
+// (a point) or two (a line) vertices.
+func convexHullRing(geometry geom.T) []geom.Coord {
+	hull := ConvexHull(geometry)
+	if hull == nil {
+		return nil
+	}
+	stride := hull.Stride()
+	flatCoords := hull.FlatCoords()
+	if _, ok := hull.(*geom.Polygon); ok {
+		// drop the closing point
+		flatCoords = flatCoords[:len(flatCoords)-stride]
+	}
+	pts := make([]geom.Coord, 0, len(flatCoords)/stride)
+	for i := 0; i < len(flatCoords); i += stride {
+		pts = append(pts, geom.Coord{flatCoords[i], flatCoords[i+1]})
+	}
+	if len(pts) == 2 && pts[0].Equal(geom.XY, pts[1]) {
+		pts = pts[:1]
+	}
+	if len(pts) >= 3 && signedDoubleArea(pts) < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	return pts
+}
+
+// signedDoubleArea returns twice the signed area of the (implicitly closed)
+// ring, positive if the ring is counter-clockwise.
+func signedDoubleArea(pts []geom.Coord) float64 {
+	sum := 0.0
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		sum += pts[i][0]*pts[j][1] - pts[j][0]*pts[i][1]
+	}
+	return sum
+}
+
+// A caliper describes the state of the rotating calipers for one edge of a
+// convex polygon.
+type caliper struct {
+	origin geom.Coord // start of the edge
+	ux, uy float64    // unit vector along the edge
+	vx, vy float64    // unit vector perpendicular to the edge, pointing inwards
+	far    int        // index of the vertex furthest from the edge
+	left   int        // index of the vertex with the minimum projection onto the edge
+	right  int        // index of the vertex with the maximum projection onto the edge
+	width  float64    // distance from the edge to the furthest vertex
+}
+
+// project returns the position of p along the edge direction, relative to the
+// edge origin.
+func (c caliper) project(p geom.Coord) float64 {
+	return (p[0]-c.origin[0])*c.ux + (p[1]-c.origin[1])*c.uy
+}
+
+// height returns the distance of p from the edge line, positive inside the
+// polygon.
+func (c caliper) height(p geom.Coord) float64 {
+	return (p[0]-c.origin[0])*c.vx + (p[1]-c.origin[1])*c.vy
+}
+
+// at returns the point at position u along the edge and distance v from it.
+func (c caliper) at(u, v float64) geom.Coord {
+	return geom.Coord{
+		c.origin[0] + u*c.ux + v*c.vx,
+		c.origin[1] + u*c.uy + v*c.vy,
+	}
+}
+
+// rotatingCalipers calls visit for every edge of the counter-clockwise convex
+// polygon pts (which must contain at least 3 distinct points) with the
+// extreme vertices relative to that edge.
+func rotatingCalipers(pts []geom.Coord, visit func(c caliper)) {
+	n := len(pts)
+	var c caliper
+	for i := 0; i < n; i++ {
+		p0, p1 := pts[i], pts[(i+1)%n]
+		dx, dy := p1[0]-p0[0], p1[1]-p0[1]
+		l := math.Hypot(dx, dy)
+		c.origin = p0
+		c.ux, c.uy = dx/l, dy/l
+		c.vx, c.vy = -c.uy, c.ux
+
+		if i == 0 {
+			for j := 1; j < n; j++ {
+				if c.height(pts[j]) > c.height(pts[c.far]) {
+					c.far = j
+				}
+				if c.project(pts[j]) > c.project(pts[c.right]) {
+					c.right = j
+				}
+				if c.project(pts[j]) < c.project(pts[c.left]) {
+					c.left = j
+				}
+			}
+		} else {
+			// the extreme vertices only ever advance as the calipers rotate
+			for k := 0; k < n && c.height(pts[(c.far+1)%n]) >= c.height(pts[c.far]); k++ {
+				c.far = (c.far + 1) % n
+			}
+			for k := 0; k < n && c.project(pts[(c.right+1)%n]) >= c.project(pts[c.right]); k++ {
+				c.right = (c.right + 1) % n
+			}
+			for k := 0; k < n && c.project(pts[(c.left+1)%n]) <= c.project(pts[c.left]); k++ {
+				c.left = (c.left + 1) % n
+			}
+		}
+		c.width = c.height(pts[c.far])
+		visit(c)
+	}
+}
@@ -0,0 +1,125 @@
+package xy
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/internal"
+)
+
+func TestMinimumDiameter(t *testing.T) {
+	for i, tc := range []struct {
+		geometry geom.T
+		expected *geom.LineString
+	}{
+		{
+			geometry: geom.NewLineString(geom.XY),
+			expected: nil,
+		},
+		{
+			geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			expected: geom.NewLineStringFlat(geom.XY, []float64{1, 2, 1, 2}),
+		},
+		{
+			geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2}),
+			expected: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0, 0}),
+		},
+		{
+			geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 2, 0, 2, 0, 0}, []int{10}),
+			expected: geom.NewLineStringFlat(geom.XY, []float64{4, 0, 4, 2}),
+		},
+	} {
+		diameter := MinimumDiameter(tc.geometry)
+		if !reflect.DeepEqual(diameter, tc.expected) {
+			t.Errorf("Test %v failed, expected:\n\t%v\nbut was:\n\t%v", i+1, tc.expected, diameter)
+		}
+	}
+}
+
+func TestMinimumAreaRectangle(t *testing.T) {
+	for i, tc := range []struct {
+		geometry geom.T
+		expected geom.T
+	}{
+		{
+			geometry: geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, 1, 2, 4}),
+			expected: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2}),
+			expected: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 2}),
+		},
+		{
+			geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 2, 0, 2, 0, 0}, []int{10}),
+			expected: geom.NewPolygonFlat(geom.XY, []float64{4, 0, 4, 2, 0, 2, 0, 0, 4, 0}, []int{10}),
+		},
+	} {
+		rectangle := MinimumAreaRectangle(tc.geometry)
+		if !reflect.DeepEqual(rectangle, tc.expected) {
+			t.Errorf("Test %v failed, expected:\n\t%v\nbut was:\n\t%v", i+1, tc.expected, rectangle)
+		}
+	}
+}
+
+func TestMinimumAreaRectangle_Rotated(t *testing.T) {
+	// a 4x2 rectangle rotated by 30 degrees
+	sin, cos := math.Sin(math.Pi/6), math.Cos(math.Pi/6)
+	var flatCoords []float64
+	for _, c := range [][2]float64{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {2, 1}, {0, 0}} {
+		flatCoords = append(flatCoords, c[0]*cos-c[1]*sin, c[0]*sin+c[1]*cos)
+	}
+	polygon := geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+
+	rectangle := MinimumAreaRectangle(polygon).(*geom.Polygon)
+	if area := rectangle.Area(); math.Abs(area-8) > 1e-9 {
+		t.Errorf("expected area 8 but was %v", area)
+	}
+	if width := MinimumDiameter(polygon).Length(); math.Abs(width-2) > 1e-9 {
+		t.Errorf("expected width 2 but was %v", width)
+	}
+}
+
+func TestRotatingCalipers(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for n := 3; n < 200; n += 7 {
+		flatCoords := make([]float64, 2*n)
+		for i := range flatCoords {
+			flatCoords[i] = r.Float64() * 100
+		}
+		pts := convexHullRing(geom.NewMultiPointFlat(geom.XY, flatCoords))
+		if len(pts) < 3 {
+			continue
+		}
+		rotatingCalipers(pts, func(c caliper) {
+			for j, p := range pts {
+				if c.height(p) > c.width+1e-9 {
+					t.Errorf("n=%d: vertex %d is further than far vertex %d", n, j, c.far)
+				}
+				if c.project(p) > c.project(pts[c.right])+1e-9 {
+					t.Errorf("n=%d: vertex %d is further right than vertex %d", n, j, c.right)
+				}
+				if c.project(p) < c.project(pts[c.left])-1e-9 {
+					t.Errorf("n=%d: vertex %d is further left than vertex %d", n, j, c.left)
+				}
+			}
+		})
+	}
+}
+
+func TestMinimumAreaRectangle_ContainsAllPoints(t *testing.T) {
+	rectangle := MinimumAreaRectangle(internal.RING).(*geom.Polygon)
+	hullArea := ConvexHull(internal.RING).(*geom.Polygon).Area()
+	if rectangle.Area() < hullArea {
+		t.Errorf("rectangle area %v is smaller than convex hull area %v", rectangle.Area(), hullArea)
+	}
+	ring := rectangle.LinearRing(0).FlatCoords()
+	for i := 0; i < len(internal.RING.FlatCoords()); i += 2 {
+		p := geom.Coord(internal.RING.FlatCoords()[i : i+2])
+		if !IsPointInRing(geom.XY, p, ring) && DistanceFromPointToLineString(geom.XY, p, ring) > 1e-9 {
+			t.Errorf("point %v is outside the rectangle %v", p, ring)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package xy
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+	"github.com/twpayne/go-geom/xy/lineintersector"
+)
+
+// Node returns the line strings of geometry, which must be a LineString,
+// LinearRing, or MultiLineString, split at every point where they intersect
+// themselves or each other, so that line strings in the result only meet at
+// their endpoints.  Where line strings overlap, the overlapping part is
+// included only once.  Coordinates inserted at intersection points have their
+// Z and M values, if any, interpolated along the line string that contains
+// them.  Repeated consecutive coordinates are removed.
+func Node(geometry geom.T) (*geom.MultiLineString, error) {
+	lines, err := flatLineStrings(geometry, "noding")
+	if err != nil {
+		return nil, err
+	}
+	layout, stride := geometry.Layout(), geometry.Stride()
+
+	segments, ordinals, numSegments := nonDegenerateSegments(lines, stride)
+	nodes := make([][]segmentNode, len(segments))
+	for _, intersection := range lineintersector.FindIntersections(lineintersector.RobustLineIntersector{}, segments) {
+		i, j := intersection.I, intersection.J
+		if isAllowedIntersection(lines, stride, segments[i], segments[j], ordinals[i], ordinals[j], numSegments, intersection.Result) {
+			continue
+		}
+		// the results of FindIntersections always have lines
+		for k, p := range intersection.Result.Intersection() {
+			fraction0, _ := intersection.Result.Fraction(0, k)
+			fraction1, _ := intersection.Result.Fraction(1, k)
+			nodes[i] = append(nodes[i], segmentNode{fraction: fraction0, x: p[0], y: p[1]})
+			nodes[j] = append(nodes[j], segmentNode{fraction: fraction1, x: p[0], y: p[1]})
+		}
+	}
+
+	var flatCoords []float64
+	var ends []int
+	seen := make(map[string]bool)
+	for _, piece := range splitAtNodes(lines, stride, segments, nodes) {
+		if key := edgeKey(piece, stride); !seen[key] {
+			seen[key] = true
+			flatCoords = append(flatCoords, piece...)
+			ends = append(ends, len(flatCoords))
+		}
+	}
+	return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+}
+
+// splitAtNodes returns the pieces of lines, whose segments are segments,
+// split at nodes, where nodes[i] are the nodes of segments[i].  Repeated
+// consecutive coordinates are removed and pieces that collapse to a single
+// point are omitted.
+func splitAtNodes(lines [][]float64, stride int, segments []lineintersector.Segment, nodes [][]segmentNode) [][]float64 {
+	var pieces [][]float64
+	var piece []float64
+	flush := func() {
+		if len(piece) >= 2*stride {
+			pieces = append(pieces, piece)
+		}
+		piece = append([]float64(nil), piece[len(piece)-stride:]...)
+	}
+	segmentIndex := 0
+	for lineIndex, line := range lines {
+		piece = nil
+		if len(line) > 0 {
+			piece = append(piece, line[:stride]...)
+		}
+		for ; segmentIndex < len(segments) && segments[segmentIndex].LineIndex == lineIndex; segmentIndex++ {
+			segment := segments[segmentIndex]
+			segmentNodes := nodes[segmentIndex]
+			sort.Stable(segmentNodesByFraction(segmentNodes))
+			splitAtEnd := false
+			for _, node := range segmentNodes {
+				switch {
+				case node.fraction <= 0:
+					flush()
+				case node.fraction >= 1:
+					splitAtEnd = true
+				default:
+					piece = flat.AppendUnrepeated(piece, interpolateNode(segment.Start, segment.End, node), stride)
+					flush()
+				}
+			}
+			piece = flat.AppendUnrepeated(piece, segment.End, stride)
+			if splitAtEnd {
+				flush()
+			}
+		}
+		if len(piece) >= 2*stride {
+			flush()
+		}
+	}
+	return pieces
+}
+
+// A segmentNode is a point at which a segment is split.
+type segmentNode struct {
+	fraction float64
+	x, y     float64
+}
+
+// segmentNodesByFraction sorts segmentNodes by their fraction.
+type segmentNodesByFraction []segmentNode
+
+func (s segmentNodesByFraction) Len() int           { return len(s) }
+func (s segmentNodesByFraction) Less(i, j int) bool { return s[i].fraction < s[j].fraction }
+func (s segmentNodesByFraction) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// interpolateNode returns the coordinate of node on the segment from start to
+// end, with ordinates other than X and Y interpolated.
+func interpolateNode(start, end geom.Coord, node segmentNode) []float64 {
+	coord := make([]float64, len(start))
+	f := math.Max(0, math.Min(node.fraction, 1))
+	for i := range coord {
+		coord[i] = start[i] + f*(end[i]-start[i])
+	}
+	coord[0], coord[1] = node.x, node.y
+	return coord
+}
+
+// edgeKey returns a key that identifies the X and Y coordinates of the line
+// string in flatCoords independently of its direction.
+func edgeKey(flatCoords []float64, stride int) string {
+	n := len(flatCoords) / stride
+	forward := make([]float64, 0, 2*n)
+	reverse := make([]float64, 0, 2*n)
+	for i := 0; i < n; i++ {
+		forward = append(forward, flatCoords[i*stride], flatCoords[i*stride+1])
+		reverse = append(reverse, flatCoords[(n-1-i)*stride], flatCoords[(n-1-i)*stride+1])
+	}
+	key := forward
+	for i := range forward {
+		if forward[i] != reverse[i] {
+			if reverse[i] < forward[i] {
+				key = reverse
+			}
+			break
+		}
+	}
+	b := make([]byte, 0, 8*len(key))
+	for _, x := range key {
+		bits := math.Float64bits(x)
+		for i := 0; i < 8; i++ {
+			b = append(b, byte(bits>>(8*i)))
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,51 @@
+package xy_test
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func TestNode(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want *geom.MultiLineString
+	}{
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0}, []int{10}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 2, 2, 0, 0, 2}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 1, 1, 2, 2, 2, 0, 1, 1, 1, 1, 0, 2}, []int{4, 12, 16}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XYZ, []float64{0, 0, 0, 2, 2, 2, 0, 2, 4, 2, 0, 6}, []int{6, 12}),
+			want: geom.NewMultiLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 1, 1, 1, 1, 1, 2, 2, 2, 0, 2, 4, 1, 1, 5, 1, 1, 5, 2, 0, 6}, []int{6, 12, 18, 24}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 1, 0, 3, 0}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 2, 0, 2, 0, 3, 0}, []int{4, 8, 12}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 1, 0, 1, 1}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 2, 0, 1, 0, 1, 1}, []int{4, 8, 12}),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 1, 0, 0, 0}, []int{4, 6, 10}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0}, []int{4}),
+		},
+		{
+			g:    geom.NewMultiLineString(geom.XY),
+			want: geom.NewMultiLineString(geom.XY),
+		},
+	} {
+		if got, err := xy.Node(tc.g); err != nil || !geom.Equal(got, tc.want) {
+			t.Errorf("%d: Node(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+	if _, err := xy.Node(geom.NewPointFlat(geom.XY, []float64{0, 0})); err == nil {
+		t.Errorf("Node(Point) == ..., <nil>, want ..., non-<nil>")
+	}
+}
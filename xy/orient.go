@@ -0,0 +1,159 @@
+package xy
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+	"github.com/twpayne/go-geom/xy/orientation"
+)
+
+// Reverse returns a copy of geometry with the order of the coordinates in
+// each line string and polygon ring reversed.  The order of the points of a
+// MultiPoint and of the components of other multi-geometries is unchanged.
+func Reverse(geometry geom.T) (geom.T, error) {
+	switch t := geometry.(type) {
+	case *geom.Point:
+		return t.Clone().SetSRID(t.SRID()), nil
+	case *geom.MultiPoint:
+		return t.Clone().SetSRID(t.SRID()), nil
+	case *geom.LineString:
+		r := t.Clone().SetSRID(t.SRID())
+		flat.Reverse(r.FlatCoords(), r.Stride())
+		return r, nil
+	case *geom.LinearRing:
+		r := t.Clone().SetSRID(t.SRID())
+		flat.Reverse(r.FlatCoords(), r.Stride())
+		return r, nil
+	case *geom.MultiLineString:
+		r := t.Clone().SetSRID(t.SRID())
+		reverseFlat2(r.FlatCoords(), 0, r.Ends(), r.Stride())
+		return r, nil
+	case *geom.Polygon:
+		r := t.Clone().SetSRID(t.SRID())
+		reverseFlat2(r.FlatCoords(), 0, r.Ends(), r.Stride())
+		return r, nil
+	case *geom.MultiPolygon:
+		r := t.Clone().SetSRID(t.SRID())
+		offset := 0
+		for _, ends := range r.Endss() {
+			offset = reverseFlat2(r.FlatCoords(), offset, ends, r.Stride())
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("%v is not a supported type for reversal", t)
+	}
+}
+
+// IsOriented returns true if the exterior rings of the polygons in geometry
+// have orientation exterior and their interior rings have the opposite
+// orientation.  Rings with fewer than three distinct points are ignored.
+// Geometries other than Polygons and MultiPolygons are always oriented.
+func IsOriented(geometry geom.T, exterior orientation.Type) bool {
+	switch t := geometry.(type) {
+	case *geom.Polygon:
+		return isOrientedFlat(t.Layout(), t.FlatCoords(), 0, t.Ends(), exterior)
+	case *geom.MultiPolygon:
+		offset := 0
+		for _, ends := range t.Endss() {
+			if !isOrientedFlat(t.Layout(), t.FlatCoords(), offset, ends, exterior) {
+				return false
+			}
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// Orient returns geometry with the exterior rings of its polygons oriented
+// in direction exterior, which must be orientation.Clockwise or
+// orientation.CounterClockwise, and their interior rings oriented in the
+// opposite direction.  The OGC Simple Features specification and RFC 7946
+// GeoJSON use counter-clockwise exterior rings, and Esri Shapefiles use
+// clockwise exterior rings.
+//
+// If geometry is already oriented, or is not a Polygon or MultiPolygon, then
+// it is returned unchanged.  Otherwise, the returned geometry is a copy that
+// does not alias geometry.
+func Orient(geometry geom.T, exterior orientation.Type) (geom.T, error) {
+	if exterior != orientation.Clockwise && exterior != orientation.CounterClockwise {
+		return nil, fmt.Errorf("%v is not a valid ring orientation", exterior)
+	}
+	if IsOriented(geometry, exterior) {
+		return geometry, nil
+	}
+	switch t := geometry.(type) {
+	case *geom.Polygon:
+		p := t.Clone().SetSRID(t.SRID())
+		orientFlat(p.Layout(), p.FlatCoords(), 0, p.Ends(), exterior)
+		return p, nil
+	case *geom.MultiPolygon:
+		mp := t.Clone().SetSRID(t.SRID())
+		offset := 0
+		for _, ends := range mp.Endss() {
+			offset = orientFlat(mp.Layout(), mp.FlatCoords(), offset, ends, exterior)
+		}
+		return mp, nil
+	default:
+		return geometry, nil
+	}
+}
+
+// ringOrientation returns the orientation of ring, or orientation.Collinear
+// if it has fewer than three distinct points.
+func ringOrientation(layout geom.Layout, ring []float64) orientation.Type {
+	if len(ring) < 4*layout.Stride() {
+		return orientation.Collinear
+	}
+	if IsRingCounterClockwise(layout, ring) {
+		return orientation.CounterClockwise
+	}
+	if SignedArea(layout, ring) == 0 {
+		return orientation.Collinear
+	}
+	return orientation.Clockwise
+}
+
+// isOrientedFlat returns true if the polygon in flatCoords starting at offset
+// with the given ends is oriented.
+func isOrientedFlat(layout geom.Layout, flatCoords []float64, offset int, ends []int, exterior orientation.Type) bool {
+	want := exterior
+	for _, end := range ends {
+		if o := ringOrientation(layout, flatCoords[offset:end]); o != orientation.Collinear && o != want {
+			return false
+		}
+		offset = end
+		want = -exterior
+	}
+	return true
+}
+
+// orientFlat orients the polygon in flatCoords starting at offset with the
+// given ends in place, and returns the offset of the next polygon.
+func orientFlat(layout geom.Layout, flatCoords []float64, offset int, ends []int, exterior orientation.Type) int {
+	want := exterior
+	for _, end := range ends {
+		ring := flatCoords[offset:end]
+		if o := ringOrientation(layout, ring); o != orientation.Collinear && o != want {
+			flat.Reverse(ring, layout.Stride())
+		}
+		offset = end
+		want = -exterior
+	}
+	return offset
+}
+
+// reverseFlat2 reverses the order of the coordinates in each of the rings or
+// line strings in flatCoords starting at offset with the given ends in place,
+// and returns the offset of the next geometry.
+func reverseFlat2(flatCoords []float64, offset int, ends []int, stride int) int {
+	for _, end := range ends {
+		flat.Reverse(flatCoords[offset:end], stride)
+		offset = end
+	}
+	return offset
+}
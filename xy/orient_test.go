@@ -0,0 +1,143 @@
+package xy_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/orientation"
+)
+
+var (
+	ccwSquare = []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}
+	cwSquare  = []float64{0, 0, 0, 4, 4, 4, 4, 0, 0, 0}
+	ccwHole   = []float64{1, 1, 2, 1, 2, 2, 1, 1}
+	cwHole    = []float64{1, 1, 2, 2, 2, 1, 1, 1}
+)
+
+func concat(flatCoordss ...[]float64) []float64 {
+	var result []float64
+	for _, flatCoords := range flatCoordss {
+		result = append(result, flatCoords...)
+	}
+	return result
+}
+
+func TestReverse(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want geom.T
+	}{
+		{
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			want: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+		},
+		{
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+			want: geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+		},
+		{
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9}).SetSRID(3857),
+			want: geom.NewLineStringFlat(geom.XYZ, []float64{7, 8, 9, 4, 5, 6, 1, 2, 3}).SetSRID(3857),
+		},
+		{
+			g:    geom.NewLinearRingFlat(geom.XY, ccwSquare),
+			want: geom.NewLinearRingFlat(geom.XY, cwSquare),
+		},
+		{
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{4, 10}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{3, 4, 1, 2, 9, 10, 7, 8, 5, 6}, []int{4, 10}),
+		},
+		{
+			g:    geom.NewPolygonFlat(geom.XY, concat(ccwSquare, cwHole), []int{10, 18}),
+			want: geom.NewPolygonFlat(geom.XY, concat(cwSquare, ccwHole), []int{10, 18}),
+		},
+		{
+			g:    geom.NewMultiPolygonFlat(geom.XY, concat(ccwSquare, cwHole, ccwSquare), [][]int{{10, 18}, {28}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, concat(cwSquare, ccwHole, cwSquare), [][]int{{10, 18}, {28}}),
+		},
+	} {
+		flatCoords := append([]float64(nil), tc.g.FlatCoords()...)
+		if got, err := xy.Reverse(tc.g); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Reverse(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+		if !reflect.DeepEqual(tc.g.FlatCoords(), flatCoords) {
+			t.Errorf("%d: Reverse modified its argument", i)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	for i, tc := range []struct {
+		g        geom.T
+		exterior orientation.Type
+		want     geom.T
+	}{
+		{
+			g:        geom.NewPolygonFlat(geom.XY, concat(cwSquare, ccwHole), []int{10, 18}).SetSRID(4326),
+			exterior: orientation.CounterClockwise,
+			want:     geom.NewPolygonFlat(geom.XY, concat(ccwSquare, cwHole), []int{10, 18}).SetSRID(4326),
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, concat(ccwSquare, ccwHole), []int{10, 18}),
+			exterior: orientation.CounterClockwise,
+			want:     geom.NewPolygonFlat(geom.XY, concat(ccwSquare, cwHole), []int{10, 18}),
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, concat(ccwSquare, cwHole), []int{10, 18}),
+			exterior: orientation.Clockwise,
+			want:     geom.NewPolygonFlat(geom.XY, concat(cwSquare, ccwHole), []int{10, 18}),
+		},
+		{
+			g:        geom.NewMultiPolygonFlat(geom.XY, concat(ccwSquare, cwSquare, cwHole), [][]int{{10}, {20, 28}}),
+			exterior: orientation.CounterClockwise,
+			want:     geom.NewMultiPolygonFlat(geom.XY, concat(ccwSquare, ccwSquare, cwHole), [][]int{{10}, {20, 28}}),
+		},
+		{
+			g:        geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 1, 0, 0}, []int{6}),
+			exterior: orientation.Clockwise,
+			want:     geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 1, 0, 0}, []int{6}),
+		},
+		{
+			g:        geom.NewLineStringFlat(geom.XY, cwSquare),
+			exterior: orientation.CounterClockwise,
+			want:     geom.NewLineStringFlat(geom.XY, cwSquare),
+		},
+	} {
+		flatCoords := append([]float64(nil), tc.g.FlatCoords()...)
+		got, err := xy.Orient(tc.g, tc.exterior)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Orient(%v, %v) == %v, %v, want %v, <nil>", i, tc.g, tc.exterior, got, err, tc.want)
+		}
+		if !xy.IsOriented(got, tc.exterior) {
+			t.Errorf("%d: IsOriented(Orient(%v, %v), %v) == false, want true", i, tc.g, tc.exterior, tc.exterior)
+		}
+		if !reflect.DeepEqual(tc.g.FlatCoords(), flatCoords) {
+			t.Errorf("%d: Orient modified its argument", i)
+		}
+	}
+	if _, err := xy.Orient(geom.NewPolygon(geom.XY), orientation.Collinear); err == nil {
+		t.Errorf("Orient(..., Collinear) == ..., <nil>, want ..., non-<nil>")
+	}
+}
+
+func TestIsOriented(t *testing.T) {
+	for i, tc := range []struct {
+		g        geom.T
+		exterior orientation.Type
+		want     bool
+	}{
+		{g: geom.NewPolygonFlat(geom.XY, concat(ccwSquare, cwHole), []int{10, 18}), exterior: orientation.CounterClockwise, want: true},
+		{g: geom.NewPolygonFlat(geom.XY, concat(ccwSquare, cwHole), []int{10, 18}), exterior: orientation.Clockwise, want: false},
+		{g: geom.NewPolygonFlat(geom.XY, concat(ccwSquare, ccwHole), []int{10, 18}), exterior: orientation.CounterClockwise, want: false},
+		{g: geom.NewMultiPolygonFlat(geom.XY, concat(cwSquare, cwSquare), [][]int{{10}, {20}}), exterior: orientation.Clockwise, want: true},
+		{g: geom.NewMultiPolygonFlat(geom.XY, concat(cwSquare, ccwSquare), [][]int{{10}, {20}}), exterior: orientation.Clockwise, want: false},
+		{g: geom.NewMultiPolygon(geom.XY), exterior: orientation.Clockwise, want: true},
+		{g: geom.NewPointFlat(geom.XY, []float64{1, 2}), exterior: orientation.Clockwise, want: true},
+	} {
+		if got := xy.IsOriented(tc.g, tc.exterior); got != tc.want {
+			t.Errorf("%d: IsOriented(%v, %v) == %t, want %t", i, tc.g, tc.exterior, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package xy
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/flat"
+	"github.com/twpayne/go-geom/xy/location"
+)
+
+// A PolygonizeResult is the result of Polygonize.
+type PolygonizeResult struct {
+	// Polygons are the polygons formed by the line strings.
+	Polygons []*geom.Polygon
+	// Dangles are the line strings that have at least one endpoint that is
+	// not shared with any other line string.
+	Dangles []*geom.LineString
+	// CutEdges are the line strings that are connected at both ends but do
+	// not form part of a polygon's boundary.
+	CutEdges []*geom.LineString
+	// InvalidRings are the closed rings formed by the line strings that are
+	// not valid polygon exterior rings, for example because they intersect
+	// themselves.
+	InvalidRings []*geom.LineString
+}
+
+// Polygonize returns the polygons formed by the line strings of geometry,
+// which must be a LineString, LinearRing, or MultiLineString.  The line
+// strings must be correctly noded, i.e. they must only meet at their
+// endpoints, see Node.  Each minimal area enclosed by the line strings becomes
+// a polygon, with an exterior ring oriented counter-clockwise.  Areas enclosed
+// by other polygons, and not themselves part of any polygon, become holes
+// oriented clockwise, and may touch their exterior ring or each other at a
+// single point.  Line strings that do not contribute to any polygon are
+// reported as dangles or cut edges, and rings that cannot form valid polygons
+// are reported as invalid rings.  Repeated consecutive coordinates and line
+// strings of zero length are removed, and line strings that appear more than
+// once are only used once.
+func Polygonize(geometry geom.T) (*PolygonizeResult, error) {
+	lines, err := flatLineStrings(geometry, "polygonization")
+	if err != nil {
+		return nil, err
+	}
+	layout, stride := geometry.Layout(), geometry.Stride()
+	srid := geometry.SRID()
+	g := newPolygonizeGraph(lines, stride)
+	result := &PolygonizeResult{}
+	newLineString := func(flatCoords []float64) *geom.LineString {
+		return geom.NewLineStringFlat(layout, flatCoords).SetSRID(srid)
+	}
+
+	for _, e := range g.removeDangles() {
+		result.Dangles = append(result.Dangles, newLineString(g.edges[e]))
+	}
+
+	// A directed edge is a cut edge if it is in the same ring as its twin.
+	rings, ringOf := g.rings()
+	var cutEdges []int
+	for e := range g.edges {
+		if !g.removed[e] && ringOf[2*e] == ringOf[2*e+1] {
+			cutEdges = append(cutEdges, e)
+		}
+	}
+	if len(cutEdges) > 0 {
+		for _, e := range cutEdges {
+			g.removed[e] = true
+			result.CutEdges = append(result.CutEdges, newLineString(g.edges[e]))
+		}
+		rings, _ = g.rings()
+	}
+
+	// Rings that touch themselves at a node, for example where a hole
+	// touches an exterior ring, are split into rings that do not.
+	// Counter-clockwise rings are polygon exterior rings and clockwise rings
+	// are holes.
+	var shells, holes [][]float64
+	var minimalRings [][]int
+	for _, ring := range rings {
+		minimalRings = append(minimalRings, g.splitRing(ring)...)
+	}
+	for _, ring := range minimalRings {
+		flatCoords := g.ringFlatCoords(ring)
+		if SignedArea(layout, flatCoords) > 0 {
+			holes = append(holes, flatCoords)
+			continue
+		}
+		if selfIntersections, err := SelfIntersections(geom.NewLineStringFlat(layout, flatCoords)); err != nil {
+			return nil, err
+		} else if len(flatCoords) < 4*stride || len(selfIntersections) > 0 {
+			result.InvalidRings = append(result.InvalidRings, newLineString(flatCoords))
+			continue
+		}
+		shells = append(shells, flatCoords)
+	}
+
+	// Assign each hole to the smallest shell that contains it.
+	shellBounds := make([]*geom.Bounds, len(shells))
+	shellAreas := make([]float64, len(shells))
+	for i, shell := range shells {
+		shellBounds[i] = geom.NewLinearRingFlat(layout, shell).Bounds()
+		shellAreas[i] = -SignedArea(layout, shell)
+	}
+	shellHoles := make([][][]float64, len(shells))
+	for _, hole := range holes {
+		holeBounds := geom.NewLinearRingFlat(layout, hole).Bounds()
+		best := -1
+		for i, shell := range shells {
+			if !shellBounds[i].Contains(holeBounds) || (best != -1 && shellAreas[i] >= shellAreas[best]) {
+				continue
+			}
+			if p := pointNotOnRing(hole, shell, stride); p != nil && LocatePointInRing(layout, p, shell) == location.Interior {
+				best = i
+			}
+		}
+		if best != -1 {
+			shellHoles[best] = append(shellHoles[best], hole)
+		}
+	}
+
+	for i, shell := range shells {
+		flatCoords := append([]float64(nil), shell...)
+		ends := []int{len(flatCoords)}
+		for _, hole := range shellHoles[i] {
+			flatCoords = append(flatCoords, hole...)
+			ends = append(ends, len(flatCoords))
+		}
+		result.Polygons = append(result.Polygons, geom.NewPolygonFlat(layout, flatCoords, ends).SetSRID(srid))
+	}
+	return result, nil
+}
+
+// A polygonizeGraph is a planar graph of line strings.  Each edge e has two
+// directed edges: 2*e, which follows the line string forwards, and 2*e+1,
+// which follows it backwards.
+type polygonizeGraph struct {
+	stride  int
+	edges   [][]float64
+	removed []bool
+	// origins are the nodes at the start of each directed edge.
+	origins []int
+	// outEdges are the directed edges leaving each node, sorted
+	// counter-clockwise by angle.
+	outEdges [][]int
+}
+
+// newPolygonizeGraph returns a new polygonizeGraph of lines.
+func newPolygonizeGraph(lines [][]float64, stride int) *polygonizeGraph {
+	g := &polygonizeGraph{
+		stride: stride,
+	}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		edge := appendUnrepeatedFlat(nil, line, stride)
+		if len(edge) < 2*stride {
+			continue
+		}
+		if key := edgeKey(edge, stride); !seen[key] {
+			seen[key] = true
+			g.edges = append(g.edges, edge)
+		}
+	}
+	g.removed = make([]bool, len(g.edges))
+
+	nodes := make(map[[2]float64]int)
+	nodeIndex := func(coord []float64) int {
+		key := nodeKey(coord)
+		if i, ok := nodes[key]; ok {
+			return i
+		}
+		nodes[key] = len(g.outEdges)
+		g.outEdges = append(g.outEdges, nil)
+		return nodes[key]
+	}
+	angles := make([]float64, 2*len(g.edges))
+	g.origins = make([]int, 2*len(g.edges))
+	for e, edge := range g.edges {
+		n := len(edge)
+		for d, coords := range [2][2][]float64{
+			{edge[:stride], edge[stride : 2*stride]},
+			{edge[n-stride:], edge[n-2*stride : n-stride]},
+		} {
+			de := 2*e + d
+			origin := nodeIndex(coords[0])
+			g.origins[de] = origin
+			g.outEdges[origin] = append(g.outEdges[origin], de)
+			angles[de] = math.Atan2(coords[1][1]-coords[0][1], coords[1][0]-coords[0][0])
+		}
+	}
+	for _, outEdges := range g.outEdges {
+		sort.Stable(edgesByAngle{edges: outEdges, angles: angles})
+	}
+	return g
+}
+
+// removeDangles repeatedly removes edges with an endpoint of degree one and
+// returns the removed edges.
+func (g *polygonizeGraph) removeDangles() []int {
+	degrees := make([]int, len(g.outEdges))
+	for de, origin := range g.origins {
+		if !g.removed[de/2] {
+			degrees[origin]++
+		}
+	}
+	var stack []int
+	for node, degree := range degrees {
+		if degree == 1 {
+			stack = append(stack, node)
+		}
+	}
+	var dangles []int
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, de := range g.outEdges[node] {
+			if g.removed[de/2] {
+				continue
+			}
+			g.removed[de/2] = true
+			dangles = append(dangles, de/2)
+			degrees[node]--
+			other := g.origins[de^1]
+			if degrees[other]--; degrees[other] == 1 {
+				stack = append(stack, other)
+			}
+		}
+	}
+	sort.Ints(dangles)
+	return dangles
+}
+
+// next returns the directed edge that follows de on the ring that has de's
+// face on its left, i.e. the directed edge leaving de's destination that is
+// immediately clockwise from de's twin.
+func (g *polygonizeGraph) next(de int) int {
+	outEdges := g.outEdges[g.origins[de^1]]
+	i := 0
+	for outEdges[i] != de^1 {
+		i++
+	}
+	for {
+		i = (i + len(outEdges) - 1) % len(outEdges)
+		if !g.removed[outEdges[i]/2] {
+			return outEdges[i]
+		}
+	}
+}
+
+// rings returns the rings of directed edges formed by the remaining edges and
+// the index of the ring that contains each directed edge.
+func (g *polygonizeGraph) rings() ([][]int, []int) {
+	ringOf := make([]int, 2*len(g.edges))
+	for i := range ringOf {
+		ringOf[i] = -1
+	}
+	var rings [][]int
+	for de := range ringOf {
+		if g.removed[de/2] || ringOf[de] != -1 {
+			continue
+		}
+		var ring []int
+		for d := de; ringOf[d] == -1; d = g.next(d) {
+			ringOf[d] = len(rings)
+			ring = append(ring, d)
+		}
+		rings = append(rings, ring)
+	}
+	return rings, ringOf
+}
+
+// splitRing splits ring wherever it visits the same node more than once and
+// returns the resulting rings, none of which visit any node more than once.
+func (g *polygonizeGraph) splitRing(ring []int) [][]int {
+	var rings [][]int
+	var stack []int
+	positions := make(map[int]int)
+	for _, de := range ring {
+		node := g.origins[de]
+		if i, ok := positions[node]; ok {
+			rings = append(rings, append([]int(nil), stack[i:]...))
+			for _, d := range stack[i:] {
+				delete(positions, g.origins[d])
+			}
+			stack = stack[:i]
+		}
+		positions[node] = len(stack)
+		stack = append(stack, de)
+	}
+	return append(rings, stack)
+}
+
+// ringFlatCoords returns the flat coordinates of ring.
+func (g *polygonizeGraph) ringFlatCoords(ring []int) []float64 {
+	var flatCoords []float64
+	for _, de := range ring {
+		edge := g.edges[de/2]
+		if de%2 == 1 {
+			edge = append([]float64(nil), edge...)
+			flat.Reverse(edge, g.stride)
+		}
+		flatCoords = append(flatCoords, edge[:len(edge)-g.stride]...)
+	}
+	return append(flatCoords, flatCoords[:g.stride]...)
+}
+
+// pointNotOnRing returns a coordinate of flatCoords that is not a vertex of
+// ring, or nil if there is none.
+func pointNotOnRing(flatCoords, ring []float64, stride int) geom.Coord {
+	vertices := make(map[[2]float64]bool)
+	for i := 0; i < len(ring); i += stride {
+		vertices[nodeKey(ring[i:])] = true
+	}
+	for i := 0; i < len(flatCoords); i += stride {
+		if !vertices[nodeKey(flatCoords[i:])] {
+			return geom.Coord(flatCoords[i : i+stride])
+		}
+	}
+	return nil
+}
+
+// edgesByAngle sorts directed edges by their angles.
+type edgesByAngle struct {
+	edges  []int
+	angles []float64
+}
+
+func (s edgesByAngle) Len() int           { return len(s.edges) }
+func (s edgesByAngle) Less(i, j int) bool { return s.angles[s.edges[i]] < s.angles[s.edges[j]] }
+func (s edgesByAngle) Swap(i, j int)      { s.edges[i], s.edges[j] = s.edges[j], s.edges[i] }
@@ -0,0 +1,92 @@
+package xy_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func TestPolygonize(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want *xy.PolygonizeResult
+	}{
+		{
+			g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0, 1, 1, 1, 1, 0, 0, 0}),
+			want: &xy.PolygonizeResult{
+				Polygons: []*geom.Polygon{
+					geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, []int{10}),
+				},
+			},
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{
+				0, 0, 2, 0, 2, 0, 2, 2, 2, 2, 0, 2, 0, 2, 0, 0,
+				2, 0, 4, 0, 4, 0, 4, 2, 4, 2, 2, 2,
+				4, 2, 5, 3,
+				4, 0, 6, 0,
+				6, 0, 7, 0, 7, 0, 7, 1, 7, 1, 6, 0,
+				0.5, 0.5, 1.5, 0.5, 1.5, 1.5, 0.5, 1.5, 0.5, 0.5,
+			}, []int{4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 48, 58}),
+			want: &xy.PolygonizeResult{
+				Polygons: []*geom.Polygon{
+					geom.NewPolygonFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0, 0.5, 0.5, 0.5, 1.5, 1.5, 1.5, 1.5, 0.5, 0.5, 0.5}, []int{10, 20}),
+					geom.NewPolygonFlat(geom.XY, []float64{2, 2, 2, 0, 4, 0, 4, 2, 2, 2}, []int{10}),
+					geom.NewPolygonFlat(geom.XY, []float64{6, 0, 7, 0, 7, 1, 6, 0}, []int{8}),
+					geom.NewPolygonFlat(geom.XY, []float64{0.5, 0.5, 1.5, 0.5, 1.5, 1.5, 0.5, 1.5, 0.5, 0.5}, []int{10}),
+				},
+				Dangles: []*geom.LineString{
+					geom.NewLineStringFlat(geom.XY, []float64{4, 2, 5, 3}),
+				},
+				CutEdges: []*geom.LineString{
+					geom.NewLineStringFlat(geom.XY, []float64{4, 0, 6, 0}),
+				},
+			},
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 2, 0, 2, 0, 2, 1}, []int{4, 8, 12}),
+			want: &xy.PolygonizeResult{
+				Dangles: []*geom.LineString{
+					geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0}),
+					geom.NewLineStringFlat(geom.XY, []float64{1, 0, 2, 0}),
+					geom.NewLineStringFlat(geom.XY, []float64{2, 0, 2, 1}),
+				},
+			},
+		},
+		{
+			g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 1, 0, 0, 2, 0, 0}),
+			want: &xy.PolygonizeResult{
+				InvalidRings: []*geom.LineString{
+					geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 1, 0, 0, 2, 0, 0}),
+				},
+			},
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 4, 0, 2,
+				0, 2, 0, 0,
+				0, 2, 2, 1, 2, 3, 0, 2,
+			}, []int{10, 14, 22}),
+			want: &xy.PolygonizeResult{
+				Polygons: []*geom.Polygon{
+					geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 2, 0, 0, 0, 2, 2, 3, 2, 1, 0, 2}, []int{12, 20}),
+					geom.NewPolygonFlat(geom.XY, []float64{0, 2, 2, 1, 2, 3, 0, 2}, []int{8}),
+				},
+			},
+		},
+	} {
+		got, err := xy.Polygonize(tc.g)
+		if err != nil {
+			t.Errorf("%d: Polygonize(%v) == ..., %v, want ..., <nil>", i, tc.g, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Polygonize(%v) == %+v, <nil>, want %+v, <nil>", i, tc.g, got, tc.want)
+		}
+	}
+	if _, err := xy.Polygonize(geom.NewPointFlat(geom.XY, []float64{0, 0})); err == nil {
+		t.Errorf("Polygonize(Point) == ..., <nil>, want ..., non-<nil>")
+	}
+}
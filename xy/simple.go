@@ -0,0 +1,224 @@
+package xy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/lineintersection"
+	"github.com/twpayne/go-geom/xy/lineintersector"
+)
+
+// A SelfIntersection is an intersection between two segments of a geometry
+// that makes it non-simple.
+type SelfIntersection struct {
+	// Segments are the intersecting segments.  Their LineIndex is the index
+	// of the line string in a MultiLineString, or zero, and their
+	// SegmentIndex is the index of the coordinate at which they start.
+	Segments [2]lineintersector.Segment
+	// Result is the intersection of the segments.  Its Intersection is the
+	// intersection point, or the start and end of the overlap if the segments
+	// are collinear.
+	Result lineintersection.Result
+}
+
+// IsClosed returns true if the first and last coordinates of geometry are
+// equal in X and Y.  For a MultiLineString, it returns true if all of its
+// line strings are closed.  Points and MultiPoints are always closed, like
+// the single coordinate of a Point.  Empty geometries are not closed.
+func IsClosed(geometry geom.T) (bool, error) {
+	switch t := geometry.(type) {
+	case *geom.Point:
+		return !t.Empty(), nil
+	case *geom.MultiPoint:
+		return t.NumPoints() != 0, nil
+	case *geom.LineString:
+		return isClosedFlat(t.FlatCoords(), t.Stride()), nil
+	case *geom.LinearRing:
+		return isClosedFlat(t.FlatCoords(), t.Stride()), nil
+	case *geom.MultiLineString:
+		if t.NumLineStrings() == 0 {
+			return false, nil
+		}
+		offset := 0
+		for _, end := range t.Ends() {
+			if !isClosedFlat(t.FlatCoords()[offset:end], t.Stride()) {
+				return false, nil
+			}
+			offset = end
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("%v is not a supported type for closure test", t)
+	}
+}
+
+// IsSimple returns true if geometry is simple as defined by the OGC Simple
+// Features specification:
+//
+//   - Points are always simple;
+//   - MultiPoints are simple if no two of their points are equal in X and Y;
+//   - LineStrings and LinearRings are simple if they do not intersect
+//     themselves, except that the first and last coordinates of a closed line
+//     string may be equal;
+//   - MultiLineStrings are simple if each of their line strings is simple and
+//     the line strings only intersect each other at their endpoints.
+//
+// Repeated consecutive coordinates are ignored.
+func IsSimple(geometry geom.T) (bool, error) {
+	switch t := geometry.(type) {
+	case *geom.Point:
+		return true, nil
+	case *geom.MultiPoint:
+		n := t.NumPoints()
+		coords := make([]geom.Coord, n)
+		for i := range coords {
+			coords[i] = t.Coord(i)
+		}
+		sort.Sort(coordsByXY(coords))
+		for i := 1; i < n; i++ {
+			if coords[i][0] == coords[i-1][0] && coords[i][1] == coords[i-1][1] {
+				return false, nil
+			}
+		}
+		return true, nil
+	default:
+		selfIntersections, err := SelfIntersections(geometry)
+		if err != nil {
+			return false, err
+		}
+		return len(selfIntersections) == 0, nil
+	}
+}
+
+// coordsByXY sorts coordinates by X and then by Y.
+type coordsByXY []geom.Coord
+
+func (s coordsByXY) Len() int { return len(s) }
+func (s coordsByXY) Less(i, j int) bool {
+	if s[i][0] != s[j][0] {
+		return s[i][0] < s[j][0]
+	}
+	return s[i][1] < s[j][1]
+}
+func (s coordsByXY) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// SelfIntersections returns the intersections between the segments of
+// geometry, which must be a LineString, LinearRing, or MultiLineString, that
+// make it non-simple, see IsSimple.  Intersections between consecutive
+// segments at their shared coordinate, between the first and last segments of
+// a closed line string at its first coordinate, and between different line
+// strings at endpoints of both are not self-intersections.  Candidate pairs of
+// segments are found with a sweep line, so large geometries are handled
+// efficiently.  The result is sorted by the segments' line and segment
+// indexes.
+func SelfIntersections(geometry geom.T) ([]SelfIntersection, error) {
+	lines, err := flatLineStrings(geometry, "self-intersection calculation")
+	if err != nil {
+		return nil, err
+	}
+	stride := geometry.Stride()
+	segments, ordinals, numSegments := nonDegenerateSegments(lines, stride)
+	var selfIntersections []SelfIntersection
+	for _, intersection := range lineintersector.FindIntersections(lineintersector.RobustLineIntersector{}, segments) {
+		s1, s2 := segments[intersection.I], segments[intersection.J]
+		if isAllowedIntersection(lines, stride, s1, s2, ordinals[intersection.I], ordinals[intersection.J], numSegments, intersection.Result) {
+			continue
+		}
+		selfIntersections = append(selfIntersections, SelfIntersection{
+			Segments: [2]lineintersector.Segment{s1, s2},
+			Result:   intersection.Result,
+		})
+	}
+	return selfIntersections, nil
+}
+
+// flatLineStrings returns the flat coordinates of the line strings of
+// geometry, which must be a LineString, LinearRing, or MultiLineString.
+func flatLineStrings(geometry geom.T, operation string) ([][]float64, error) {
+	switch t := geometry.(type) {
+	case *geom.LineString:
+		return [][]float64{t.FlatCoords()}, nil
+	case *geom.LinearRing:
+		return [][]float64{t.FlatCoords()}, nil
+	case *geom.MultiLineString:
+		lines := make([][]float64, 0, t.NumLineStrings())
+		offset := 0
+		for _, end := range t.Ends() {
+			lines = append(lines, t.FlatCoords()[offset:end])
+			offset = end
+		}
+		return lines, nil
+	default:
+		return nil, fmt.Errorf("%v is not a supported type for %s", t, operation)
+	}
+}
+
+// nonDegenerateSegments returns the segments of non-zero length of lines,
+// their ordinal positions in their line strings, so that consecutive segments
+// can be identified, and the number of segments in each line string.
+func nonDegenerateSegments(lines [][]float64, stride int) ([]lineintersector.Segment, []int, []int) {
+	var segments []lineintersector.Segment
+	var ordinals []int
+	numSegments := make([]int, len(lines))
+	for lineIndex, flatCoords := range lines {
+		for i := 0; i+stride < len(flatCoords); i += stride {
+			start := geom.Coord(flatCoords[i : i+stride])
+			end := geom.Coord(flatCoords[i+stride : i+2*stride])
+			if start.Equal(geom.XY, end) {
+				continue
+			}
+			segments = append(segments, lineintersector.Segment{
+				Start:        start,
+				End:          end,
+				LineIndex:    lineIndex,
+				SegmentIndex: i / stride,
+			})
+			ordinals = append(ordinals, numSegments[lineIndex])
+			numSegments[lineIndex]++
+		}
+	}
+	return segments, ordinals, numSegments
+}
+
+// isAllowedIntersection returns true if the intersection result between
+// segments s1 and s2, with ordinals o1 and o2 in their line strings, does not
+// make the geometry non-simple.
+func isAllowedIntersection(lines [][]float64, stride int, s1, s2 lineintersector.Segment, o1, o2 int, numSegments []int, result lineintersection.Result) bool {
+	if result.Type() != lineintersection.PointIntersection {
+		return false
+	}
+	p := result.Intersection()[0]
+	if s1.LineIndex != s2.LineIndex {
+		return isEndpoint(lines[s1.LineIndex], stride, p) && isEndpoint(lines[s2.LineIndex], stride, p)
+	}
+	if o1 > o2 {
+		s1, s2 = s2, s1
+		o1, o2 = o2, o1
+	}
+	switch {
+	case o2 == o1+1:
+		return p.Equal(geom.XY, s1.End)
+	case o1 == 0 && o2 == numSegments[s1.LineIndex]-1 && o2 > 1 && isClosedFlat(lines[s1.LineIndex], stride):
+		return p.Equal(geom.XY, s1.Start)
+	default:
+		return false
+	}
+}
+
+// isEndpoint returns true if p is equal to the first or last coordinate of
+// the line string in flatCoords.
+func isEndpoint(flatCoords []float64, stride int, p geom.Coord) bool {
+	n := len(flatCoords)
+	return p.Equal(geom.XY, flatCoords[:stride]) || p.Equal(geom.XY, flatCoords[n-stride:])
+}
+
+// isClosedFlat returns true if the line string in flatCoords is non-empty and
+// its first and last coordinates are equal in X and Y.
+func isClosedFlat(flatCoords []float64, stride int) bool {
+	n := len(flatCoords)
+	if n == 0 {
+		return false
+	}
+	return flatCoords[0] == flatCoords[n-stride] && flatCoords[1] == flatCoords[n-stride+1]
+}
@@ -0,0 +1,149 @@
+package xy_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/lineintersection"
+)
+
+func TestIsClosed(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want bool
+	}{
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}), want: true},
+		{g: geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 0, 1}), want: true},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1}), want: false},
+		{g: geom.NewLineString(geom.XY), want: false},
+		{g: geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}), want: true},
+		{g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 0, 0, 2, 2, 3, 3, 2, 2}, []int{6, 12}), want: true},
+		{g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 0, 0, 2, 2, 3, 3}, []int{6, 10}), want: false},
+		{g: geom.NewMultiLineString(geom.XY), want: false},
+		{g: geom.NewPointFlat(geom.XY, []float64{0, 0}), want: true},
+		{g: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1}), want: true},
+		{g: geom.NewMultiPoint(geom.XY), want: false},
+	} {
+		if got, err := xy.IsClosed(tc.g); err != nil || got != tc.want {
+			t.Errorf("%d: IsClosed(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+	if _, err := xy.IsClosed(geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})); err == nil {
+		t.Errorf("IsClosed(Polygon) == ..., <nil>, want ..., non-<nil>")
+	}
+}
+
+func TestIsSimple(t *testing.T) {
+	for i, tc := range []struct {
+		g    geom.T
+		want bool
+	}{
+		{g: geom.NewPointFlat(geom.XY, []float64{0, 0}), want: true},
+		{g: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1, 2, 0}), want: true},
+		{g: geom.NewMultiPointFlat(geom.XYZ, []float64{0, 0, 0, 1, 1, 1, 0, 0, 2}), want: false},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1}), want: true},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0, 1, 1}), want: true},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 1, 1, -1}), want: false},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 1, 0}), want: false},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 0}), want: true},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 1, 0, 0, 2}), want: false},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 2, 0}), want: false},
+		{g: geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0}), want: true},
+		{g: geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 2, 2, 2, 0, 0, 2, 0, 0}), want: false},
+		{g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 1, 1, 2, 0}, []int{4, 8}), want: true},
+		{g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 2, 0, 2, 2, 0}, []int{4, 8}), want: false},
+		{g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 1, 0, 1, 1}, []int{4, 8}), want: false},
+	} {
+		if got, err := xy.IsSimple(tc.g); err != nil || got != tc.want {
+			t.Errorf("%d: IsSimple(%v) == %v, %v, want %v, <nil>", i, tc.g, got, err, tc.want)
+		}
+	}
+	if _, err := xy.IsSimple(geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})); err == nil {
+		t.Errorf("IsSimple(Polygon) == ..., <nil>, want ..., non-<nil>")
+	}
+}
+
+func TestSelfIntersections(t *testing.T) {
+	type selfIntersection struct {
+		segments         [2][2]int
+		intersectionType lineintersection.Type
+		intersection     []geom.Coord
+	}
+	for i, tc := range []struct {
+		g    geom.T
+		want []selfIntersection
+	}{
+		{
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1}),
+			want: nil,
+		},
+		{
+			// a bow-tie ring
+			g: geom.NewLinearRingFlat(geom.XY, []float64{0, 0, 2, 2, 2, 0, 0, 2, 0, 0}),
+			want: []selfIntersection{
+				{segments: [2][2]int{{0, 0}, {0, 2}}, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{1, 1}}},
+			},
+		},
+		{
+			// a track that revisits a vertex and then doubles back
+			g: geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 2, 0, 0, 2, 2, 0, 1, 0, 0, 1, 0, 0, 1, -1, 0, 1, 1, 0}),
+			want: []selfIntersection{
+				{segments: [2][2]int{{0, 0}, {0, 2}}, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{1, 0}}},
+				{segments: [2][2]int{{0, 0}, {0, 4}}, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{1, 0}}},
+				{segments: [2][2]int{{0, 0}, {0, 5}}, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{1, 0}}},
+				{segments: [2][2]int{{0, 2}, {0, 5}}, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{1, 0}}},
+				{segments: [2][2]int{{0, 4}, {0, 5}}, intersectionType: lineintersection.CollinearIntersection, intersection: []geom.Coord{{1, 0, 0}, {1, -1, 0}}},
+			},
+		},
+		{
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 2, 0, 2, 2, 0}, []int{4, 8}),
+			want: []selfIntersection{
+				{segments: [2][2]int{{0, 0}, {1, 0}}, intersectionType: lineintersection.PointIntersection, intersection: []geom.Coord{{1, 1}}},
+			},
+		},
+	} {
+		selfIntersections, err := xy.SelfIntersections(tc.g)
+		if err != nil {
+			t.Errorf("%d: SelfIntersections(%v) == ..., %v, want ..., <nil>", i, tc.g, err)
+			continue
+		}
+		var got []selfIntersection
+		for _, si := range selfIntersections {
+			got = append(got, selfIntersection{
+				segments: [2][2]int{
+					{si.Segments[0].LineIndex, si.Segments[0].SegmentIndex},
+					{si.Segments[1].LineIndex, si.Segments[1].SegmentIndex},
+				},
+				intersectionType: si.Result.Type(),
+				intersection:     si.Result.Intersection(),
+			})
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: SelfIntersections(%v) == %v, want %v", i, tc.g, got, tc.want)
+		}
+	}
+}
+
+func TestSelfIntersectionsLargeTrack(t *testing.T) {
+	// a spiral track with many vertices and n/1000 turns that never crosses
+	// itself, followed by a final segment that crosses every turn
+	const n = 100000
+	flatCoords := make([]float64, 0, 2*n+4)
+	for i := 0; i < n; i++ {
+		theta := float64(i) * 2 * math.Pi / 1000
+		r := 1 + float64(i)/1000
+		flatCoords = append(flatCoords, r*math.Cos(theta), r*math.Sin(theta))
+	}
+	g := geom.NewLineStringFlat(geom.XY, flatCoords)
+	if got, err := xy.IsSimple(g); err != nil || !got {
+		t.Errorf("IsSimple(spiral) == %v, %v, want true, <nil>", got, err)
+	}
+	g = geom.NewLineStringFlat(geom.XY, append(flatCoords, 0, 0.5))
+	selfIntersections, err := xy.SelfIntersections(g)
+	if err != nil || len(selfIntersections) != n/1000 {
+		t.Errorf("len(SelfIntersections(spiral)) == %d, %v, want %d, <nil>", len(selfIntersections), err, n/1000)
+	}
+}
@@ -0,0 +1,211 @@
+package xy
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/lineintersector"
+)
+
+// Split returns the pieces of geometry cut by blade.
+//
+// LineStrings, LinearRings, and MultiLineStrings can be split by Points,
+// MultiPoints, or by the lines or rings of any other geometry, and are
+// returned as a MultiLineString.  Coordinates inserted at split points have
+// their Z and M values, if any, interpolated along the line string that
+// contains them.
+//
+// Polygons and MultiPolygons can be split by the lines or rings of any
+// geometry other than a Point or MultiPoint, and are returned as a
+// MultiPolygon.  Holes are preserved in the pieces that contain them and are
+// cut where the blade crosses them.  Parts of the blade that do not cut all
+// the way across a polygon do not split it.  Exterior rings of the pieces are
+// oriented counter-clockwise and holes clockwise.  Coordinates of the blade
+// that become vertices of the pieces take their ordinates from the blade where
+// it has them, and zero otherwise, except where they lie on the rings of
+// geometry, in which case they are interpolated along the rings.
+//
+// The result has the same layout and SRID as geometry.
+func Split(geometry, blade geom.T) (geom.T, error) {
+	layout, stride := geometry.Layout(), geometry.Stride()
+	switch t := geometry.(type) {
+	case *geom.LineString, *geom.LinearRing, *geom.MultiLineString:
+		lines, err := flatLineStrings(t, "splitting")
+		if err != nil {
+			return nil, err
+		}
+		var nodes [][]segmentNode
+		var segments []lineintersector.Segment
+		switch blade := blade.(type) {
+		case *geom.Point, *geom.MultiPoint:
+			segments, _, _ = nonDegenerateSegments(lines, stride)
+			nodes = pointNodes(segments, blade.FlatCoords(), blade.Stride())
+		default:
+			bladeLines, err := flatBladeLines(blade, layout)
+			if err != nil {
+				return nil, err
+			}
+			segments, _, _ = nonDegenerateSegments(append(lines, bladeLines...), stride)
+			nodes = lineNodes(segments, len(lines))
+		}
+		var flatCoords []float64
+		var ends []int
+		for _, piece := range splitAtNodes(lines, stride, segments, nodes) {
+			flatCoords = append(flatCoords, piece...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends).SetSRID(geometry.SRID()), nil
+	case *geom.Polygon, *geom.MultiPolygon:
+		var endss [][]int
+		switch t := t.(type) {
+		case *geom.Polygon:
+			endss = [][]int{t.Ends()}
+		case *geom.MultiPolygon:
+			endss = t.Endss()
+		}
+		bladeLines, err := flatBladeLines(blade, layout)
+		if err != nil {
+			return nil, err
+		}
+		var lines [][]float64
+		offset := 0
+		for _, ends := range endss {
+			for _, end := range ends {
+				lines = append(lines, t.FlatCoords()[offset:end])
+				offset = end
+			}
+		}
+		lines = append(lines, bladeLines...)
+		var flatCoords []float64
+		var ends []int
+		for _, line := range lines {
+			flatCoords = append(flatCoords, line...)
+			ends = append(ends, len(flatCoords))
+		}
+		noded, err := Node(geom.NewMultiLineStringFlat(layout, flatCoords, ends))
+		if err != nil {
+			return nil, err
+		}
+		polygonized, err := Polygonize(noded)
+		if err != nil {
+			return nil, err
+		}
+		// Coordinates on the rings of geometry take their ordinates from the
+		// rings, rather than from the blade.
+		numRings := len(lines) - len(bladeLines)
+		segments, _, _ := nonDegenerateSegments(lines, stride)
+		ringCoords := make(map[[2]float64][]float64)
+		for _, piece := range splitAtNodes(lines[:numRings], stride, segments, lineNodes(segments, numRings)) {
+			for i := 0; i < len(piece); i += stride {
+				ringCoords[nodeKey(piece[i:])] = piece[i : i+stride]
+			}
+		}
+		multiPolygon := geom.NewMultiPolygon(layout).SetSRID(geometry.SRID())
+		for _, polygon := range polygonized.Polygons {
+			p, err := InteriorPoint(polygon)
+			if err != nil {
+				return nil, err
+			}
+			if p == nil || !IsPointInPolygons(layout, p, t.FlatCoords(), endss) {
+				continue
+			}
+			polygonFlatCoords := polygon.FlatCoords()
+			for i := 0; i < len(polygonFlatCoords); i += stride {
+				if coord, ok := ringCoords[nodeKey(polygonFlatCoords[i:])]; ok {
+					copy(polygonFlatCoords[i:i+stride], coord)
+				}
+			}
+			if err := multiPolygon.Push(polygon); err != nil {
+				return nil, err
+			}
+		}
+		return multiPolygon, nil
+	default:
+		return nil, fmt.Errorf("%v is not a supported type for splitting", t)
+	}
+}
+
+// flatBladeLines returns the flat coordinates of the line strings or rings of
+// blade, converted to layout.
+func flatBladeLines(blade geom.T, layout geom.Layout) ([][]float64, error) {
+	var flatCoords []float64
+	var ends []int
+	switch blade := blade.(type) {
+	case *geom.LineString, *geom.LinearRing:
+		flatCoords, ends = blade.FlatCoords(), []int{len(blade.FlatCoords())}
+	case *geom.MultiLineString:
+		flatCoords, ends = blade.FlatCoords(), blade.Ends()
+	case *geom.Polygon:
+		flatCoords, ends = blade.FlatCoords(), blade.Ends()
+	case *geom.MultiPolygon:
+		flatCoords = blade.FlatCoords()
+		for _, polygonEnds := range blade.Endss() {
+			ends = append(ends, polygonEnds...)
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a supported blade type for splitting", blade)
+	}
+	var lines [][]float64
+	offset := 0
+	for _, end := range ends {
+		lines = append(lines, convertFlatCoords(flatCoords[offset:end], blade.Layout(), layout))
+		offset = end
+	}
+	return lines, nil
+}
+
+// convertFlatCoords returns a copy of flatCoords converted from layout from
+// to layout to.  Ordinates that from does not have are set to zero.
+func convertFlatCoords(flatCoords []float64, from, to geom.Layout) []float64 {
+	fromStride, toStride := from.Stride(), to.Stride()
+	n := len(flatCoords) / fromStride
+	result := make([]float64, n*toStride)
+	for i := 0; i < n; i++ {
+		coord := flatCoords[i*fromStride : (i+1)*fromStride]
+		converted := result[i*toStride : (i+1)*toStride]
+		converted[0], converted[1] = coord[0], coord[1]
+		if zIndex := to.ZIndex(); zIndex != -1 && from.ZIndex() != -1 {
+			converted[zIndex] = coord[from.ZIndex()]
+		}
+		if mIndex := to.MIndex(); mIndex != -1 && from.MIndex() != -1 {
+			converted[mIndex] = coord[from.MIndex()]
+		}
+	}
+	return result
+}
+
+// pointNodes returns the nodes of segments at each of the points in
+// flatCoords that lie on them.
+func pointNodes(segments []lineintersector.Segment, flatCoords []float64, stride int) [][]segmentNode {
+	nodes := make([][]segmentNode, len(segments))
+	for i, segment := range segments {
+		dx, dy := segment.End[0]-segment.Start[0], segment.End[1]-segment.Start[1]
+		for j := 0; j < len(flatCoords); j += stride {
+			p := geom.Coord(flatCoords[j : j+stride])
+			if !lineintersector.PointIntersectsLine(lineintersector.RobustLineIntersector{}, p, segment.Start, segment.End) {
+				continue
+			}
+			fraction := ((p[0]-segment.Start[0])*dx + (p[1]-segment.Start[1])*dy) / (dx*dx + dy*dy)
+			nodes[i] = append(nodes[i], segmentNode{fraction: fraction, x: p[0], y: p[1]})
+		}
+	}
+	return nodes
+}
+
+// lineNodes returns the nodes of segments of the first numLines line strings
+// where they intersect segments of the remaining line strings.
+func lineNodes(segments []lineintersector.Segment, numLines int) [][]segmentNode {
+	nodes := make([][]segmentNode, len(segments))
+	for _, intersection := range lineintersector.FindIntersections(lineintersector.RobustLineIntersector{}, segments) {
+		i, j := intersection.I, intersection.J
+		if segments[i].LineIndex >= numLines || segments[j].LineIndex < numLines {
+			continue
+		}
+		// the results of FindIntersections always have lines
+		for k, p := range intersection.Result.Intersection() {
+			fraction, _ := intersection.Result.Fraction(0, k)
+			nodes[i] = append(nodes[i], segmentNode{fraction: fraction, x: p[0], y: p[1]})
+		}
+	}
+	return nodes
+}
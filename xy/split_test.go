@@ -0,0 +1,84 @@
+package xy_test
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy"
+)
+
+func TestSplit(t *testing.T) {
+	for i, tc := range []struct {
+		g     geom.T
+		blade geom.T
+		want  geom.T
+	}{
+		{
+			g:     geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 4, 0, 4, 4, 4, 8}),
+			blade: geom.NewMultiPointFlat(geom.XY, []float64{1, 0, 4, 0, 4, 3, 9, 9}),
+			want:  geom.NewMultiLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 1, 1, 0, 1, 4, 0, 4, 4, 0, 4, 4, 3, 7, 4, 3, 7, 4, 4, 8}, []int{6, 12, 18, 24}),
+		},
+		{
+			g:     geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 0, 4, 0, 4}),
+			blade: geom.NewLineStringFlat(geom.XY, []float64{1, -1, 1, 1, 3, 1, 3, -1}),
+			want:  geom.NewMultiLineStringFlat(geom.XYM, []float64{0, 0, 0, 1, 0, 1, 1, 0, 1, 3, 0, 3, 3, 0, 3, 4, 0, 4}, []int{6, 12, 18}),
+		},
+		{
+			g:     geom.NewLineStringFlat(geom.XY, []float64{0, 0, 4, 0}),
+			blade: geom.NewPointFlat(geom.XY, []float64{2, 1}),
+			want:  geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 4, 0}, []int{4}),
+		},
+		{
+			g:     geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}, []int{10}),
+			blade: geom.NewLineStringFlat(geom.XY, []float64{2, -1, 2, 5}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 2, 0, 2, 4, 0, 4, 0, 0,
+				2, 0, 4, 0, 4, 4, 2, 4, 2, 0,
+			}, [][]int{{10}, {20}}),
+		},
+		{
+			g:     geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 3, 3, 3, 3, 1, 1, 1}, []int{10, 20}),
+			blade: geom.NewLineStringFlat(geom.XY, []float64{2, -1, 2, 5}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 2, 0, 2, 1, 1, 1, 1, 3, 2, 3, 2, 4, 0, 4, 0, 0,
+				2, 0, 4, 0, 4, 4, 2, 4, 2, 3, 3, 3, 3, 1, 2, 1, 2, 0,
+			}, [][]int{{18}, {36}}),
+		},
+		{
+			g:     geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1}, []int{10, 20}),
+			blade: geom.NewLineStringFlat(geom.XY, []float64{3, -1, 3, 5}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 3, 0, 3, 4, 0, 4, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1,
+				3, 0, 4, 0, 4, 4, 3, 4, 3, 0,
+			}, [][]int{{10, 20}, {30}}),
+		},
+		{
+			g:     geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}, []int{10}),
+			blade: geom.NewLineStringFlat(geom.XY, []float64{2, -1, 2, 2}),
+			want:  geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 2, 0, 4, 0, 4, 4, 0, 4, 0, 0}, [][]int{{12}}),
+		},
+		{
+			g:     geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 0, 4, 0, 4, 4, 4, 4, 0, 4, 0, 0, 0, 0}, []int{15}),
+			blade: geom.NewLineStringFlat(geom.XYZ, []float64{2, -1, 7, 2, 5, 7}),
+			want: geom.NewMultiPolygonFlat(geom.XYZ, []float64{
+				0, 0, 0, 2, 0, 2, 2, 4, 2, 0, 4, 0, 0, 0, 0,
+				2, 0, 2, 4, 0, 4, 4, 4, 4, 2, 4, 2, 2, 0, 2,
+			}, [][]int{{15}, {30}}),
+		},
+	} {
+		if got, err := xy.Split(tc.g, tc.blade); err != nil || !geom.Equal(got, tc.want) {
+			t.Errorf("%d: Split(%v, %v) == %v, %v, want %v, <nil>", i, tc.g, tc.blade, got, err, tc.want)
+		}
+	}
+	for i, tc := range []struct {
+		g     geom.T
+		blade geom.T
+	}{
+		{g: geom.NewPointFlat(geom.XY, []float64{0, 0}), blade: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1})},
+		{g: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}), blade: geom.NewPointFlat(geom.XY, []float64{0, 0})},
+	} {
+		if _, err := xy.Split(tc.g, tc.blade); err == nil {
+			t.Errorf("%d: Split(%v, %v) == ..., <nil>, want ..., non-<nil>", i, tc.g, tc.blade)
+		}
+	}
+}